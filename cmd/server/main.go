@@ -1,9 +1,11 @@
 package main
 
 import (
+	"context"
 	"log"
 	"os"
 	"os/signal"
+	"sync"
 	"syscall"
 	"time"
 
@@ -15,12 +17,31 @@ func main() {
 
 	go app.StartServer(appCtx)
 
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	var jobs sync.WaitGroup
+	jobs.Add(3)
+	go func() {
+		defer jobs.Done()
+		appCtx.Jobs.Run(jobsCtx)
+	}()
+	go func() {
+		defer jobs.Done()
+		appCtx.Events.Run(jobsCtx)
+	}()
+	go func() {
+		defer jobs.Done()
+		appCtx.Hub.Listen(jobsCtx, appCtx.Config.DB.DSN())
+	}()
+
 	stop := make(chan os.Signal, 1)
 	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
 	<-stop
 
 	log.Println("Shutting down server...")
 
+	stopJobs()
+	jobs.Wait()
+
 	log.Println("Server stopped gracefully")
 	time.Sleep(1 * time.Second)
 }
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/rxmy43/support-platform/internal/config"
+	"github.com/rxmy43/support-platform/internal/db"
+	"github.com/rxmy43/support-platform/internal/moderation"
+	"github.com/rxmy43/support-platform/internal/modules/balance"
+	"github.com/rxmy43/support-platform/internal/modules/campaign"
+	"github.com/rxmy43/support-platform/internal/modules/post"
+	"github.com/rxmy43/support-platform/internal/modules/support"
+	"github.com/rxmy43/support-platform/internal/modules/user"
+	"github.com/rxmy43/support-platform/internal/payment"
+	"github.com/rxmy43/support-platform/internal/socket"
+	"github.com/rxmy43/support-platform/internal/worker"
+)
+
+func main() {
+	cfg := config.Load()
+
+	DB, err := db.Connect(cfg.DB.DSN())
+	if err != nil {
+		log.Fatal("Database connection failed ", err)
+	}
+	defer DB.Close()
+
+	gateway, err := payment.NewFromConfig(cfg)
+	if err != nil {
+		log.Fatal("payment gateway setup failed ", err)
+	}
+
+	// the worker has no websocket clients, the supports it settles write
+	// outbox events that the API process dispatches. Anything it sends is
+	// relayed to the API processes.
+	hub := socket.NewHub()
+	hub.UseRelay(DB)
+
+	supportService := support.NewSupportService(support.NewSupportRepo(DB), user.NewUserRepo(DB), balance.NewBalanceRepo(DB), hub, gateway, moderation.NewBlocklist(cfg.Moderation.Blocklist), cfg.Support, cfg.AppURL)
+	postRepo := post.NewPostRepo(DB)
+	campaignService := campaign.NewCampaignService(campaign.NewCampaignRepo(DB), hub)
+
+	scheduler := worker.NewScheduler(DB, cfg.Worker.ShutdownTimeout)
+	scheduler.Add(worker.ExpirePendingSupports(supportService, cfg.Worker.ExpireInterval, cfg.Worker.PendingSupportTTL))
+	scheduler.Add(worker.ReconcilePayments(supportService, cfg.Worker.ReconcileInterval, cfg.Worker.ReconcileDelay))
+	scheduler.Add(worker.PublishScheduledPosts(postRepo, cfg.Worker.PublishInterval))
+	scheduler.Add(worker.ExpireCampaigns(campaignService, cfg.Worker.CampaignInterval))
+	scheduler.Add(worker.PruneViewWindows(postRepo, time.Hour))
+	scheduler.Add(worker.PruneFinishedJobs(DB, time.Hour, cfg.Worker.JobRetention))
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	scheduler.Run(ctx)
+}
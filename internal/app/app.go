@@ -8,12 +8,17 @@ import (
 	"github.com/rxmy43/support-platform/internal/config"
 	"github.com/rxmy43/support-platform/internal/db"
 	"github.com/rxmy43/support-platform/internal/http/router"
+	"github.com/rxmy43/support-platform/internal/outbox"
+	"github.com/rxmy43/support-platform/internal/queue"
 	"github.com/rxmy43/support-platform/internal/socket"
 )
 
 type AppContext struct {
 	Config *config.Config
 	Router http.Handler
+	Jobs   *queue.Runner
+	Events *outbox.Dispatcher
+	Hub    *socket.Hub
 }
 
 func InitApp() *AppContext {
@@ -31,15 +36,26 @@ func InitApp() *AppContext {
 	// 	}
 	// }
 
+	// socket messages are relayed through Postgres, the client of a creator
+	// can be connected to any of the API processes
 	hub := socket.NewHub()
+	hub.UseRelay(DB)
 
-	router := router.NewRouter(DB, hub)
+	// the API process dispatches outbox events and runs the queue jobs, some
+	// of them send to websocket clients through the hub
+	jobs := queue.NewRegistry()
+	events := outbox.NewDispatcher(DB, cfg.Queue.PollInterval)
+
+	router := router.NewRouter(cfg, DB, hub, jobs, events)
 
 	log.Println("Application bootstrap completed!")
 
 	return &AppContext{
 		Config: cfg,
 		Router: router,
+		Jobs:   queue.NewRunner(DB, jobs, cfg.Queue.Concurrency, cfg.Queue.PollInterval),
+		Events: events,
+		Hub:    hub,
 	}
 }
 
@@ -15,6 +15,7 @@ const (
 	CodeMethodNotAllowed         ErrorCode = "api.method_not_allowed"
 	CodeServiceUnavailable       ErrorCode = "api.code_service_unavailable"
 	CodeExternalAPIRequestFailed ErrorCode = "api.code_external_api_request_failed"
+	CodeQuotaExceeded            ErrorCode = "api.quota_exceeded"
 )
 
 // Domain: Field Validations
@@ -70,6 +71,7 @@ const (
 	CodeDigitsRequired       ErrorCode = "validation.digits_required"
 	CodeNoWhitespaceAllowed  ErrorCode = "validation.no_whitespace_allowed"
 	CodeWhitespaceRequired   ErrorCode = "validation.whitespace_required"
+	CodeContentRejected      ErrorCode = "validation.content_rejected"
 
 	// Password validations
 	CodePasswordTooWeak      ErrorCode = "validation.password_too_weak"
@@ -287,6 +289,8 @@ const (
 	CodePaymentProcessorDown    ErrorCode = "payment.processor_down"
 	CodePaymentAlreadyRefunded  ErrorCode = "payment.already_refunded"
 	CodePartialRefundNotAllowed ErrorCode = "payment.partial_refund_not_allowed"
+	CodePaymentMismatch         ErrorCode = "payment.mismatch"
+	CodePaymentInvalidStatus    ErrorCode = "payment.invalid_status_transition"
 
 	// Payment methods
 	CodeCardDeclined           ErrorCode = "payment.card_declined"
@@ -419,3 +423,10 @@ const (
 	CodeTakeoutReadyTimeInvalid ErrorCode = "delivery.ready_time_invalid"
 	CodePackagingUnavailable    ErrorCode = "delivery.packaging_unavailable"
 )
+
+// Domain: Polls
+const (
+	CodePollClosed         ErrorCode = "poll.closed"
+	CodePollAlreadyVoted   ErrorCode = "poll.already_voted"
+	CodePollSupportersOnly ErrorCode = "poll.supporters_only"
+)
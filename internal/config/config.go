@@ -5,6 +5,7 @@ import (
 	"log"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/joho/godotenv"
@@ -36,17 +37,79 @@ type CloudinaryConfig struct {
 type DuitkuAPIConfig struct {
 	MerchantCode string
 	MerchantKey  string
+	APIURL       string
+	WebAPIURL    string
+}
+
+type PaymentConfig struct {
+	Gateway string
+}
+
+type LLMConfig struct {
+	Provider       string
+	BaseURL        string
+	APIKey         string
+	Model          string
+	VisionModel    string
+	TimeoutSeconds int
+}
+
+// AIQuotaConfig limits AI caption calls per creator, 0 disables a limit.
+type AIQuotaConfig struct {
+	CaptionDaily   int
+	CaptionMonthly int
+}
+
+// SupportLimitsConfig is the platform floor and ceiling of a support amount,
+// creator limits have to stay within it.
+type SupportLimitsConfig struct {
+	MinAmount int64
+	MaxAmount int64
+}
+
+// WorkerConfig drives the periodic jobs of cmd/worker.
+type WorkerConfig struct {
+	ExpireInterval    time.Duration
+	ReconcileInterval time.Duration
+	PublishInterval   time.Duration
+	CampaignInterval  time.Duration
+	// PendingSupportTTL is how long a support may stay pending before it
+	// expires, ReconcileDelay how long before we ask the gateway about it.
+	PendingSupportTTL time.Duration
+	ReconcileDelay    time.Duration
+	ShutdownTimeout   time.Duration
+	// JobRetention is how long finished queue jobs are kept.
+	JobRetention time.Duration
+}
+
+// QueueConfig sizes the job queue runner of the API process.
+type QueueConfig struct {
+	Concurrency  int
+	PollInterval time.Duration
+}
+
+type ModerationConfig struct {
+	LLMEnabled bool
+	Blocklist  []string
 }
 
 type Config struct {
 	Env        string
 	Port       string
 	AppURL     string
+	WebURL     string
 	GroqAPIKey string
 	LogLevel   string
 	JWT        JWTConfig
 	Cloudinary CloudinaryConfig
 	Duitku     DuitkuAPIConfig
+	Payment    PaymentConfig
+	LLM        LLMConfig
+	AIQuota    AIQuotaConfig
+	Support    SupportLimitsConfig
+	Worker     WorkerConfig
+	Queue      QueueConfig
+	Moderation ModerationConfig
 	DB         DBConfig
 }
 
@@ -59,10 +122,16 @@ func Load() *Config {
 	accessTTLHours, _ := strconv.Atoi(os.Getenv("JWT_ACCESS_EXPIRATION_HOURS"))
 	refreshTTLHours, _ := strconv.Atoi(os.Getenv("JWT_REFRESH_EXPIRATION_HOURS"))
 
+	llmTimeout, err := strconv.Atoi(getEnv("LLM_TIMEOUT_SECONDS", "15"))
+	if err != nil || llmTimeout <= 0 {
+		llmTimeout = 15
+	}
+
 	return &Config{
 		Env:        os.Getenv("ENV"),
 		Port:       os.Getenv("PORT"),
 		AppURL:     os.Getenv("APP_URL"),
+		WebURL:     getEnv("WEB_URL", "http://localhost:3000"),
 		GroqAPIKey: os.Getenv("GROQ_API_KEY"),
 		LogLevel:   os.Getenv("LOG_LEVEL"),
 
@@ -82,6 +151,52 @@ func Load() *Config {
 		Duitku: DuitkuAPIConfig{
 			MerchantCode: os.Getenv("DUITKY_MERCHANT_CODE"),
 			MerchantKey:  os.Getenv("DUITKU_API_KEY"),
+			APIURL:       getEnv("DUITKU_API_URL", "https://api-sandbox.duitku.com"),
+			WebAPIURL:    getEnv("DUITKU_WEBAPI_URL", "https://sandbox.duitku.com/webapi"),
+		},
+
+		Payment: PaymentConfig{
+			Gateway: getEnv("PAYMENT_GATEWAY", "duitku"),
+		},
+
+		LLM: LLMConfig{
+			Provider:       getEnv("LLM_PROVIDER", "openai"),
+			BaseURL:        getEnv("LLM_BASE_URL", "https://api.groq.com/openai/v1"),
+			APIKey:         getEnv("LLM_API_KEY", os.Getenv("GROQ_API_KEY")),
+			Model:          getEnv("LLM_MODEL", "llama-3.3-70b-versatile"),
+			VisionModel:    getEnv("LLM_VISION_MODEL", "meta-llama/llama-4-scout-17b-16e-instruct"),
+			TimeoutSeconds: llmTimeout,
+		},
+
+		AIQuota: AIQuotaConfig{
+			CaptionDaily:   getEnvInt("AI_CAPTION_DAILY_QUOTA", 20),
+			CaptionMonthly: getEnvInt("AI_CAPTION_MONTHLY_QUOTA", 300),
+		},
+
+		Support: SupportLimitsConfig{
+			MinAmount: int64(getEnvInt("SUPPORT_MIN_AMOUNT", 10000)),
+			MaxAmount: int64(getEnvInt("SUPPORT_MAX_AMOUNT", 50000000)),
+		},
+
+		Worker: WorkerConfig{
+			ExpireInterval:    time.Duration(getEnvPositiveInt("WORKER_EXPIRE_INTERVAL_SECONDS", 900)) * time.Second,
+			ReconcileInterval: time.Duration(getEnvPositiveInt("WORKER_RECONCILE_INTERVAL_SECONDS", 300)) * time.Second,
+			PublishInterval:   time.Duration(getEnvPositiveInt("WORKER_PUBLISH_INTERVAL_SECONDS", 60)) * time.Second,
+			CampaignInterval:  time.Duration(getEnvPositiveInt("WORKER_CAMPAIGN_INTERVAL_SECONDS", 300)) * time.Second,
+			PendingSupportTTL: time.Duration(getEnvInt("SUPPORT_PENDING_TTL_MINUTES", 1440)) * time.Minute,
+			ReconcileDelay:    time.Duration(getEnvInt("SUPPORT_RECONCILE_DELAY_MINUTES", 10)) * time.Minute,
+			ShutdownTimeout:   time.Duration(getEnvPositiveInt("WORKER_SHUTDOWN_TIMEOUT_SECONDS", 30)) * time.Second,
+			JobRetention:      time.Duration(getEnvInt("JOB_RETENTION_DAYS", 7)) * 24 * time.Hour,
+		},
+
+		Queue: QueueConfig{
+			Concurrency:  getEnvPositiveInt("JOB_QUEUE_CONCURRENCY", 4),
+			PollInterval: time.Duration(getEnvPositiveInt("JOB_QUEUE_POLL_INTERVAL_MS", 1000)) * time.Millisecond,
+		},
+
+		Moderation: ModerationConfig{
+			LLMEnabled: os.Getenv("MODERATION_LLM_ENABLED") == "true",
+			Blocklist:  strings.Split(os.Getenv("MODERATION_BLOCKLIST"), ","),
 		},
 
 		DB: DBConfig{
@@ -96,6 +211,30 @@ func Load() *Config {
 	}
 }
 
+func getEnv(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
+
+func getEnvInt(key string, fallback int) int {
+	v, err := strconv.Atoi(os.Getenv(key))
+	if err != nil {
+		return fallback
+	}
+	return v
+}
+
+// getEnvPositiveInt is getEnvInt for values that must be above zero, like
+// ticker intervals, it falls back when the value is zero or negative.
+func getEnvPositiveInt(key string, fallback int) int {
+	if v := getEnvInt(key, fallback); v > 0 {
+		return v
+	}
+	return fallback
+}
+
 func (c DBConfig) DSN() string {
 	return fmt.Sprintf(
 		"user=%s password=%s host=%s port=%s dbname=%s sslmode=%s timezone=%s",
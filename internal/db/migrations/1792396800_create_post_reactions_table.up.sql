@@ -0,0 +1,14 @@
+CREATE TABLE post_reactions (
+    id BIGSERIAL PRIMARY KEY,
+    post_id BIGINT NOT NULL,
+    user_id BIGINT NOT NULL,
+    reaction VARCHAR(20) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    FOREIGN KEY (post_id) REFERENCES posts(id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    CONSTRAINT post_reactions_post_user_unique UNIQUE (post_id, user_id),
+    CONSTRAINT post_reactions_reaction_check CHECK (reaction IN ('love', 'fire', 'laugh', 'wow', 'sad', 'clap'))
+);
+
+CREATE INDEX idx_post_reactions_post_id ON post_reactions(post_id);
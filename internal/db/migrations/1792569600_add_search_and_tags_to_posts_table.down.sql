@@ -0,0 +1,10 @@
+DROP TABLE IF EXISTS post_tags;
+
+DROP INDEX IF EXISTS idx_posts_search_vector;
+
+ALTER TABLE posts
+    DROP COLUMN IF EXISTS search_vector;
+
+ALTER TABLE posts
+    DROP CONSTRAINT IF EXISTS posts_visibility_check,
+    DROP COLUMN IF EXISTS visibility;
@@ -0,0 +1,25 @@
+ALTER TABLE posts
+    ADD COLUMN visibility VARCHAR(20) NOT NULL DEFAULT 'public',
+    ADD CONSTRAINT posts_visibility_check CHECK (visibility IN ('public', 'supporters'));
+
+ALTER TABLE posts
+    ADD COLUMN search_vector TSVECTOR GENERATED ALWAYS AS (to_tsvector('simple', COALESCE(text, ''))) STORED;
+
+CREATE INDEX idx_posts_search_vector ON posts USING GIN (search_vector);
+
+CREATE TABLE post_tags (
+    post_id BIGINT NOT NULL,
+    tag VARCHAR(50) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (post_id, tag),
+    FOREIGN KEY (post_id) REFERENCES posts(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_post_tags_tag ON post_tags(tag);
+CREATE INDEX idx_post_tags_created_at ON post_tags(created_at);
+
+-- backfill tags of existing posts
+INSERT INTO post_tags (post_id, tag)
+SELECT DISTINCT p.id, LOWER(m[1])
+FROM posts p, REGEXP_MATCHES(p.text, '#([[:alnum:]_]{1,50})', 'g') AS m
+ON CONFLICT DO NOTHING;
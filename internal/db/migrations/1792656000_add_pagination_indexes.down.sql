@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_posts_published_at_id;
+DROP INDEX IF EXISTS idx_supports_creator_amount_id;
+DROP INDEX IF EXISTS idx_supports_fan_sent_at_id;
@@ -0,0 +1,3 @@
+CREATE INDEX idx_posts_published_at_id ON posts(published_at DESC, id DESC);
+CREATE INDEX idx_supports_creator_amount_id ON supports(creator_id, amount DESC, id DESC);
+CREATE INDEX idx_supports_fan_sent_at_id ON supports(fan_id, sent_at DESC, id DESC);
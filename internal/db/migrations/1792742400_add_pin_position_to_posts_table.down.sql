@@ -0,0 +1,4 @@
+ALTER TABLE posts
+    DROP CONSTRAINT IF EXISTS posts_creator_pin_position_unique,
+    DROP CONSTRAINT IF EXISTS posts_pin_position_check,
+    DROP COLUMN IF EXISTS pin_position;
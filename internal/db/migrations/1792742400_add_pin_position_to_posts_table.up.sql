@@ -0,0 +1,4 @@
+ALTER TABLE posts
+    ADD COLUMN pin_position SMALLINT,
+    ADD CONSTRAINT posts_pin_position_check CHECK (pin_position BETWEEN 1 AND 3),
+    ADD CONSTRAINT posts_creator_pin_position_unique UNIQUE (creator_id, pin_position) DEFERRABLE INITIALLY DEFERRED;
@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS idx_comments_created_at;
+DROP INDEX IF EXISTS idx_post_reactions_created_at;
+
+ALTER TABLE supports
+    DROP COLUMN IF EXISTS post_id;
+
+DROP TABLE IF EXISTS post_daily_stats;
+DROP TABLE IF EXISTS post_view_windows;
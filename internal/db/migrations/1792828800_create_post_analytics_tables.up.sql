@@ -0,0 +1,28 @@
+-- one row per viewer per post per hour, used to deduplicate views
+CREATE TABLE post_view_windows (
+    post_id BIGINT NOT NULL,
+    viewer_id BIGINT NOT NULL,
+    window_start TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (post_id, viewer_id, window_start),
+    FOREIGN KEY (post_id) REFERENCES posts(id) ON DELETE CASCADE,
+    FOREIGN KEY (viewer_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_post_view_windows_window_start ON post_view_windows(window_start);
+
+-- daily rollup read by the analytics endpoint
+CREATE TABLE post_daily_stats (
+    post_id BIGINT NOT NULL,
+    day DATE NOT NULL,
+    views INTEGER NOT NULL DEFAULT 0,
+    unique_viewers INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (post_id, day),
+    FOREIGN KEY (post_id) REFERENCES posts(id) ON DELETE CASCADE
+);
+
+ALTER TABLE supports
+    ADD COLUMN post_id BIGINT REFERENCES posts(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_supports_post_id ON supports(post_id);
+CREATE INDEX idx_post_reactions_created_at ON post_reactions(post_id, created_at);
+CREATE INDEX idx_comments_created_at ON comments(post_id, created_at);
@@ -0,0 +1 @@
+-- enum values cannot be dropped, 'admin' is left in user_role
@@ -0,0 +1,19 @@
+DROP TABLE IF EXISTS reports;
+
+ALTER TABLE comments
+    DROP COLUMN IF EXISTS taken_down_at;
+
+DROP INDEX IF EXISTS idx_posts_status;
+
+ALTER TABLE posts
+    DROP CONSTRAINT IF EXISTS posts_status_check,
+    DROP COLUMN IF EXISTS status;
+
+ALTER TABLE users
+    DROP COLUMN IF EXISTS taken_down_at;
+
+ALTER TABLE users
+DROP CONSTRAINT IF EXISTS users_role_check;
+
+ALTER TABLE users
+ADD CONSTRAINT users_role_check CHECK (role IN ('fan', 'creator'));
@@ -0,0 +1,42 @@
+ALTER TABLE users
+DROP CONSTRAINT IF EXISTS users_role_check;
+
+ALTER TABLE users
+ADD CONSTRAINT users_role_check CHECK (role IN ('fan', 'creator', 'admin'));
+
+ALTER TABLE users
+    ADD COLUMN taken_down_at TIMESTAMPTZ;
+
+ALTER TABLE posts
+    ADD COLUMN status VARCHAR(20) NOT NULL DEFAULT 'published',
+    ADD CONSTRAINT posts_status_check CHECK (status IN ('published', 'taken_down'));
+
+CREATE INDEX idx_posts_status ON posts(status);
+
+ALTER TABLE comments
+    ADD COLUMN taken_down_at TIMESTAMPTZ;
+
+CREATE TABLE reports (
+    id BIGSERIAL PRIMARY KEY,
+    reporter_id BIGINT NOT NULL,
+    target_type VARCHAR(20) NOT NULL,
+    target_id BIGINT NOT NULL,
+    reason VARCHAR(30) NOT NULL,
+    details TEXT,
+    status VARCHAR(20) NOT NULL DEFAULT 'open',
+    reviewed_by BIGINT,
+    reviewed_at TIMESTAMPTZ,
+    resolution_note TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    FOREIGN KEY (reporter_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (reviewed_by) REFERENCES users(id) ON DELETE SET NULL,
+    CONSTRAINT reports_target_type_check CHECK (target_type IN ('post', 'comment', 'user')),
+    CONSTRAINT reports_reason_check CHECK (reason IN ('spam', 'harassment', 'hate', 'nudity', 'violence', 'scam', 'other')),
+    CONSTRAINT reports_status_check CHECK (status IN ('open', 'dismissed', 'actioned'))
+);
+
+CREATE INDEX idx_reports_status ON reports(status, id);
+CREATE INDEX idx_reports_target ON reports(target_type, target_id);
+
+-- a user can only have one open report per target
+CREATE UNIQUE INDEX idx_reports_open_per_reporter ON reports(reporter_id, target_type, target_id) WHERE status = 'open';
@@ -0,0 +1,8 @@
+ALTER TABLE posts
+    DROP COLUMN IF EXISTS moderation_reason;
+
+UPDATE posts SET status = 'published' WHERE status = 'pending';
+
+ALTER TABLE posts
+    DROP CONSTRAINT IF EXISTS posts_status_check,
+    ADD CONSTRAINT posts_status_check CHECK (status IN ('published', 'taken_down'));
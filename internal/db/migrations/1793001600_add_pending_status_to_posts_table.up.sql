@@ -0,0 +1,6 @@
+ALTER TABLE posts
+    DROP CONSTRAINT IF EXISTS posts_status_check,
+    ADD CONSTRAINT posts_status_check CHECK (status IN ('published', 'pending', 'taken_down'));
+
+ALTER TABLE posts
+    ADD COLUMN moderation_reason TEXT;
@@ -0,0 +1,17 @@
+CREATE TABLE ai_usage (
+    id BIGSERIAL PRIMARY KEY,
+    creator_id BIGINT NOT NULL,
+    feature VARCHAR(30) NOT NULL,
+    outcome VARCHAR(20) NOT NULL DEFAULT 'pending',
+    prompt_tokens INT NOT NULL DEFAULT 0,
+    completion_tokens INT NOT NULL DEFAULT 0,
+    latency_ms INT,
+    error_message TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    finished_at TIMESTAMPTZ,
+    FOREIGN KEY (creator_id) REFERENCES users(id) ON DELETE CASCADE,
+    CONSTRAINT ai_usage_outcome_check CHECK (outcome IN ('pending', 'success', 'failed', 'cancelled'))
+);
+
+-- quota checks count a creator's calls since the start of the day or month
+CREATE INDEX idx_ai_usage_creator_created_at ON ai_usage(creator_id, created_at);
@@ -0,0 +1,22 @@
+ALTER TABLE users
+    ADD COLUMN handle VARCHAR(50);
+
+-- derive a handle from the name, adding the id when two names collide
+WITH slugs AS (
+    SELECT id, COALESCE(NULLIF(TRIM(BOTH '-' FROM LOWER(REGEXP_REPLACE(name, '[^a-zA-Z0-9]+', '-', 'g'))), ''), 'user') AS slug
+    FROM users
+),
+ranked AS (
+    SELECT id, slug, ROW_NUMBER() OVER (PARTITION BY slug ORDER BY id) AS n
+    FROM slugs
+)
+UPDATE users u
+SET handle = LEFT(CASE WHEN r.n = 1 THEN r.slug ELSE r.slug || '-' || u.id END, 50)
+FROM ranked r
+WHERE r.id = u.id;
+
+ALTER TABLE users
+    ALTER COLUMN handle SET NOT NULL,
+    ADD CONSTRAINT users_handle_format_check CHECK (handle ~ '^[a-z0-9][a-z0-9-]*$');
+
+CREATE UNIQUE INDEX idx_users_handle ON users(handle);
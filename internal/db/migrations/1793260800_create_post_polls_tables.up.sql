@@ -0,0 +1,38 @@
+CREATE TABLE post_polls (
+    id BIGSERIAL PRIMARY KEY,
+    post_id BIGINT NOT NULL UNIQUE,
+    question VARCHAR(200) NOT NULL,
+    mode VARCHAR(20) NOT NULL DEFAULT 'open',
+    closes_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    FOREIGN KEY (post_id) REFERENCES posts(id) ON DELETE CASCADE,
+    CONSTRAINT post_polls_mode_check CHECK (mode IN ('open', 'supporters', 'weighted'))
+);
+
+CREATE TABLE poll_options (
+    id BIGSERIAL PRIMARY KEY,
+    poll_id BIGINT NOT NULL,
+    position SMALLINT NOT NULL,
+    label VARCHAR(100) NOT NULL,
+    FOREIGN KEY (poll_id) REFERENCES post_polls(id) ON DELETE CASCADE,
+    CONSTRAINT poll_options_position_check CHECK (position BETWEEN 1 AND 6),
+    UNIQUE (poll_id, position)
+);
+
+-- weight is 1 per vote, or the voter's paid support to the creator at voting
+-- time on weighted polls
+CREATE TABLE poll_votes (
+    id BIGSERIAL PRIMARY KEY,
+    poll_id BIGINT NOT NULL,
+    option_id BIGINT NOT NULL,
+    user_id BIGINT NOT NULL,
+    weight BIGINT NOT NULL DEFAULT 1,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    FOREIGN KEY (poll_id) REFERENCES post_polls(id) ON DELETE CASCADE,
+    FOREIGN KEY (option_id) REFERENCES poll_options(id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    CONSTRAINT poll_votes_weight_check CHECK (weight > 0),
+    UNIQUE (poll_id, user_id)
+);
+
+CREATE INDEX idx_poll_votes_option_id ON poll_votes(option_id);
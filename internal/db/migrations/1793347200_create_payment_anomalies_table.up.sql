@@ -0,0 +1,18 @@
+-- callbacks that passed the signature check but did not match the support
+-- they point at, kept for admins to investigate. order_id is the
+-- supports.support_id the callback claimed to pay.
+CREATE TABLE payment_anomalies (
+    id BIGSERIAL PRIMARY KEY,
+    reason VARCHAR(30) NOT NULL,
+    order_id VARCHAR(100) NOT NULL,
+    reference TEXT NOT NULL DEFAULT '',
+    merchant_code VARCHAR(50) NOT NULL,
+    amount DECIMAL(15,2) NOT NULL,
+    expected_reference TEXT,
+    expected_amount DECIMAL(15,2),
+    payload JSONB NOT NULL DEFAULT '{}',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT payment_anomalies_reason_check CHECK (reason IN ('merchant_mismatch', 'unknown_order', 'reference_mismatch', 'amount_mismatch'))
+);
+
+CREATE INDEX idx_payment_anomalies_order_id ON payment_anomalies(order_id);
@@ -0,0 +1,4 @@
+ALTER TABLE supports
+    DROP CONSTRAINT IF EXISTS supports_status_check,
+    DROP COLUMN IF EXISTS failure_reason,
+    DROP COLUMN IF EXISTS status_changed_at;
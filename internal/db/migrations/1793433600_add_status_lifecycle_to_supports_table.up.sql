@@ -0,0 +1,10 @@
+ALTER TABLE supports
+    ADD COLUMN status_changed_at TIMESTAMPTZ,
+    ADD COLUMN failure_reason TEXT;
+
+UPDATE supports SET status_changed_at = COALESCE(sent_at, NOW());
+
+ALTER TABLE supports
+    ALTER COLUMN status_changed_at SET DEFAULT NOW(),
+    ALTER COLUMN status_changed_at SET NOT NULL,
+    ADD CONSTRAINT supports_status_check CHECK (status IN ('pending', 'paid', 'failed', 'expired', 'refunded'));
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_posts_scheduled;
+
+UPDATE posts SET status = 'published' WHERE status = 'scheduled';
+
+ALTER TABLE posts
+    DROP CONSTRAINT IF EXISTS posts_status_check,
+    ADD CONSTRAINT posts_status_check CHECK (status IN ('published', 'pending', 'taken_down'));
@@ -0,0 +1,7 @@
+-- a scheduled post keeps its future publish time in published_at until the
+-- worker publishes it
+ALTER TABLE posts
+    DROP CONSTRAINT IF EXISTS posts_status_check,
+    ADD CONSTRAINT posts_status_check CHECK (status IN ('published', 'pending', 'taken_down', 'scheduled'));
+
+CREATE INDEX idx_posts_scheduled ON posts(published_at) WHERE status = 'scheduled';
@@ -0,0 +1,18 @@
+CREATE TABLE jobs (
+    id BIGSERIAL PRIMARY KEY,
+    type VARCHAR(100) NOT NULL,
+    payload JSONB NOT NULL DEFAULT '{}',
+    status VARCHAR(20) NOT NULL DEFAULT 'queued',
+    attempts INT NOT NULL DEFAULT 0,
+    max_attempts INT NOT NULL DEFAULT 8,
+    run_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    locked_at TIMESTAMPTZ,
+    last_error TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT jobs_status_check CHECK (status IN ('queued', 'running', 'done', 'dead')),
+    CONSTRAINT jobs_max_attempts_check CHECK (max_attempts > 0)
+);
+
+CREATE INDEX idx_jobs_runnable ON jobs(run_at, id) WHERE status IN ('queued', 'running');
+CREATE INDEX idx_jobs_dead ON jobs(updated_at) WHERE status = 'dead';
@@ -0,0 +1,5 @@
+ALTER TABLE supports
+    DROP COLUMN IF EXISTS message_hidden,
+    DROP COLUMN IF EXISTS is_anonymous,
+    DROP COLUMN IF EXISTS display_name,
+    DROP COLUMN IF EXISTS message;
@@ -0,0 +1,5 @@
+ALTER TABLE supports
+    ADD COLUMN message VARCHAR(280),
+    ADD COLUMN display_name VARCHAR(50),
+    ADD COLUMN is_anonymous BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN message_hidden BOOLEAN NOT NULL DEFAULT FALSE;
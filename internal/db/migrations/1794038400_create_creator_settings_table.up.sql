@@ -0,0 +1,11 @@
+CREATE TABLE creator_settings (
+    creator_id BIGINT PRIMARY KEY,
+    min_support_amount BIGINT,
+    max_support_amount BIGINT,
+    preset_amounts BIGINT[] NOT NULL DEFAULT '{}',
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    FOREIGN KEY (creator_id) REFERENCES users(id) ON DELETE CASCADE,
+    CHECK (min_support_amount IS NULL OR min_support_amount > 0),
+    CHECK (max_support_amount IS NULL OR min_support_amount IS NULL OR max_support_amount >= min_support_amount),
+    CHECK (cardinality(preset_amounts) <= 6)
+);
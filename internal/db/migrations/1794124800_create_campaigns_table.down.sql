@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_supports_campaign_id;
+ALTER TABLE supports DROP COLUMN IF EXISTS campaign_id;
+DROP TABLE IF EXISTS campaigns;
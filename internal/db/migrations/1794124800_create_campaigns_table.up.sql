@@ -0,0 +1,19 @@
+CREATE TABLE campaigns (
+    id BIGSERIAL PRIMARY KEY,
+    creator_id BIGINT NOT NULL,
+    title VARCHAR(100) NOT NULL,
+    description TEXT,
+    target_amount BIGINT NOT NULL CHECK (target_amount > 0),
+    deadline TIMESTAMPTZ,
+    status VARCHAR(20) NOT NULL DEFAULT 'active' CHECK (status IN ('active', 'reached', 'expired', 'closed')),
+    closed_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    FOREIGN KEY (creator_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_campaigns_creator_id ON campaigns(creator_id);
+CREATE INDEX idx_campaigns_active_deadline ON campaigns(deadline) WHERE status = 'active';
+
+ALTER TABLE supports ADD COLUMN campaign_id BIGINT REFERENCES campaigns(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_supports_campaign_id ON supports(campaign_id) WHERE campaign_id IS NOT NULL;
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_supports_pending_last_checked_at;
+ALTER TABLE supports DROP COLUMN IF EXISTS last_checked_at;
@@ -0,0 +1,3 @@
+ALTER TABLE supports ADD COLUMN last_checked_at TIMESTAMPTZ;
+
+CREATE INDEX idx_supports_pending_last_checked_at ON supports(last_checked_at NULLS FIRST, sent_at) WHERE status = 'pending';
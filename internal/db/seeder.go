@@ -11,30 +11,30 @@ import (
 func SeedUsers(ctx context.Context, db *sqlx.DB) error {
 	users := []user.User{
 		// Creators
-		{Name: "NovaArtemis", Phone: "+62811100001", Role: "creator"},
-		{Name: "LumenKai", Phone: "+62811100002", Role: "creator"},
-		{Name: "OrionVex", Phone: "+62811100003", Role: "creator"},
-		{Name: "AstraNova", Phone: "+62811100004", Role: "creator"},
-		{Name: "VegaSol", Phone: "+62811100005", Role: "creator"},
-		{Name: "EonLyra", Phone: "+62811100006", Role: "creator"},
-		{Name: "CyraZen", Phone: "+62811100007", Role: "creator"},
+		{Name: "NovaArtemis", Handle: "novaartemis", Phone: "+62811100001", Role: "creator"},
+		{Name: "LumenKai", Handle: "lumenkai", Phone: "+62811100002", Role: "creator"},
+		{Name: "OrionVex", Handle: "orionvex", Phone: "+62811100003", Role: "creator"},
+		{Name: "AstraNova", Handle: "astranova", Phone: "+62811100004", Role: "creator"},
+		{Name: "VegaSol", Handle: "vegasol", Phone: "+62811100005", Role: "creator"},
+		{Name: "EonLyra", Handle: "eonlyra", Phone: "+62811100006", Role: "creator"},
+		{Name: "CyraZen", Handle: "cyrazen", Phone: "+62811100007", Role: "creator"},
 
 		// Fans
-		{Name: "KaiRen", Phone: "+62811100008", Role: "fan"},
-		{Name: "MiraSol", Phone: "+62811100009", Role: "fan"},
-		{Name: "LeoNix", Phone: "+62811100010", Role: "fan"},
+		{Name: "KaiRen", Handle: "kairen", Phone: "+62811100008", Role: "fan"},
+		{Name: "MiraSol", Handle: "mirasol", Phone: "+62811100009", Role: "fan"},
+		{Name: "LeoNix", Handle: "leonix", Phone: "+62811100010", Role: "fan"},
 	}
 
 	userQuery := `
-		INSERT INTO users (name, phone, role)
-		VALUES ($1, $2, $3)
+		INSERT INTO users (name, handle, phone, role)
+		VALUES ($1, $2, $3, $4)
 		ON CONFLICT (phone) DO NOTHING
 		RETURNING id;
 	`
 
 	for _, u := range users {
 		var userID int64
-		err := db.QueryRowxContext(ctx, userQuery, u.Name, u.Phone, u.Role).Scan(&userID)
+		err := db.QueryRowxContext(ctx, userQuery, u.Name, u.Handle, u.Phone, u.Role).Scan(&userID)
 		if err != nil {
 			// ambil existing id kalau user sudah ada
 			err := db.GetContext(ctx, &userID, "SELECT id FROM users WHERE phone=$1", u.Phone)
@@ -0,0 +1,96 @@
+package campaign
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rxmy43/support-platform/internal/apperror"
+	"github.com/rxmy43/support-platform/internal/http/middleware"
+	"github.com/rxmy43/support-platform/internal/http/response"
+	"github.com/rxmy43/support-platform/internal/modules/campaign"
+)
+
+type CampaignHandler struct {
+	campaignService *campaign.CampaignService
+}
+
+func NewCampaignHandler(campaignService *campaign.CampaignService) *CampaignHandler {
+	return &CampaignHandler{
+		campaignService: campaignService,
+	}
+}
+
+func (h *CampaignHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req campaign.CampaignCreateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.ToJSON(w, r, apperror.BadRequest("invalid request json format", apperror.CodeInvalidRequestJSONFormat))
+		return
+	}
+
+	userID, ok := middleware.RequireUserID(w, r)
+	if !ok {
+		return
+	}
+
+	created, appErr := h.campaignService.Create(r.Context(), userID, req)
+	if appErr != nil {
+		response.ToJSON(w, r, appErr)
+		return
+	}
+
+	response.ToJSON(w, r, created)
+}
+
+func (h *CampaignHandler) FindAll(w http.ResponseWriter, r *http.Request) {
+	creatorID, err := strconv.ParseUint(chi.URLParam(r, "creatorID"), 10, 64)
+	if err != nil {
+		response.ToJSON(w, r, apperror.BadRequest("invalid creator id", apperror.CodeFieldInvalidFormat))
+		return
+	}
+
+	campaigns, appErr := h.campaignService.FindAll(r.Context(), uint(creatorID))
+	if appErr != nil {
+		response.ToJSON(w, r, appErr)
+		return
+	}
+
+	response.ToJSON(w, r, campaigns)
+}
+
+func (h *CampaignHandler) GetProgress(w http.ResponseWriter, r *http.Request) {
+	campaignID, err := strconv.ParseUint(chi.URLParam(r, "campaignID"), 10, 64)
+	if err != nil {
+		response.ToJSON(w, r, apperror.BadRequest("invalid campaign id", apperror.CodeFieldInvalidFormat))
+		return
+	}
+
+	progress, appErr := h.campaignService.GetProgress(r.Context(), uint(campaignID))
+	if appErr != nil {
+		response.ToJSON(w, r, appErr)
+		return
+	}
+
+	response.ToJSON(w, r, progress)
+}
+
+func (h *CampaignHandler) Close(w http.ResponseWriter, r *http.Request) {
+	campaignID, err := strconv.ParseUint(chi.URLParam(r, "campaignID"), 10, 64)
+	if err != nil {
+		response.ToJSON(w, r, apperror.BadRequest("invalid campaign id", apperror.CodeFieldInvalidFormat))
+		return
+	}
+
+	userID, ok := middleware.RequireUserID(w, r)
+	if !ok {
+		return
+	}
+
+	if appErr := h.campaignService.Close(r.Context(), uint(campaignID), userID); appErr != nil {
+		response.ToJSON(w, r, appErr)
+		return
+	}
+
+	response.ToJSON(w, r, "Campaign has been closed!")
+}
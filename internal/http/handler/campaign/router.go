@@ -0,0 +1,34 @@
+package campaign
+
+import (
+	"github.com/go-chi/chi/v5"
+	"github.com/jmoiron/sqlx"
+	"github.com/rxmy43/support-platform/internal/apperror"
+	"github.com/rxmy43/support-platform/internal/http/middleware"
+	"github.com/rxmy43/support-platform/internal/modules/campaign"
+	"github.com/rxmy43/support-platform/internal/modules/support"
+	"github.com/rxmy43/support-platform/internal/outbox"
+	"github.com/rxmy43/support-platform/internal/queue"
+	"github.com/rxmy43/support-platform/internal/socket"
+)
+
+func CampaignRoutes(r chi.Router, db *sqlx.DB, hub *socket.Hub, jobs *queue.Registry, events *outbox.Dispatcher) {
+	campaignRepo := campaign.NewCampaignRepo(db)
+	campaignService := campaign.NewCampaignService(campaignRepo, hub)
+	handler := NewCampaignHandler(campaignService)
+
+	events.Subscribe(support.EventSupportPaid, campaign.JobProgress)
+	jobs.Register(campaign.JobProgress, campaignService.HandleSupportPaid)
+
+	r.Route("/campaigns", func(r chi.Router) {
+		r.Use(middleware.UserContext)
+		r.Get("/creators/{creatorID}", handler.FindAll)
+		r.Get("/{campaignID}", handler.GetProgress)
+
+		r.Group(func(r chi.Router) {
+			r.Use(middleware.RequireRole("creator", apperror.CodeUnauthorizedOperation))
+			r.Post("/", handler.Create)
+			r.Post("/{campaignID}/close", handler.Close)
+		})
+	})
+}
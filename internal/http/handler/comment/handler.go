@@ -0,0 +1,213 @@
+package comment
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rxmy43/support-platform/internal/apperror"
+	"github.com/rxmy43/support-platform/internal/http/middleware"
+	"github.com/rxmy43/support-platform/internal/http/response"
+	"github.com/rxmy43/support-platform/internal/modules/comment"
+	"github.com/rxmy43/support-platform/internal/pagination"
+)
+
+type CommentHandler struct {
+	commentService *comment.CommentService
+}
+
+func NewCommentHandler(commentService *comment.CommentService) *CommentHandler {
+	return &CommentHandler{
+		commentService: commentService,
+	}
+}
+
+func parseIDParam(r *http.Request, name string) (uint, *apperror.AppError) {
+	parsed, err := strconv.ParseUint(chi.URLParam(r, name), 10, 64)
+	if err != nil {
+		return 0, apperror.BadRequest("invalid "+name, apperror.CodeFieldInvalidFormat)
+	}
+	return uint(parsed), nil
+}
+
+func (h *CommentHandler) Create(w http.ResponseWriter, r *http.Request) {
+	postID, appErr := parseIDParam(r, "postID")
+	if appErr != nil {
+		response.ToJSON(w, r, appErr)
+		return
+	}
+
+	var req comment.CommentCreateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.ToJSON(w, r, apperror.BadRequest("invalid request json format", apperror.CodeInvalidRequestJSONFormat))
+		return
+	}
+
+	userID, ok := middleware.RequireUserID(w, r)
+	if !ok {
+		return
+	}
+
+	created, err := h.commentService.Create(r.Context(), postID, userID, req)
+	if err != nil {
+		response.ToJSON(w, r, err)
+		return
+	}
+
+	response.ToJSON(w, r, created)
+}
+
+func (h *CommentHandler) FindAll(w http.ResponseWriter, r *http.Request) {
+	postID, appErr := parseIDParam(r, "postID")
+	if appErr != nil {
+		response.ToJSON(w, r, appErr)
+		return
+	}
+
+	params, appErr := pagination.FromRequest(r)
+	if appErr != nil {
+		response.ToJSON(w, r, appErr)
+		return
+	}
+
+	userID, ok := middleware.RequireUserID(w, r)
+	if !ok {
+		return
+	}
+
+	page, err := h.commentService.FindAll(r.Context(), postID, params, userID)
+	if err != nil {
+		response.ToJSON(w, r, err)
+		return
+	}
+
+	response.ToJSON(w, r, response.Paginate(page, params.Limit))
+}
+
+func (h *CommentHandler) FindReplies(w http.ResponseWriter, r *http.Request) {
+	commentID, appErr := parseIDParam(r, "commentID")
+	if appErr != nil {
+		response.ToJSON(w, r, appErr)
+		return
+	}
+
+	params, appErr := pagination.FromRequest(r)
+	if appErr != nil {
+		response.ToJSON(w, r, appErr)
+		return
+	}
+
+	userID, ok := middleware.RequireUserID(w, r)
+	if !ok {
+		return
+	}
+
+	page, err := h.commentService.FindReplies(r.Context(), commentID, params, userID)
+	if err != nil {
+		response.ToJSON(w, r, err)
+		return
+	}
+
+	response.ToJSON(w, r, response.Paginate(page, params.Limit))
+}
+
+func (h *CommentHandler) Update(w http.ResponseWriter, r *http.Request) {
+	commentID, appErr := parseIDParam(r, "commentID")
+	if appErr != nil {
+		response.ToJSON(w, r, appErr)
+		return
+	}
+
+	var req comment.CommentUpdateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.ToJSON(w, r, apperror.BadRequest("invalid request json format", apperror.CodeInvalidRequestJSONFormat))
+		return
+	}
+
+	userID, ok := middleware.RequireUserID(w, r)
+	if !ok {
+		return
+	}
+
+	updated, err := h.commentService.Update(r.Context(), commentID, userID, req)
+	if err != nil {
+		response.ToJSON(w, r, err)
+		return
+	}
+
+	response.ToJSON(w, r, updated)
+}
+
+func (h *CommentHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	commentID, appErr := parseIDParam(r, "commentID")
+	if appErr != nil {
+		response.ToJSON(w, r, appErr)
+		return
+	}
+
+	userID, ok := middleware.RequireUserID(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.commentService.Delete(r.Context(), commentID, userID); err != nil {
+		response.ToJSON(w, r, err)
+		return
+	}
+
+	response.ToJSON(w, r, "Comment has been deleted!")
+}
+
+// moderationHandler builds the creator-only moderation endpoints, which all
+// take a comment id and differ only in the service call.
+func (h *CommentHandler) moderationHandler(action func(r *http.Request, commentID, userID uint) *apperror.AppError, message string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		commentID, appErr := parseIDParam(r, "commentID")
+		if appErr != nil {
+			response.ToJSON(w, r, appErr)
+			return
+		}
+
+		if userRole := middleware.GetUserRole(r.Context()); userRole != "creator" {
+			response.ToJSON(w, r, apperror.Forbidden("only creator allowed to moderate comments", apperror.CodeUnauthorizedOperation))
+			return
+		}
+
+		userID, ok := middleware.RequireUserID(w, r)
+		if !ok {
+			return
+		}
+
+		if err := action(r, commentID, userID); err != nil {
+			response.ToJSON(w, r, err)
+			return
+		}
+
+		response.ToJSON(w, r, message)
+	}
+}
+
+func (h *CommentHandler) Hide() http.HandlerFunc {
+	return h.moderationHandler(func(r *http.Request, commentID, userID uint) *apperror.AppError {
+		return h.commentService.SetHidden(r.Context(), commentID, userID, true)
+	}, "Comment has been hidden!")
+}
+
+func (h *CommentHandler) Unhide() http.HandlerFunc {
+	return h.moderationHandler(func(r *http.Request, commentID, userID uint) *apperror.AppError {
+		return h.commentService.SetHidden(r.Context(), commentID, userID, false)
+	}, "Comment is visible again!")
+}
+
+func (h *CommentHandler) Pin() http.HandlerFunc {
+	return h.moderationHandler(func(r *http.Request, commentID, userID uint) *apperror.AppError {
+		return h.commentService.Pin(r.Context(), commentID, userID)
+	}, "Comment has been pinned!")
+}
+
+func (h *CommentHandler) Unpin() http.HandlerFunc {
+	return h.moderationHandler(func(r *http.Request, commentID, userID uint) *apperror.AppError {
+		return h.commentService.Unpin(r.Context(), commentID, userID)
+	}, "Comment has been unpinned!")
+}
@@ -0,0 +1,31 @@
+package comment
+
+import (
+	"github.com/go-chi/chi/v5"
+	"github.com/jmoiron/sqlx"
+	"github.com/rxmy43/support-platform/internal/http/middleware"
+	"github.com/rxmy43/support-platform/internal/modules/comment"
+	"github.com/rxmy43/support-platform/internal/modules/post"
+	"github.com/rxmy43/support-platform/internal/socket"
+)
+
+func CommentRoutes(r chi.Router, db *sqlx.DB, hub *socket.Hub) {
+	commentRepo := comment.NewCommentRepo(db)
+	postRepo := post.NewPostRepo(db)
+
+	commentService := comment.NewCommentService(commentRepo, postRepo, hub)
+	handler := NewCommentHandler(commentService)
+
+	r.Route("/comments", func(r chi.Router) {
+		r.Use(middleware.UserContext)
+		r.Get("/post/{postID}", handler.FindAll)
+		r.Post("/post/{postID}", handler.Create)
+		r.Get("/{commentID}/replies", handler.FindReplies)
+		r.Put("/{commentID}", handler.Update)
+		r.Delete("/{commentID}", handler.Delete)
+		r.Post("/{commentID}/hide", handler.Hide())
+		r.Delete("/{commentID}/hide", handler.Unhide())
+		r.Post("/{commentID}/pin", handler.Pin())
+		r.Delete("/{commentID}/pin", handler.Unpin())
+	})
+}
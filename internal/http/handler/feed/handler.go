@@ -0,0 +1,48 @@
+package feed
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rxmy43/support-platform/internal/http/response"
+	"github.com/rxmy43/support-platform/internal/modules/feed"
+)
+
+type FeedHandler struct {
+	feedService *feed.FeedService
+}
+
+func NewFeedHandler(feedService *feed.FeedService) *FeedHandler {
+	return &FeedHandler{
+		feedService: feedService,
+	}
+}
+
+func (h *FeedHandler) RSS(w http.ResponseWriter, r *http.Request) {
+	h.serve(w, r, feed.FormatRSS)
+}
+
+func (h *FeedHandler) Atom(w http.ResponseWriter, r *http.Request) {
+	h.serve(w, r, feed.FormatAtom)
+}
+
+// serve answers conditional requests through http.ServeContent, using a hash
+// of the rendered feed as ETag so edits to a post invalidate it too.
+func (h *FeedHandler) serve(w http.ResponseWriter, r *http.Request, format string) {
+	doc, err := h.feedService.Render(r.Context(), chi.URLParam(r, "handle"), format)
+	if err != nil {
+		response.ToJSON(w, r, err)
+		return
+	}
+
+	sum := sha256.Sum256(doc.Body)
+
+	w.Header().Set("Content-Type", doc.ContentType)
+	w.Header().Set("ETag", `"`+hex.EncodeToString(sum[:16])+`"`)
+	w.Header().Set("Cache-Control", "public, max-age=300")
+
+	http.ServeContent(w, r, "", doc.LastModified, bytes.NewReader(doc.Body))
+}
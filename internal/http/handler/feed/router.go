@@ -0,0 +1,23 @@
+package feed
+
+import (
+	"github.com/go-chi/chi/v5"
+	"github.com/jmoiron/sqlx"
+	"github.com/rxmy43/support-platform/internal/config"
+	"github.com/rxmy43/support-platform/internal/modules/feed"
+	"github.com/rxmy43/support-platform/internal/modules/post"
+	"github.com/rxmy43/support-platform/internal/modules/user"
+)
+
+func FeedRoutes(r chi.Router, db *sqlx.DB, cfg *config.Config) {
+	postRepo := post.NewPostRepo(db)
+	userRepo := user.NewUserRepo(db)
+
+	feedService := feed.NewFeedService(postRepo, userRepo, cfg.AppURL, cfg.WebURL)
+	handler := NewFeedHandler(feedService)
+
+	r.Route("/creators/{handle}", func(r chi.Router) {
+		r.Get("/feed.xml", handler.RSS)
+		r.Get("/feed.atom", handler.Atom)
+	})
+}
@@ -0,0 +1,64 @@
+package notification
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rxmy43/support-platform/internal/apperror"
+	"github.com/rxmy43/support-platform/internal/http/middleware"
+	"github.com/rxmy43/support-platform/internal/http/response"
+	"github.com/rxmy43/support-platform/internal/modules/notification"
+	"github.com/rxmy43/support-platform/internal/pagination"
+)
+
+type NotificationHandler struct {
+	notificationService *notification.NotificationService
+}
+
+func NewNotificationHandler(notificationService *notification.NotificationService) *NotificationHandler {
+	return &NotificationHandler{
+		notificationService: notificationService,
+	}
+}
+
+func (h *NotificationHandler) FindAll(w http.ResponseWriter, r *http.Request) {
+	params, appErr := pagination.FromRequest(r)
+	if appErr != nil {
+		response.ToJSON(w, r, appErr)
+		return
+	}
+
+	userID, ok := middleware.RequireUserID(w, r)
+	if !ok {
+		return
+	}
+
+	page, appErr := h.notificationService.FindAll(r.Context(), params, userID, r.URL.Query().Get("unread") == "true")
+	if appErr != nil {
+		response.ToJSON(w, r, appErr)
+		return
+	}
+
+	response.ToJSON(w, r, response.Paginate(page, params.Limit))
+}
+
+func (h *NotificationHandler) MarkRead(w http.ResponseWriter, r *http.Request) {
+	notificationID, err := strconv.ParseUint(chi.URLParam(r, "notificationID"), 10, 64)
+	if err != nil {
+		response.ToJSON(w, r, apperror.BadRequest("invalid notification id", apperror.CodeFieldInvalidFormat))
+		return
+	}
+
+	userID, ok := middleware.RequireUserID(w, r)
+	if !ok {
+		return
+	}
+
+	if appErr := h.notificationService.MarkRead(r.Context(), uint(notificationID), userID); appErr != nil {
+		response.ToJSON(w, r, appErr)
+		return
+	}
+
+	response.ToJSON(w, r, "Notification marked as read")
+}
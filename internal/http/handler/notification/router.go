@@ -0,0 +1,29 @@
+package notification
+
+import (
+	"github.com/go-chi/chi/v5"
+	"github.com/jmoiron/sqlx"
+	"github.com/rxmy43/support-platform/internal/http/middleware"
+	"github.com/rxmy43/support-platform/internal/modules/notification"
+	"github.com/rxmy43/support-platform/internal/modules/report"
+	"github.com/rxmy43/support-platform/internal/modules/support"
+	"github.com/rxmy43/support-platform/internal/outbox"
+	"github.com/rxmy43/support-platform/internal/queue"
+)
+
+func NotificationRoutes(r chi.Router, db *sqlx.DB, jobs *queue.Registry, events *outbox.Dispatcher) {
+	notificationRepo := notification.NewNotificationRepo(db)
+	notificationService := notification.NewNotificationService(notificationRepo)
+	handler := NewNotificationHandler(notificationService)
+
+	events.Subscribe(support.EventSupportPaid, notification.JobSupportPaid)
+	jobs.Register(notification.JobSupportPaid, notificationService.HandleSupportPaid)
+	events.Subscribe(report.EventContentTakenDown, notification.JobContentTakenDown)
+	jobs.Register(notification.JobContentTakenDown, notificationService.HandleContentTakenDown)
+
+	r.Route("/notifications", func(r chi.Router) {
+		r.Use(middleware.UserContext)
+		r.Get("/", handler.FindAll)
+		r.Post("/{notificationID}/read", handler.MarkRead)
+	})
+}
@@ -5,10 +5,12 @@ import (
 	"net/http"
 	"strconv"
 
+	"github.com/go-chi/chi/v5"
 	"github.com/rxmy43/support-platform/internal/apperror"
 	"github.com/rxmy43/support-platform/internal/http/middleware"
 	"github.com/rxmy43/support-platform/internal/http/response"
 	"github.com/rxmy43/support-platform/internal/modules/post"
+	"github.com/rxmy43/support-platform/internal/pagination"
 )
 
 type PostHandler struct {
@@ -43,77 +45,492 @@ func (h *PostHandler) Create(w http.ResponseWriter, r *http.Request) {
 	defer file.Close()
 
 	req := post.PostCreateRequest{
-		CreatorID: userID,
-		Text:      r.FormValue("text"),
-		File:      file,
-		Header:    header,
+		CreatorID:  userID,
+		Text:       r.FormValue("text"),
+		Visibility: r.FormValue("visibility"),
+		File:       file,
+		Header:     header,
+		PublishAt:  r.FormValue("publish_at"),
 	}
 
-	if err := h.postService.Create(r.Context(), req); err != nil {
-		response.ToJSON(w, r, err)
+	if options := r.MultipartForm.Value["poll_options"]; len(options) > 0 || r.FormValue("poll_question") != "" {
+		req.Poll = &post.PollRequest{
+			Question: r.FormValue("poll_question"),
+			Options:  options,
+			Mode:     r.FormValue("poll_mode"),
+			ClosesAt: r.FormValue("poll_closes_at"),
+		}
+	}
+
+	created, appErr := h.postService.Create(r.Context(), req)
+	if appErr != nil {
+		response.ToJSON(w, r, appErr)
+		return
+	}
+
+	if created.Status == post.StatusPending {
+		response.ToJSON(w, r, "Post has been created and is waiting for review!")
+		return
+	}
+
+	if created.Status == post.StatusScheduled {
+		response.ToJSON(w, r, "Post has been scheduled!")
 		return
 	}
 
 	response.ToJSON(w, r, "Post has been created!")
 }
 
+func (h *PostHandler) Update(w http.ResponseWriter, r *http.Request) {
+	postID, appErr := parsePostID(r)
+	if appErr != nil {
+		response.ToJSON(w, r, appErr)
+		return
+	}
+
+	var req post.PostUpdateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.ToJSON(w, r, apperror.BadRequest("invalid request json format", apperror.CodeInvalidRequestJSONFormat))
+		return
+	}
+
+	creatorID, ok := h.creatorID(w, r)
+	if !ok {
+		return
+	}
+
+	updated, appErr := h.postService.Update(r.Context(), postID, creatorID, req)
+	if appErr != nil {
+		response.ToJSON(w, r, appErr)
+		return
+	}
+
+	if updated.Status == post.StatusPending {
+		response.ToJSON(w, r, "Post has been updated and is waiting for review!")
+		return
+	}
+
+	response.ToJSON(w, r, "Post has been updated!")
+}
+
 func (h *PostHandler) GenerateCaption(w http.ResponseWriter, r *http.Request) {
-	var req struct {
-		Tone string `json:"tone"`
+	var req post.CaptionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.ToJSON(w, r, apperror.BadRequest("invalid request json format", apperror.CodeInvalidRequestJSONFormat))
+		return
+	}
+	defer r.Body.Close()
+
+	creatorID, ok := h.creatorID(w, r)
+	if !ok {
+		return
+	}
+
+	captions, err := h.postService.GenerateCaption(r.Context(), creatorID, req)
+	if err != nil {
+		response.ToJSON(w, r, err)
+		return
 	}
 
+	response.ToJSON(w, r, captions)
+}
+
+// StreamCaption streams a caption as Server-Sent Events: an optional
+// "image_description" event, "delta" events with the generated text, then
+// "done" or "error". Validation errors are answered as plain JSON.
+func (h *PostHandler) StreamCaption(w http.ResponseWriter, r *http.Request) {
+	var req post.CaptionRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		response.ToJSON(w, r, apperror.BadRequest("invalid request json format", apperror.CodeInvalidRequestJSONFormat))
 		return
 	}
 	defer r.Body.Close()
 
-	caption, err := h.postService.GenerateCaption(r.Context(), req.Tone)
+	creatorID, ok := h.creatorID(w, r)
+	if !ok {
+		return
+	}
+
+	var stream *response.EventStream
+
+	onStart := func(imageDescription *string) error {
+		var err error
+		if stream, err = response.NewEventStream(w); err != nil {
+			return err
+		}
+
+		if imageDescription != nil {
+			return stream.Send("image_description", map[string]string{"description": *imageDescription})
+		}
+		return nil
+	}
+
+	onDelta := func(delta string) error {
+		return stream.Send("delta", map[string]string{"delta": delta})
+	}
+
+	appErr := h.postService.StreamCaption(r.Context(), creatorID, req, onStart, onDelta)
+	if stream == nil {
+		if appErr != nil {
+			response.ToJSON(w, r, appErr)
+		}
+		return
+	}
+
+	// the client went away, nobody is left to tell
+	if r.Context().Err() != nil {
+		return
+	}
+
+	if appErr != nil {
+		stream.SendError(appErr)
+		return
+	}
+
+	stream.Send("done", map[string]bool{"done": true})
+}
+
+func (h *PostHandler) AIUsage(w http.ResponseWriter, r *http.Request) {
+	creatorID, ok := h.creatorID(w, r)
+	if !ok {
+		return
+	}
+
+	usage, err := h.postService.AIUsage(r.Context(), creatorID)
 	if err != nil {
 		response.ToJSON(w, r, err)
 		return
 	}
 
-	resp := map[string]string{"caption": caption}
-	response.ToJSON(w, r, resp)
+	response.ToJSON(w, r, usage)
 }
 
 func (h *PostHandler) FindAll(w http.ResponseWriter, r *http.Request) {
-	cursorStr := r.URL.Query().Get("cursor")
-	var cursor *uint
+	params, appErr := pagination.FromRequest(r)
+	if appErr != nil {
+		response.ToJSON(w, r, appErr)
+		return
+	}
+
+	var userID *uint
+	if userRole := middleware.GetUserRole(r.Context()); userRole != "" && userRole == "creator" {
+		userID = middleware.GetUserID(r.Context())
+	}
+
+	viewerID := middleware.GetUserID(r.Context())
+
+	page, appErr := h.postService.FindAll(r.Context(), params, userID, viewerID)
+	if appErr != nil {
+		response.ToJSON(w, r, appErr)
+		return
+	}
+
+	response.ToJSON(w, r, response.Paginate(page, params.Limit))
+}
+
+func parsePostID(r *http.Request) (uint, *apperror.AppError) {
+	parsed, err := strconv.ParseUint(chi.URLParam(r, "postID"), 10, 64)
+	if err != nil {
+		return 0, apperror.BadRequest("invalid post id", apperror.CodeFieldInvalidFormat)
+	}
+	return uint(parsed), nil
+}
+
+func (h *PostHandler) AddReaction(w http.ResponseWriter, r *http.Request) {
+	postID, appErr := parsePostID(r)
+	if appErr != nil {
+		response.ToJSON(w, r, appErr)
+		return
+	}
+
+	var req post.ReactionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.ToJSON(w, r, apperror.BadRequest("invalid request json format", apperror.CodeInvalidRequestJSONFormat))
+		return
+	}
+
+	userID := middleware.GetUserID(r.Context())
+	if userID == nil {
+		response.ToJSON(w, r, apperror.Unauthorized("invalid user id", apperror.CodeUnauthorizedOperation))
+		return
+	}
+
+	if err := h.postService.AddReaction(r.Context(), postID, *userID, req); err != nil {
+		response.ToJSON(w, r, err)
+		return
+	}
+
+	response.ToJSON(w, r, "Reaction has been added!")
+}
+
+func (h *PostHandler) ChangeReaction(w http.ResponseWriter, r *http.Request) {
+	postID, appErr := parsePostID(r)
+	if appErr != nil {
+		response.ToJSON(w, r, appErr)
+		return
+	}
+
+	var req post.ReactionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.ToJSON(w, r, apperror.BadRequest("invalid request json format", apperror.CodeInvalidRequestJSONFormat))
+		return
+	}
+
+	userID := middleware.GetUserID(r.Context())
+	if userID == nil {
+		response.ToJSON(w, r, apperror.Unauthorized("invalid user id", apperror.CodeUnauthorizedOperation))
+		return
+	}
+
+	if err := h.postService.ChangeReaction(r.Context(), postID, *userID, req); err != nil {
+		response.ToJSON(w, r, err)
+		return
+	}
+
+	response.ToJSON(w, r, "Reaction has been changed!")
+}
+
+func (h *PostHandler) RemoveReaction(w http.ResponseWriter, r *http.Request) {
+	postID, appErr := parsePostID(r)
+	if appErr != nil {
+		response.ToJSON(w, r, appErr)
+		return
+	}
+
+	userID := middleware.GetUserID(r.Context())
+	if userID == nil {
+		response.ToJSON(w, r, apperror.Unauthorized("invalid user id", apperror.CodeUnauthorizedOperation))
+		return
+	}
 
-	if cursorStr != "" {
-		parsed, err := strconv.ParseUint(cursorStr, 10, 64)
+	if err := h.postService.RemoveReaction(r.Context(), postID, *userID); err != nil {
+		response.ToJSON(w, r, err)
+		return
+	}
+
+	response.ToJSON(w, r, "Reaction has been removed!")
+}
+
+func (h *PostHandler) Search(w http.ResponseWriter, r *http.Request) {
+	params, appErr := pagination.FromRequest(r)
+	if appErr != nil {
+		response.ToJSON(w, r, appErr)
+		return
+	}
+
+	viewerID := middleware.GetUserID(r.Context())
+
+	query := r.URL.Query()
+	page, appErr := h.postService.Search(r.Context(), query.Get("q"), query.Get("tag"), params, viewerID)
+	if appErr != nil {
+		response.ToJSON(w, r, appErr)
+		return
+	}
+
+	response.ToJSON(w, r, response.Paginate(page, params.Limit))
+}
+
+func (h *PostHandler) TrendingTags(w http.ResponseWriter, r *http.Request) {
+	tags, err := h.postService.TrendingTags(r.Context(), r.URL.Query().Get("window"))
+	if err != nil {
+		response.ToJSON(w, r, err)
+		return
+	}
+
+	response.ToJSON(w, r, tags)
+}
+
+func (h *PostHandler) creatorID(w http.ResponseWriter, r *http.Request) (uint, bool) {
+	if userRole := middleware.GetUserRole(r.Context()); userRole != "creator" {
+		response.ToJSON(w, r, apperror.Forbidden("only creator allowed to manage posts", apperror.CodeUnauthorizedOperation))
+		return 0, false
+	}
+
+	userID := middleware.GetUserID(r.Context())
+	if userID == nil {
+		response.ToJSON(w, r, apperror.Unauthorized("invalid user id", apperror.CodeUnauthorizedOperation))
+		return 0, false
+	}
+
+	return *userID, true
+}
+
+func (h *PostHandler) Pin(w http.ResponseWriter, r *http.Request) {
+	postID, appErr := parsePostID(r)
+	if appErr != nil {
+		response.ToJSON(w, r, appErr)
+		return
+	}
+
+	creatorID, ok := h.creatorID(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.postService.Pin(r.Context(), postID, creatorID); err != nil {
+		response.ToJSON(w, r, err)
+		return
+	}
+
+	response.ToJSON(w, r, "Post has been pinned!")
+}
+
+func (h *PostHandler) Unpin(w http.ResponseWriter, r *http.Request) {
+	postID, appErr := parsePostID(r)
+	if appErr != nil {
+		response.ToJSON(w, r, appErr)
+		return
+	}
+
+	creatorID, ok := h.creatorID(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.postService.Unpin(r.Context(), postID, creatorID); err != nil {
+		response.ToJSON(w, r, err)
+		return
+	}
+
+	response.ToJSON(w, r, "Post has been unpinned!")
+}
+
+func (h *PostHandler) ReorderPins(w http.ResponseWriter, r *http.Request) {
+	var req post.ReorderPinsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.ToJSON(w, r, apperror.BadRequest("invalid request json format", apperror.CodeInvalidRequestJSONFormat))
+		return
+	}
+
+	creatorID, ok := h.creatorID(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.postService.ReorderPins(r.Context(), creatorID, req); err != nil {
+		response.ToJSON(w, r, err)
+		return
+	}
+
+	response.ToJSON(w, r, "Pinned posts have been reordered!")
+}
+
+func (h *PostHandler) FindOne(w http.ResponseWriter, r *http.Request) {
+	postID, appErr := parsePostID(r)
+	if appErr != nil {
+		response.ToJSON(w, r, appErr)
+		return
+	}
+
+	p, appErr := h.postService.FindOne(r.Context(), postID, middleware.GetUserID(r.Context()))
+	if appErr != nil {
+		response.ToJSON(w, r, appErr)
+		return
+	}
+
+	response.ToJSON(w, r, p)
+}
+
+func (h *PostHandler) Analytics(w http.ResponseWriter, r *http.Request) {
+	postID, appErr := parsePostID(r)
+	if appErr != nil {
+		response.ToJSON(w, r, appErr)
+		return
+	}
+
+	days := 30
+	if daysStr := r.URL.Query().Get("days"); daysStr != "" {
+		parsed, err := strconv.Atoi(daysStr)
 		if err != nil {
-			response.ToJSON(w, r, apperror.BadRequest("invalid cursor", apperror.CodeUnknown))
+			response.ToJSON(w, r, apperror.BadRequest("invalid days", apperror.CodeNumberInvalid))
 			return
 		}
+		days = parsed
+	}
 
-		temp := uint(parsed)
-		cursor = &temp
+	creatorID, ok := h.creatorID(w, r)
+	if !ok {
+		return
 	}
 
-	var userID *uint
-	if userRole := middleware.GetUserRole(r.Context()); userRole != "" && userRole == "creator" {
-		userID = middleware.GetUserID(r.Context())
+	analytics, err := h.postService.Analytics(r.Context(), postID, creatorID, days)
+	if err != nil {
+		response.ToJSON(w, r, err)
+		return
 	}
 
-	posts, nextCursor, appErr := h.postService.FindAll(r.Context(), cursor, userID)
+	response.ToJSON(w, r, analytics)
+}
+
+func (h *PostHandler) FindPending(w http.ResponseWriter, r *http.Request) {
+	params, appErr := pagination.FromRequest(r)
 	if appErr != nil {
 		response.ToJSON(w, r, appErr)
 		return
 	}
 
-	data := make([]any, len(posts))
-	for i, p := range posts {
-		data[i] = p
+	page, appErr := h.postService.FindPending(r.Context(), params)
+	if appErr != nil {
+		response.ToJSON(w, r, appErr)
+		return
 	}
 
-	resp := response.SuccessPaginateResponse{
-		Status:     response.StatusSuccess,
-		Data:       data,
-		NextCursor: nextCursor,
+	response.ToJSON(w, r, response.Paginate(page, params.Limit))
+}
+
+func (h *PostHandler) Approve(w http.ResponseWriter, r *http.Request) {
+	postID, appErr := parsePostID(r)
+	if appErr != nil {
+		response.ToJSON(w, r, appErr)
+		return
+	}
+
+	if err := h.postService.Review(r.Context(), postID, true); err != nil {
+		response.ToJSON(w, r, err)
+		return
+	}
+
+	response.ToJSON(w, r, "Post has been approved!")
+}
+
+func (h *PostHandler) Reject(w http.ResponseWriter, r *http.Request) {
+	postID, appErr := parsePostID(r)
+	if appErr != nil {
+		response.ToJSON(w, r, appErr)
+		return
+	}
+
+	if err := h.postService.Review(r.Context(), postID, false); err != nil {
+		response.ToJSON(w, r, err)
+		return
+	}
+
+	response.ToJSON(w, r, "Post has been rejected!")
+}
+
+func (h *PostHandler) Vote(w http.ResponseWriter, r *http.Request) {
+	postID, appErr := parsePostID(r)
+	if appErr != nil {
+		response.ToJSON(w, r, appErr)
+		return
+	}
+
+	var req post.VoteRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.ToJSON(w, r, apperror.BadRequest("invalid request json format", apperror.CodeInvalidRequestJSONFormat))
+		return
+	}
+
+	userID := middleware.GetUserID(r.Context())
+	if userID == nil {
+		response.ToJSON(w, r, apperror.Unauthorized("invalid user id", apperror.CodeUnauthorizedOperation))
+		return
+	}
+
+	if err := h.postService.Vote(r.Context(), postID, *userID, req); err != nil {
+		response.ToJSON(w, r, err)
+		return
 	}
 
-	response.ToJSON(w, r, resp)
+	response.ToJSON(w, r, "Vote has been recorded!")
 }
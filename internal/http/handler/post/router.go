@@ -1,24 +1,60 @@
 package post
 
 import (
+	"log"
+
 	"github.com/go-chi/chi/v5"
 	"github.com/jmoiron/sqlx"
+	"github.com/rxmy43/support-platform/internal/config"
 	"github.com/rxmy43/support-platform/internal/http/middleware"
+	"github.com/rxmy43/support-platform/internal/llm"
+	"github.com/rxmy43/support-platform/internal/moderation"
+	"github.com/rxmy43/support-platform/internal/modules/aiusage"
 	"github.com/rxmy43/support-platform/internal/modules/post"
 	"github.com/rxmy43/support-platform/internal/modules/user"
 )
 
-func PostRoutes(r chi.Router, db *sqlx.DB) {
+func PostRoutes(r chi.Router, db *sqlx.DB, cfg *config.Config) {
 	postRepo := post.NewPostRepo(db)
 	userRepo := user.NewUserRepo(db)
 
-	postService := post.NewPostService(postRepo, userRepo)
+	llmClient, err := llm.NewFromConfig(cfg.LLM)
+	if err != nil {
+		log.Fatal("LLM client setup failed ", err)
+	}
+	moderator := moderation.NewFromConfig(cfg, llmClient)
+
+	aiUsageService := aiusage.NewAIUsageService(aiusage.NewAIUsageRepo(db), userRepo, cfg.AIQuota)
+
+	postService := post.NewPostService(postRepo, userRepo, moderator, llmClient, aiUsageService)
 	handler := NewPostHandler(postService)
 
 	r.Route("/posts", func(r chi.Router) {
 		r.Use(middleware.UserContext)
 		r.Post("/", handler.Create)
 		r.Get("/", handler.FindAll)
+		r.Get("/search", handler.Search)
+		r.Get("/trending-tags", handler.TrendingTags)
 		r.Post("/ai-caption", handler.GenerateCaption)
+		r.Post("/ai-caption/stream", handler.StreamCaption)
+		r.Get("/ai-caption/usage", handler.AIUsage)
+		r.Put("/pins", handler.ReorderPins)
+		r.Get("/{postID}", handler.FindOne)
+		r.Put("/{postID}", handler.Update)
+		r.Get("/{postID}/analytics", handler.Analytics)
+		r.Post("/{postID}/pin", handler.Pin)
+		r.Delete("/{postID}/pin", handler.Unpin)
+		r.Post("/{postID}/reactions", handler.AddReaction)
+		r.Put("/{postID}/reactions", handler.ChangeReaction)
+		r.Delete("/{postID}/reactions", handler.RemoveReaction)
+		r.Post("/{postID}/poll/votes", handler.Vote)
+	})
+
+	r.Route("/admin/posts", func(r chi.Router) {
+		r.Use(middleware.UserContext)
+		r.Use(middleware.RequireAdmin(user.NewUserService(userRepo)))
+		r.Get("/pending", handler.FindPending)
+		r.Post("/{postID}/approve", handler.Approve)
+		r.Post("/{postID}/reject", handler.Reject)
 	})
 }
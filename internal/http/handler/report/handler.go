@@ -0,0 +1,130 @@
+package report
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rxmy43/support-platform/internal/apperror"
+	"github.com/rxmy43/support-platform/internal/http/middleware"
+	"github.com/rxmy43/support-platform/internal/http/response"
+	"github.com/rxmy43/support-platform/internal/modules/report"
+	"github.com/rxmy43/support-platform/internal/pagination"
+)
+
+type ReportHandler struct {
+	reportService *report.ReportService
+}
+
+func NewReportHandler(reportService *report.ReportService) *ReportHandler {
+	return &ReportHandler{
+		reportService: reportService,
+	}
+}
+
+func parseReportID(r *http.Request) (uint, *apperror.AppError) {
+	parsed, err := strconv.ParseUint(chi.URLParam(r, "reportID"), 10, 64)
+	if err != nil {
+		return 0, apperror.BadRequest("invalid report id", apperror.CodeFieldInvalidFormat)
+	}
+	return uint(parsed), nil
+}
+
+func (h *ReportHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req report.ReportCreateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.ToJSON(w, r, apperror.BadRequest("invalid request json format", apperror.CodeInvalidRequestJSONFormat))
+		return
+	}
+
+	userID, ok := middleware.RequireUserID(w, r)
+	if !ok {
+		return
+	}
+
+	created, err := h.reportService.Create(r.Context(), userID, req)
+	if err != nil {
+		response.ToJSON(w, r, err)
+		return
+	}
+
+	response.ToJSON(w, r, map[string]any{"id": created.ID, "status": created.Status})
+}
+
+func (h *ReportHandler) FindAll(w http.ResponseWriter, r *http.Request) {
+	params, appErr := pagination.FromRequest(r)
+	if appErr != nil {
+		response.ToJSON(w, r, appErr)
+		return
+	}
+
+	userID, ok := middleware.RequireUserID(w, r)
+	if !ok {
+		return
+	}
+
+	page, err := h.reportService.FindAll(r.Context(), userID, r.URL.Query().Get("status"), params)
+	if err != nil {
+		response.ToJSON(w, r, err)
+		return
+	}
+
+	response.ToJSON(w, r, response.Paginate(page, params.Limit))
+}
+
+func (h *ReportHandler) Dismiss(w http.ResponseWriter, r *http.Request) {
+	reportID, appErr := parseReportID(r)
+	if appErr != nil {
+		response.ToJSON(w, r, appErr)
+		return
+	}
+
+	var req report.ReviewRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			response.ToJSON(w, r, apperror.BadRequest("invalid request json format", apperror.CodeInvalidRequestJSONFormat))
+			return
+		}
+	}
+
+	userID, ok := middleware.RequireUserID(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.reportService.Dismiss(r.Context(), userID, reportID, req); err != nil {
+		response.ToJSON(w, r, err)
+		return
+	}
+
+	response.ToJSON(w, r, "Report has been dismissed!")
+}
+
+func (h *ReportHandler) TakeDown(w http.ResponseWriter, r *http.Request) {
+	reportID, appErr := parseReportID(r)
+	if appErr != nil {
+		response.ToJSON(w, r, appErr)
+		return
+	}
+
+	var req report.ReviewRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			response.ToJSON(w, r, apperror.BadRequest("invalid request json format", apperror.CodeInvalidRequestJSONFormat))
+			return
+		}
+	}
+
+	userID, ok := middleware.RequireUserID(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.reportService.TakeDown(r.Context(), userID, reportID, req); err != nil {
+		response.ToJSON(w, r, err)
+		return
+	}
+
+	response.ToJSON(w, r, "Content has been taken down!")
+}
@@ -0,0 +1,37 @@
+package report
+
+import (
+	"github.com/go-chi/chi/v5"
+	"github.com/jmoiron/sqlx"
+	"github.com/rxmy43/support-platform/internal/apperror"
+	"github.com/rxmy43/support-platform/internal/http/middleware"
+	"github.com/rxmy43/support-platform/internal/modules/report"
+	"github.com/rxmy43/support-platform/internal/modules/user"
+	"github.com/rxmy43/support-platform/internal/outbox"
+	"github.com/rxmy43/support-platform/internal/queue"
+	"github.com/rxmy43/support-platform/internal/socket"
+)
+
+func ReportRoutes(r chi.Router, db *sqlx.DB, hub *socket.Hub, jobs *queue.Registry, events *outbox.Dispatcher) {
+	reportRepo := report.NewReportRepo(db)
+	userRepo := user.NewUserRepo(db)
+
+	reportService := report.NewReportService(reportRepo, userRepo, hub)
+	handler := NewReportHandler(reportService)
+
+	events.Subscribe(report.EventContentTakenDown, report.JobContentTakenDown)
+	jobs.Register(report.JobContentTakenDown, reportService.HandleContentTakenDown)
+
+	r.Route("/reports", func(r chi.Router) {
+		r.Use(middleware.UserContext)
+		r.Post("/", handler.Create)
+	})
+
+	r.Route("/admin/reports", func(r chi.Router) {
+		r.Use(middleware.UserContext)
+		r.Use(middleware.RequireRole("admin", apperror.CodeAdminAccessRequired))
+		r.Get("/", handler.FindAll)
+		r.Post("/{reportID}/dismiss", handler.Dismiss)
+		r.Post("/{reportID}/takedown", handler.TakeDown)
+	})
+}
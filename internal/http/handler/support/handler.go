@@ -1,19 +1,18 @@
 package support
 
 import (
-	"bytes"
 	"encoding/json"
-	"fmt"
-	"io"
 	"log"
 	"net/http"
 	"strconv"
 
-	"github.com/gorilla/schema"
+	"github.com/go-chi/chi/v5"
+
 	"github.com/rxmy43/support-platform/internal/apperror"
 	"github.com/rxmy43/support-platform/internal/http/middleware"
 	"github.com/rxmy43/support-platform/internal/http/response"
 	"github.com/rxmy43/support-platform/internal/modules/support"
+	"github.com/rxmy43/support-platform/internal/pagination"
 )
 
 type SupportHandler struct {
@@ -33,66 +32,130 @@ func (h *SupportHandler) Donate(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	paymentURL, err := h.supportService.Donate(r.Context(), req)
+	donation, err := h.supportService.Donate(r.Context(), req)
 	if err != nil {
 		response.ToJSON(w, r, err)
 		return
 	}
 
-	response.ToJSON(w, r, map[string]string{"payment_url": paymentURL})
+	response.ToJSON(w, r, donation)
 }
 
-var decoder = schema.NewDecoder()
+func (h *SupportHandler) GetSupportSettings(w http.ResponseWriter, r *http.Request) {
+	creatorID, err := strconv.ParseUint(chi.URLParam(r, "creatorID"), 10, 64)
+	if err != nil {
+		response.ToJSON(w, r, apperror.BadRequest("invalid creator id", apperror.CodeFieldInvalidFormat))
+		return
+	}
+
+	settings, appErr := h.supportService.GetSupportSettings(r.Context(), uint(creatorID))
+	if appErr != nil {
+		response.ToJSON(w, r, appErr)
+		return
+	}
 
-func init() {
-	decoder.IgnoreUnknownKeys(true)
+	response.ToJSON(w, r, settings)
 }
 
-func (h *SupportHandler) PaymentCallback(w http.ResponseWriter, r *http.Request) {
-	bodyBytes, err := io.ReadAll(r.Body)
+func (h *SupportHandler) UpdateSupportSettings(w http.ResponseWriter, r *http.Request) {
+	var req support.SupportSettingsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.ToJSON(w, r, apperror.BadRequest("invalid request json format", apperror.CodeInvalidRequestJSONFormat))
+		return
+	}
+
+	userID := middleware.GetUserID(r.Context())
+	if userID == nil {
+		response.ToJSON(w, r, apperror.Unauthorized("invalid user id", apperror.CodeUnauthorizedOperation))
+		return
+	}
+
+	settings, appErr := h.supportService.UpdateSupportSettings(r.Context(), *userID, req)
+	if appErr != nil {
+		response.ToJSON(w, r, appErr)
+		return
+	}
+
+	response.ToJSON(w, r, settings)
+}
+
+func (h *SupportHandler) GetStatus(w http.ResponseWriter, r *http.Request) {
+	supportID, err := strconv.ParseUint(chi.URLParam(r, "supportID"), 10, 64)
 	if err != nil {
-		fmt.Println("Failed to read request body:", err)
-		response.ToJSON(w, r, apperror.BadRequest("cannot read body", apperror.CodeInvalidRequestJSONFormat))
+		response.ToJSON(w, r, apperror.BadRequest("invalid support id", apperror.CodeFieldInvalidFormat))
 		return
 	}
 
-	r.Body = io.NopCloser(bytes.NewBuffer(bodyBytes))
+	refresh := r.URL.Query().Get("refresh") == "true"
 
-	if err := r.ParseForm(); err != nil {
-		log.Println("ParseForm error:", err)
-		response.ToJSON(w, r, apperror.BadRequest("invalid form payload", apperror.CodeInvalidRequestJSONFormat))
+	support, appErr := h.supportService.GetStatus(r.Context(), uint(supportID), middleware.GetUserID(r.Context()), refresh)
+	if appErr != nil {
+		response.ToJSON(w, r, appErr)
 		return
 	}
 
-	var pc support.PaymentCallbackRequest
-	if err := decoder.Decode(&pc, r.PostForm); err != nil {
+	response.ToJSON(w, r, support)
+}
+
+// setMessageHidden builds the creator-only endpoints hiding and showing the
+// message of a support.
+func (h *SupportHandler) setMessageHidden(hidden bool, message string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		supportID, err := strconv.ParseUint(chi.URLParam(r, "supportID"), 10, 64)
+		if err != nil {
+			response.ToJSON(w, r, apperror.BadRequest("invalid support id", apperror.CodeFieldInvalidFormat))
+			return
+		}
+
+		if userRole := middleware.GetUserRole(r.Context()); userRole != "creator" {
+			response.ToJSON(w, r, apperror.Forbidden("only creator allowed to moderate support messages", apperror.CodeUnauthorizedOperation))
+			return
+		}
+
+		userID := middleware.GetUserID(r.Context())
+		if userID == nil {
+			response.ToJSON(w, r, apperror.Unauthorized("invalid user id", apperror.CodeUnauthorizedOperation))
+			return
+		}
+
+		if appErr := h.supportService.SetMessageHidden(r.Context(), uint(supportID), *userID, hidden); appErr != nil {
+			response.ToJSON(w, r, appErr)
+			return
+		}
+
+		response.ToJSON(w, r, message)
+	}
+}
+
+func (h *SupportHandler) HideMessage() http.HandlerFunc {
+	return h.setMessageHidden(true, "Support message has been hidden!")
+}
+
+func (h *SupportHandler) UnhideMessage() http.HandlerFunc {
+	return h.setMessageHidden(false, "Support message is visible again!")
+}
+
+func (h *SupportHandler) PaymentCallback(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
 		log.Println("ParseForm error:", err)
-		response.ToJSON(w, r, apperror.BadRequest("invalid form payload", apperror.CodeUnknown))
+		response.ToJSON(w, r, apperror.BadRequest("invalid form payload", apperror.CodeInvalidRequestFormFormat))
 		return
 	}
 
-	status, appErr := h.supportService.PaymentCallback(r.Context(), pc)
+	status, appErr := h.supportService.PaymentCallback(r.Context(), r.PostForm)
 	if appErr != nil {
-		response.ToJSON(w, r, err)
+		response.ToJSON(w, r, appErr)
 		return
 	}
 
 	response.ToJSON(w, r, status)
-
 }
 
 func (h *SupportHandler) GetBestSupporters(w http.ResponseWriter, r *http.Request) {
-	cursorStr := r.URL.Query().Get("cursor")
-	var cursor *uint
-
-	if cursorStr != "" {
-		parsed, err := strconv.ParseUint(cursorStr, 10, 64)
-		if err != nil {
-			response.ToJSON(w, r, apperror.BadRequest("invalid cursor", apperror.CodeUnknown))
-			return
-		}
-		temp := uint(parsed)
-		cursor = &temp
+	params, appErr := pagination.FromRequest(r)
+	if appErr != nil {
+		response.ToJSON(w, r, appErr)
+		return
 	}
 
 	userID := middleware.GetUserID(r.Context())
@@ -103,24 +166,13 @@ func (h *SupportHandler) GetBestSupporters(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	bestSupports, nextCursor, err := h.supportService.GetSupporters(r.Context(), cursor, *userID)
+	page, err := h.supportService.GetSupporters(r.Context(), params, *userID)
 	if err != nil {
 		response.ToJSON(w, r, err)
 		return
 	}
 
-	data := make([]any, len(bestSupports))
-	for i, b := range bestSupports {
-		data[i] = b
-	}
-
-	resp := response.SuccessPaginateResponse{
-		Status:     response.StatusSuccess,
-		Data:       data,
-		NextCursor: nextCursor,
-	}
-
-	response.ToJSON(w, r, resp)
+	response.ToJSON(w, r, response.Paginate(page, params.Limit))
 }
 
 func (h *SupportHandler) GetFanSpending(w http.ResponseWriter, r *http.Request) {
@@ -142,18 +194,10 @@ func (h *SupportHandler) GetFanSpending(w http.ResponseWriter, r *http.Request)
 }
 
 func (h *SupportHandler) GetFanSpendingHistory(w http.ResponseWriter, r *http.Request) {
-	cursorStr := r.URL.Query().Get("cursor")
-	var cursor *uint
-
-	if cursorStr != "" {
-		parsed, err := strconv.ParseUint(cursorStr, 10, 64)
-		if err != nil {
-			response.ToJSON(w, r, apperror.BadRequest("invalid cursor", apperror.CodeUnknown))
-			return
-		}
-
-		temp := uint(parsed)
-		cursor = &temp
+	params, appErr := pagination.FromRequest(r)
+	if appErr != nil {
+		response.ToJSON(w, r, appErr)
+		return
 	}
 
 	var userID *uint
@@ -161,22 +205,27 @@ func (h *SupportHandler) GetFanSpendingHistory(w http.ResponseWriter, r *http.Re
 		userID = middleware.GetUserID(r.Context())
 	}
 
-	histories, nextCursor, err := h.supportService.GetFanSupportHistory(r.Context(), cursor, userID)
+	page, err := h.supportService.GetFanSupportHistory(r.Context(), params, userID)
 	if err != nil {
 		response.ToJSON(w, r, err)
 		return
 	}
 
-	data := make([]any, len(histories))
-	for i, h := range histories {
-		data[i] = h
+	response.ToJSON(w, r, response.Paginate(page, params.Limit))
+}
+
+func (h *SupportHandler) FindAnomalies(w http.ResponseWriter, r *http.Request) {
+	params, appErr := pagination.FromRequest(r)
+	if appErr != nil {
+		response.ToJSON(w, r, appErr)
+		return
 	}
 
-	resp := response.SuccessPaginateResponse{
-		Status:     response.StatusSuccess,
-		Data:       data,
-		NextCursor: nextCursor,
+	page, appErr := h.supportService.FindAnomalies(r.Context(), params)
+	if appErr != nil {
+		response.ToJSON(w, r, appErr)
+		return
 	}
 
-	response.ToJSON(w, r, resp)
+	response.ToJSON(w, r, response.Paginate(page, params.Limit))
 }
@@ -3,20 +3,29 @@ package support
 import (
 	"github.com/go-chi/chi/v5"
 	"github.com/jmoiron/sqlx"
+	"github.com/rxmy43/support-platform/internal/apperror"
+	"github.com/rxmy43/support-platform/internal/config"
 	"github.com/rxmy43/support-platform/internal/http/middleware"
+	"github.com/rxmy43/support-platform/internal/moderation"
 	"github.com/rxmy43/support-platform/internal/modules/balance"
 	"github.com/rxmy43/support-platform/internal/modules/support"
 	"github.com/rxmy43/support-platform/internal/modules/user"
+	"github.com/rxmy43/support-platform/internal/outbox"
+	"github.com/rxmy43/support-platform/internal/payment"
+	"github.com/rxmy43/support-platform/internal/queue"
 	"github.com/rxmy43/support-platform/internal/socket"
 )
 
-func SupportRoutes(r chi.Router, db *sqlx.DB, hub *socket.Hub) {
+func SupportRoutes(r chi.Router, db *sqlx.DB, hub *socket.Hub, cfg *config.Config, gateway payment.PaymentGateway, jobs *queue.Registry, events *outbox.Dispatcher) {
 	supportRepo := support.NewSupportRepo(db)
 	userRepo := user.NewUserRepo(db)
 	balanceRepo := balance.NewBalanceRepo(db)
-	supportService := support.NewSupportService(supportRepo, userRepo, balanceRepo, hub)
+	supportService := support.NewSupportService(supportRepo, userRepo, balanceRepo, hub, gateway, moderation.NewBlocklist(cfg.Moderation.Blocklist), cfg.Support, cfg.AppURL)
 	handler := NewSupportHandler(supportService)
 
+	events.Subscribe(support.EventSupportPaid, support.JobSupportReceived)
+	jobs.Register(support.JobSupportReceived, supportService.HandleSupportReceived)
+
 	r.Post("/payment/callback", handler.PaymentCallback)
 
 	r.Route("/supports", func(r chi.Router) {
@@ -25,5 +34,16 @@ func SupportRoutes(r chi.Router, db *sqlx.DB, hub *socket.Hub) {
 		r.Get("/best", handler.GetBestSupporters)
 		r.Get("/fan-spending", handler.GetFanSpending)
 		r.Get("/fan-spending/history", handler.GetFanSpendingHistory)
+		r.Get("/creators/{creatorID}/settings", handler.GetSupportSettings)
+		r.With(middleware.RequireRole("creator", apperror.CodeUnauthorizedOperation)).Put("/settings", handler.UpdateSupportSettings)
+		r.Get("/{supportID}", handler.GetStatus)
+		r.Post("/{supportID}/hide", handler.HideMessage())
+		r.Delete("/{supportID}/hide", handler.UnhideMessage())
+	})
+
+	r.Route("/admin/payment-anomalies", func(r chi.Router) {
+		r.Use(middleware.UserContext)
+		r.Use(middleware.RequireAdmin(user.NewUserService(userRepo)))
+		r.Get("/", handler.FindAnomalies)
 	})
 }
@@ -0,0 +1,57 @@
+package user
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/rxmy43/support-platform/internal/apperror"
+	"github.com/rxmy43/support-platform/internal/http/middleware"
+	"github.com/rxmy43/support-platform/internal/http/response"
+	"github.com/rxmy43/support-platform/internal/modules/user"
+)
+
+type UserHandler struct {
+	userService *user.UserService
+}
+
+func NewUserHandler(userService *user.UserService) *UserHandler {
+	return &UserHandler{
+		userService: userService,
+	}
+}
+
+func (h *UserHandler) GetMe(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.RequireUserID(w, r)
+	if !ok {
+		return
+	}
+
+	me, appErr := h.userService.GetMe(r.Context(), userID)
+	if appErr != nil {
+		response.ToJSON(w, r, appErr)
+		return
+	}
+
+	response.ToJSON(w, r, me)
+}
+
+func (h *UserHandler) UpdateHandle(w http.ResponseWriter, r *http.Request) {
+	var req user.HandleUpdateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.ToJSON(w, r, apperror.BadRequest("invalid request json format", apperror.CodeInvalidRequestJSONFormat))
+		return
+	}
+
+	userID, ok := middleware.RequireUserID(w, r)
+	if !ok {
+		return
+	}
+
+	updated, appErr := h.userService.UpdateHandle(r.Context(), userID, req)
+	if appErr != nil {
+		response.ToJSON(w, r, appErr)
+		return
+	}
+
+	response.ToJSON(w, r, updated)
+}
@@ -0,0 +1,20 @@
+package user
+
+import (
+	"github.com/go-chi/chi/v5"
+	"github.com/jmoiron/sqlx"
+	"github.com/rxmy43/support-platform/internal/http/middleware"
+	"github.com/rxmy43/support-platform/internal/modules/user"
+)
+
+func UserRoutes(r chi.Router, db *sqlx.DB) {
+	userRepo := user.NewUserRepo(db)
+	userService := user.NewUserService(userRepo)
+	handler := NewUserHandler(userService)
+
+	r.Route("/users/me", func(r chi.Router) {
+		r.Use(middleware.UserContext)
+		r.Get("/", handler.GetMe)
+		r.Put("/handle", handler.UpdateHandle)
+	})
+}
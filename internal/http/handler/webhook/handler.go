@@ -0,0 +1,79 @@
+package webhook
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rxmy43/support-platform/internal/apperror"
+	"github.com/rxmy43/support-platform/internal/http/middleware"
+	"github.com/rxmy43/support-platform/internal/http/response"
+	"github.com/rxmy43/support-platform/internal/modules/webhook"
+)
+
+type WebhookHandler struct {
+	webhookService *webhook.WebhookService
+}
+
+func NewWebhookHandler(webhookService *webhook.WebhookService) *WebhookHandler {
+	return &WebhookHandler{
+		webhookService: webhookService,
+	}
+}
+
+func (h *WebhookHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req webhook.WebhookCreateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.ToJSON(w, r, apperror.BadRequest("invalid request json format", apperror.CodeInvalidRequestJSONFormat))
+		return
+	}
+
+	userID, ok := middleware.RequireUserID(w, r)
+	if !ok {
+		return
+	}
+
+	created, appErr := h.webhookService.Create(r.Context(), userID, req)
+	if appErr != nil {
+		response.ToJSON(w, r, appErr)
+		return
+	}
+
+	response.ToJSON(w, r, created)
+}
+
+func (h *WebhookHandler) FindAll(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.RequireUserID(w, r)
+	if !ok {
+		return
+	}
+
+	webhooks, appErr := h.webhookService.FindAll(r.Context(), userID)
+	if appErr != nil {
+		response.ToJSON(w, r, appErr)
+		return
+	}
+
+	response.ToJSON(w, r, webhooks)
+}
+
+func (h *WebhookHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	webhookID, err := strconv.ParseUint(chi.URLParam(r, "webhookID"), 10, 64)
+	if err != nil {
+		response.ToJSON(w, r, apperror.BadRequest("invalid webhook id", apperror.CodeFieldInvalidFormat))
+		return
+	}
+
+	userID, ok := middleware.RequireUserID(w, r)
+	if !ok {
+		return
+	}
+
+	if appErr := h.webhookService.Delete(r.Context(), uint(webhookID), userID); appErr != nil {
+		response.ToJSON(w, r, appErr)
+		return
+	}
+
+	response.ToJSON(w, r, "Webhook has been deleted!")
+}
@@ -0,0 +1,31 @@
+package webhook
+
+import (
+	"github.com/go-chi/chi/v5"
+	"github.com/jmoiron/sqlx"
+	"github.com/rxmy43/support-platform/internal/apperror"
+	"github.com/rxmy43/support-platform/internal/config"
+	"github.com/rxmy43/support-platform/internal/http/middleware"
+	"github.com/rxmy43/support-platform/internal/modules/support"
+	"github.com/rxmy43/support-platform/internal/modules/webhook"
+	"github.com/rxmy43/support-platform/internal/outbox"
+	"github.com/rxmy43/support-platform/internal/queue"
+)
+
+func WebhookRoutes(r chi.Router, db *sqlx.DB, cfg *config.Config, jobs *queue.Registry, events *outbox.Dispatcher) {
+	webhookRepo := webhook.NewWebhookRepo(db)
+	webhookService := webhook.NewWebhookService(webhookRepo, cfg.Env == "development")
+	handler := NewWebhookHandler(webhookService)
+
+	events.Subscribe(support.EventSupportPaid, webhook.JobDispatch)
+	jobs.Register(webhook.JobDispatch, webhookService.HandleDispatch)
+	jobs.Register(webhook.JobDeliver, webhookService.HandleDeliver)
+
+	r.Route("/webhooks", func(r chi.Router) {
+		r.Use(middleware.UserContext)
+		r.Use(middleware.RequireRole("creator", apperror.CodeUnauthorizedOperation))
+		r.Get("/", handler.FindAll)
+		r.Post("/", handler.Create)
+		r.Delete("/{webhookID}", handler.Delete)
+	})
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/rxmy43/support-platform/internal/apperror"
 	"github.com/rxmy43/support-platform/internal/http/response"
+	"github.com/rxmy43/support-platform/internal/modules/user"
 )
 
 type ctxKey string
@@ -51,9 +52,56 @@ func GetUserID(ctx context.Context) *uint {
 	return nil
 }
 
+// RequireUserID returns the user id of the request. When it is missing or
+// invalid it responds with 401 and returns false.
+func RequireUserID(w http.ResponseWriter, r *http.Request) (uint, bool) {
+	userID := GetUserID(r.Context())
+	if userID == nil {
+		response.ToJSON(w, r, apperror.Unauthorized("invalid user id", apperror.CodeUnauthorizedOperation))
+		return 0, false
+	}
+	return *userID, true
+}
+
 func GetUserRole(ctx context.Context) string {
 	if val, ok := ctx.Value(userRoleKey).(string); ok {
 		return val
 	}
 	return ""
 }
+
+// RequireRole rejects requests whose X-User-Role is not the given role. It
+// must run after UserContext.
+func RequireRole(role string, code apperror.ErrorCode) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if GetUserRole(r.Context()) != role {
+				response.ToJSON(w, r, apperror.Forbidden("only "+role+" allowed to access this resource", code))
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RequireAdmin rejects requests of users who are not admins in the database.
+// Unlike RequireRole it does not trust X-User-Role. It must run after
+// UserContext.
+func RequireAdmin(userService *user.UserService) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			userID, ok := RequireUserID(w, r)
+			if !ok {
+				return
+			}
+
+			if appErr := userService.CheckAdmin(r.Context(), userID); appErr != nil {
+				response.ToJSON(w, r, appErr)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
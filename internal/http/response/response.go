@@ -7,6 +7,7 @@ import (
 
 	"github.com/rxmy43/support-platform/internal/apperror"
 	"github.com/rxmy43/support-platform/internal/config"
+	"github.com/rxmy43/support-platform/internal/pagination"
 )
 
 type PaginationMeta struct {
@@ -32,7 +33,25 @@ type SuccessResponse struct {
 type SuccessPaginateResponse struct {
 	Status     ResponseStatus `json:"status"`
 	Data       []any          `json:"data"`
-	NextCursor *uint          `json:"next_cursor"`
+	NextCursor *string        `json:"next_cursor"`
+	HasMore    bool           `json:"has_more"`
+	Limit      int            `json:"limit"`
+}
+
+// Paginate wraps one page of a list endpoint into a SuccessPaginateResponse.
+func Paginate[T any](page pagination.Page[T], limit int) SuccessPaginateResponse {
+	data := make([]any, len(page.Items))
+	for i, item := range page.Items {
+		data[i] = item
+	}
+
+	return SuccessPaginateResponse{
+		Status:     StatusSuccess,
+		Data:       data,
+		NextCursor: page.NextCursor,
+		HasMore:    page.HasMore,
+		Limit:      limit,
+	}
 }
 
 type ErrorResponse struct {
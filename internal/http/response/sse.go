@@ -0,0 +1,67 @@
+package response
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/rxmy43/support-platform/internal/apperror"
+)
+
+// EventStream writes Server-Sent Events to a response.
+type EventStream struct {
+	w  http.ResponseWriter
+	rc *http.ResponseController
+}
+
+// NewEventStream sends the event stream headers. The server write timeout is
+// lifted for this response since a stream outlives a normal request.
+func NewEventStream(w http.ResponseWriter) (*EventStream, error) {
+	rc := http.NewResponseController(w)
+	if err := rc.SetWriteDeadline(time.Time{}); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		return nil, err
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	if err := rc.Flush(); err != nil {
+		return nil, err
+	}
+
+	return &EventStream{w: w, rc: rc}, nil
+}
+
+// Send writes one event with data encoded as JSON and flushes it to the client.
+func (s *EventStream) Send(event string, data any) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	if _, err := fmt.Fprintf(s.w, "event: %s\ndata: %s\n\n", event, payload); err != nil {
+		return err
+	}
+
+	return s.rc.Flush()
+}
+
+// SendError writes an "error" event with the public code and message only.
+// Like ToJSON, a server error carrying a cause is answered generically.
+func (s *EventStream) SendError(err *apperror.AppError) error {
+	resp := ErrorResponse{
+		Status:  StatusError,
+		Code:    err.Code,
+		Message: err.Message,
+	}
+	if err.HTTPStatus() >= 500 && err.Error() != err.Message {
+		resp.Message = "Internal Server Error, please contact support."
+	}
+
+	return s.Send("error", resp)
+}
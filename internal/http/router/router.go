@@ -1,20 +1,34 @@
 package router
 
 import (
+	"log"
 	"net/http"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/cors"
 	"github.com/jmoiron/sqlx"
+	"github.com/rxmy43/support-platform/internal/config"
 	"github.com/rxmy43/support-platform/internal/http/handler/auth"
 	"github.com/rxmy43/support-platform/internal/http/handler/balance"
+	"github.com/rxmy43/support-platform/internal/http/handler/campaign"
+	"github.com/rxmy43/support-platform/internal/http/handler/comment"
+	"github.com/rxmy43/support-platform/internal/http/handler/feed"
+	"github.com/rxmy43/support-platform/internal/http/handler/notification"
 	"github.com/rxmy43/support-platform/internal/http/handler/post"
+	"github.com/rxmy43/support-platform/internal/http/handler/report"
 	"github.com/rxmy43/support-platform/internal/http/handler/support"
+	"github.com/rxmy43/support-platform/internal/http/handler/user"
+	"github.com/rxmy43/support-platform/internal/http/handler/webhook"
+	"github.com/rxmy43/support-platform/internal/outbox"
+	"github.com/rxmy43/support-platform/internal/payment"
+	"github.com/rxmy43/support-platform/internal/queue"
 	"github.com/rxmy43/support-platform/internal/socket"
 )
 
-func NewRouter(db *sqlx.DB, hub *socket.Hub) http.Handler {
+// NewRouter builds the API and registers the queue handlers of its modules
+// on jobs, and their outbox event subscriptions on events.
+func NewRouter(cfg *config.Config, db *sqlx.DB, hub *socket.Hub, jobs *queue.Registry, events *outbox.Dispatcher) http.Handler {
 	r := chi.NewRouter()
 
 	r.Use(middleware.Logger)
@@ -33,17 +47,35 @@ func NewRouter(db *sqlx.DB, hub *socket.Hub) http.Handler {
 
 	r.Get("/ws", hub.WsHandler)
 
+	gateway, err := payment.NewFromConfig(cfg)
+	if err != nil {
+		log.Fatal("payment gateway setup failed ", err)
+	}
+
+	// the fake gateway serves its own payment page
+	if fake, ok := gateway.(*payment.Fake); ok && payment.FakeAllowed(cfg.Env) {
+		r.Handle(payment.FakePathPrefix+"/*", fake)
+	}
+
 	r.Get("/ping", func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(200)
 		w.Write([]byte("pong"))
 	})
 
+	feed.FeedRoutes(r, db, cfg)
+
 	/* List endpoints */
 	r.Route("/api", func(r chi.Router) {
 		auth.AuthRoutes(r, db)
-		post.PostRoutes(r, db)
-		support.SupportRoutes(r, db, hub)
+		user.UserRoutes(r, db)
+		post.PostRoutes(r, db, cfg)
+		support.SupportRoutes(r, db, hub, cfg, gateway, jobs, events)
 		balance.BalanceRoutes(r, db)
+		comment.CommentRoutes(r, db, hub)
+		report.ReportRoutes(r, db, hub, jobs, events)
+		notification.NotificationRoutes(r, db, jobs, events)
+		webhook.WebhookRoutes(r, db, cfg, jobs, events)
+		campaign.CampaignRoutes(r, db, hub, jobs, events)
 	})
 
 	return r
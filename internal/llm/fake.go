@@ -0,0 +1,82 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"strings"
+)
+
+var fakeCaptions = []string{
+	"Thank you for being here and supporting every step of this journey. Your kindness keeps me creating!",
+	"Made this one with a lot of love. If it made your day a little better, a small support means the world.",
+	"Behind every post is a late night and a cup of coffee. Thanks for keeping me going!",
+	"New work just dropped! Your support helps me keep sharing more like this.",
+}
+
+const fakeImageDescription = "A bright, well lit photo shared by the creator."
+
+// fakeVerdict answers JSON requests, the moderation classifier being the only
+// caller asking for JSON. The fake allows everything, the blocklist still
+// runs in front of it.
+const fakeVerdict = `{"decision":"allow","reason":"fake provider allows all content"}`
+
+// Fake is a deterministic Client for local development and tests. The same
+// request always gets the same answer.
+type Fake struct{}
+
+func NewFake() *Fake {
+	return &Fake{}
+}
+
+func (f *Fake) Chat(ctx context.Context, req ChatRequest) (Completion, error) {
+	if err := ctx.Err(); err != nil {
+		return Completion{}, err
+	}
+
+	answer := fakeImageDescription
+	if req.JSON {
+		answer = fakeVerdict
+	} else if !req.Vision {
+		h := fnv.New32a()
+		for _, m := range req.Messages {
+			fmt.Fprintf(h, "%s:%s\n", m.Role, m.Content)
+		}
+		answer = fakeCaptions[h.Sum32()%uint32(len(fakeCaptions))]
+	}
+
+	return Completion{Content: answer, Usage: fakeUsage(req, answer)}, nil
+}
+
+// fakeUsage counts words as tokens, close enough for local quota testing.
+func fakeUsage(req ChatRequest, answer string) Usage {
+	var prompt int
+	for _, m := range req.Messages {
+		prompt += len(strings.Fields(m.Content))
+	}
+
+	return Usage{PromptTokens: prompt, CompletionTokens: len(strings.Fields(answer))}
+}
+
+// ChatStream sends the Chat answer word by word.
+func (f *Fake) ChatStream(ctx context.Context, req ChatRequest, onDelta func(delta string) error) (Usage, error) {
+	completion, err := f.Chat(ctx, req)
+	if err != nil {
+		return Usage{}, err
+	}
+
+	for i, word := range strings.Fields(completion.Content) {
+		if err := ctx.Err(); err != nil {
+			return completion.Usage, err
+		}
+
+		if i > 0 {
+			word = " " + word
+		}
+		if err := onDelta(word); err != nil {
+			return completion.Usage, err
+		}
+	}
+
+	return completion.Usage, nil
+}
@@ -0,0 +1,80 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/rxmy43/support-platform/internal/config"
+)
+
+type Message struct {
+	Role    string
+	Content string
+	// ImageURLs are sent alongside the text for vision capable models.
+	ImageURLs []string
+}
+
+type ChatRequest struct {
+	Messages    []Message
+	Temperature float64
+	MaxTokens   int
+	// Vision routes the request to the configured vision model.
+	Vision bool
+	// JSON asks for an answer that is a single JSON object.
+	JSON bool
+}
+
+// Usage is the token count a provider reports for one call.
+type Usage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+}
+
+func (u *Usage) Add(other Usage) {
+	u.PromptTokens += other.PromptTokens
+	u.CompletionTokens += other.CompletionTokens
+}
+
+type Completion struct {
+	// Content is the text of the first answer, trimmed.
+	Content string
+	Usage   Usage
+}
+
+// Client talks to a chat completion model.
+type Client interface {
+	Chat(ctx context.Context, req ChatRequest) (Completion, error)
+	// ChatStream calls onDelta with each piece of the answer as the model
+	// produces it. Returning an error from onDelta stops the stream.
+	ChatStream(ctx context.Context, req ChatRequest, onDelta func(delta string) error) (Usage, error)
+}
+
+// APIError is returned when the provider answers with a non 200 status.
+type APIError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("llm api error (http %d): %s", e.StatusCode, e.Body)
+}
+
+const (
+	ProviderOpenAI = "openai"
+	ProviderFake   = "fake"
+)
+
+// NewFromConfig returns the client selected by LLM_PROVIDER. Any OpenAI
+// compatible API (Groq, OpenAI, a local server) goes through the "openai"
+// provider, "fake" answers locally without network access.
+func NewFromConfig(cfg config.LLMConfig) (Client, error) {
+	switch cfg.Provider {
+	case ProviderOpenAI, "":
+		return NewOpenAIClient(cfg.BaseURL, cfg.APIKey, cfg.Model, cfg.VisionModel, time.Duration(cfg.TimeoutSeconds)*time.Second), nil
+	case ProviderFake:
+		return NewFake(), nil
+	}
+
+	return nil, fmt.Errorf("unknown llm provider %q", cfg.Provider)
+}
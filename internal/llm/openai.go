@@ -0,0 +1,249 @@
+package llm
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// OpenAIClient calls any API implementing the OpenAI chat completions endpoint.
+type OpenAIClient struct {
+	baseURL     string
+	apiKey      string
+	model       string
+	visionModel string
+	httpClient  *http.Client
+	// streamClient has no overall timeout since a stream legitimately takes
+	// longer than a single answer, the caller's context bounds it instead.
+	streamClient *http.Client
+}
+
+func NewOpenAIClient(baseURL, apiKey, model, visionModel string, timeout time.Duration) *OpenAIClient {
+	return &OpenAIClient{
+		baseURL:     strings.TrimRight(baseURL, "/"),
+		apiKey:      apiKey,
+		model:       model,
+		visionModel: visionModel,
+		httpClient:  &http.Client{Timeout: timeout},
+		streamClient: &http.Client{
+			Transport: &http.Transport{
+				Proxy:                 http.ProxyFromEnvironment,
+				DialContext:           (&net.Dialer{Timeout: timeout}).DialContext,
+				TLSHandshakeTimeout:   timeout,
+				ResponseHeaderTimeout: timeout,
+			},
+		},
+	}
+}
+
+type chatCompletionRequest struct {
+	Model       string        `json:"model"`
+	Messages    []chatMessage `json:"messages"`
+	Temperature float64       `json:"temperature"`
+	MaxTokens   int           `json:"max_tokens,omitempty"`
+	Stream      bool          `json:"stream,omitempty"`
+	// StreamOptions asks for the usage in the last chunk of a stream.
+	StreamOptions  *streamOptions  `json:"stream_options,omitempty"`
+	ResponseFormat *responseFormat `json:"response_format,omitempty"`
+}
+
+type responseFormat struct {
+	Type string `json:"type"`
+}
+
+type streamOptions struct {
+	IncludeUsage bool `json:"include_usage"`
+}
+
+// chatMessage is the wire format of a message. Content is a plain string, or
+// a list of text and image parts when the message carries images.
+type chatMessage struct {
+	Role    string `json:"role"`
+	Content any    `json:"content"`
+}
+
+type contentPart struct {
+	Type     string    `json:"type"`
+	Text     string    `json:"text,omitempty"`
+	ImageURL *imageURL `json:"image_url,omitempty"`
+}
+
+type imageURL struct {
+	URL string `json:"url"`
+}
+
+func toChatMessages(messages []Message) []chatMessage {
+	out := make([]chatMessage, 0, len(messages))
+
+	for _, m := range messages {
+		if len(m.ImageURLs) == 0 {
+			out = append(out, chatMessage{Role: m.Role, Content: m.Content})
+			continue
+		}
+
+		parts := []contentPart{{Type: "text", Text: m.Content}}
+		for _, u := range m.ImageURLs {
+			parts = append(parts, contentPart{Type: "image_url", ImageURL: &imageURL{URL: u}})
+		}
+		out = append(out, chatMessage{Role: m.Role, Content: parts})
+	}
+
+	return out
+}
+
+func (c *OpenAIClient) newRequest(ctx context.Context, req ChatRequest, stream bool) (*http.Request, error) {
+	model := c.model
+	if req.Vision {
+		model = c.visionModel
+	}
+
+	payload := chatCompletionRequest{
+		Model:       model,
+		Messages:    toChatMessages(req.Messages),
+		Temperature: req.Temperature,
+		MaxTokens:   req.MaxTokens,
+		Stream:      stream,
+	}
+	if stream {
+		payload.StreamOptions = &streamOptions{IncludeUsage: true}
+	}
+	if req.JSON {
+		payload.ResponseFormat = &responseFormat{Type: "json_object"}
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/chat/completions", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Authorization", "Bearer "+c.apiKey)
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	return httpReq, nil
+}
+
+func (c *OpenAIClient) Chat(ctx context.Context, req ChatRequest) (Completion, error) {
+	httpReq, err := c.newRequest(ctx, req, false)
+	if err != nil {
+		return Completion{}, err
+	}
+
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return Completion{}, err
+	}
+	defer resp.Body.Close()
+
+	resBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return Completion{}, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return Completion{}, &APIError{StatusCode: resp.StatusCode, Body: string(resBody)}
+	}
+
+	var result struct {
+		Choices []struct {
+			Message struct {
+				Content string `json:"content"`
+			} `json:"message"`
+		} `json:"choices"`
+		Usage Usage `json:"usage"`
+	}
+
+	if err := json.Unmarshal(resBody, &result); err != nil {
+		return Completion{}, err
+	}
+
+	if len(result.Choices) == 0 {
+		return Completion{}, errors.New("llm returned no choices")
+	}
+
+	return Completion{
+		Content: strings.TrimSpace(result.Choices[0].Message.Content),
+		Usage:   result.Usage,
+	}, nil
+}
+
+func (c *OpenAIClient) ChatStream(ctx context.Context, req ChatRequest, onDelta func(delta string) error) (Usage, error) {
+	var usage Usage
+
+	httpReq, err := c.newRequest(ctx, req, true)
+	if err != nil {
+		return usage, err
+	}
+	httpReq.Header.Set("Accept", "text/event-stream")
+
+	resp, err := c.streamClient.Do(httpReq)
+	if err != nil {
+		return usage, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		resBody, _ := io.ReadAll(resp.Body)
+		return usage, &APIError{StatusCode: resp.StatusCode, Body: string(resBody)}
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		data, ok := strings.CutPrefix(scanner.Text(), "data:")
+		if !ok {
+			continue
+		}
+
+		data = strings.TrimSpace(data)
+		if data == "[DONE]" {
+			return usage, nil
+		}
+
+		var chunk struct {
+			Choices []struct {
+				Delta struct {
+					Content string `json:"content"`
+				} `json:"delta"`
+			} `json:"choices"`
+			Usage *Usage `json:"usage"`
+			// Groq reports the usage of a stream here instead
+			XGroq *struct {
+				Usage *Usage `json:"usage"`
+			} `json:"x_groq"`
+		}
+
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			return usage, err
+		}
+
+		if chunk.Usage != nil {
+			usage = *chunk.Usage
+		} else if chunk.XGroq != nil && chunk.XGroq.Usage != nil {
+			usage = *chunk.XGroq.Usage
+		}
+
+		if len(chunk.Choices) == 0 || chunk.Choices[0].Delta.Content == "" {
+			continue
+		}
+
+		if err := onDelta(chunk.Choices[0].Delta.Content); err != nil {
+			return usage, err
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return usage, err
+	}
+
+	return usage, errors.New("llm stream ended without [DONE]")
+}
@@ -0,0 +1,93 @@
+package moderation
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/rxmy43/support-platform/internal/apperror"
+)
+
+// defaultRejectPatterns are spam we never want published, mostly online
+// gambling promotion.
+var defaultRejectPatterns = []string{
+	`(?i)\bjudi\s*online\b`,
+	`(?i)\bslot\s*gacor\b`,
+	`(?i)\bsitus\s*(slot|togel|judi)\b`,
+	`(?i)\bdeposit\s*pulsa\b`,
+	`(?i)\bmaxwin\b`,
+}
+
+// defaultFlagPatterns are words that are fine in some contexts, posts using
+// them are reviewed by an admin first.
+var defaultFlagPatterns = []string{
+	`(?i)\b(anjing|bangsat|kontol|memek|goblok)\b`,
+	`(?i)\b(fuck|shit|bitch|cunt)\w*`,
+}
+
+// Blocklist is the built-in keyword/regex moderator.
+type Blocklist struct {
+	reject []*regexp.Regexp
+	flag   []*regexp.Regexp
+}
+
+// NewBlocklist compiles the default patterns plus the extra reject patterns
+// given, invalid extra patterns are ignored.
+func NewBlocklist(extraReject []string) *Blocklist {
+	b := &Blocklist{}
+
+	for _, p := range append(defaultRejectPatterns, extraReject...) {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			continue
+		}
+		if re, err := regexp.Compile(p); err == nil {
+			b.reject = append(b.reject, re)
+		}
+	}
+
+	for _, p := range defaultFlagPatterns {
+		b.flag = append(b.flag, regexp.MustCompile(p))
+	}
+
+	return b
+}
+
+func (b *Blocklist) Moderate(ctx context.Context, content []Content) (Result, error) {
+	res := Result{Decision: Allow}
+
+	for _, c := range content {
+		for _, re := range b.reject {
+			if re.MatchString(c.Text) {
+				res.Decision = Reject
+				res.Reason = "blocked keyword"
+				res.FieldErrors = append(res.FieldErrors, apperror.NewFieldError(c.Field, apperror.CodeContentRejected).WithMessage("contains blocked content"))
+				break
+			}
+		}
+
+		if res.Decision == Allow {
+			for _, re := range b.flag {
+				if re.MatchString(c.Text) {
+					res.Decision = Flag
+					res.Reason = "flagged keyword"
+					break
+				}
+			}
+		}
+	}
+
+	return res, nil
+}
+
+// Mask replaces the words matching the flag patterns with asterisks, for
+// short texts that are published right away, like support messages.
+func (b *Blocklist) Mask(text string) string {
+	for _, re := range b.flag {
+		text = re.ReplaceAllStringFunc(text, func(m string) string {
+			return strings.Repeat("*", utf8.RuneCountInString(m))
+		})
+	}
+	return text
+}
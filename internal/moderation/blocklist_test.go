@@ -0,0 +1,120 @@
+package moderation
+
+import (
+	"context"
+	"testing"
+)
+
+func TestBlocklistModerate(t *testing.T) {
+	b := NewBlocklist([]string{" (?i)\\bpinjol\\b ", "", "([unclosed"})
+
+	tests := []struct {
+		name     string
+		content  []Content
+		decision Decision
+		rejected []string
+	}{
+		{
+			name:     "clean text",
+			content:  []Content{{Field: "text", Text: "New song out tomorrow, thanks for the support!"}},
+			decision: Allow,
+		},
+		{
+			name:     "gambling spam",
+			content:  []Content{{Field: "text", Text: "Main di situs slot terpercaya"}},
+			decision: Reject,
+			rejected: []string{"text"},
+		},
+		{
+			name:     "spam in another case and spacing",
+			content:  []Content{{Field: "text", Text: "SLOT   GACOR hari ini"}},
+			decision: Reject,
+			rejected: []string{"text"},
+		},
+		{
+			name:     "extra pattern from config",
+			content:  []Content{{Field: "text", Text: "Butuh dana? Pinjol cepat cair"}},
+			decision: Reject,
+			rejected: []string{"text"},
+		},
+		{
+			name:     "keyword inside another word",
+			content:  []Content{{Field: "text", Text: "the maxwinner of the week"}},
+			decision: Allow,
+		},
+		{
+			name:     "swear word",
+			content:  []Content{{Field: "text", Text: "Dasar goblok"}},
+			decision: Flag,
+		},
+		{
+			name:     "swear word with a suffix",
+			content:  []Content{{Field: "text", Text: "this is fucking great"}},
+			decision: Flag,
+		},
+		{
+			name: "reject wins over an earlier flag",
+			content: []Content{
+				{Field: "text", Text: "shit happens"},
+				{Field: "poll_question", Text: "judi online?"},
+			},
+			decision: Reject,
+			rejected: []string{"poll_question"},
+		},
+		{
+			name: "every rejected field is reported",
+			content: []Content{
+				{Field: "text", Text: "deposit pulsa"},
+				{Field: "poll_options", Text: "maxwin"},
+			},
+			decision: Reject,
+			rejected: []string{"text", "poll_options"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := b.Moderate(context.Background(), tt.content)
+			if err != nil {
+				t.Fatalf("moderate: %v", err)
+			}
+			if res.Decision != tt.decision {
+				t.Fatalf("decision is %q, want %q", res.Decision, tt.decision)
+			}
+			if len(res.FieldErrors) != len(tt.rejected) {
+				t.Fatalf("got field errors %v, want fields %v", res.FieldErrors, tt.rejected)
+			}
+			for i, field := range tt.rejected {
+				if res.FieldErrors[i].Field != field {
+					t.Fatalf("field error %d is on %q, want %q", i, res.FieldErrors[i].Field, field)
+				}
+			}
+		})
+	}
+}
+
+func TestBlocklistMask(t *testing.T) {
+	b := NewBlocklist(nil)
+
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{"clean text", "Semangat terus!", "Semangat terus!"},
+		{"one word", "Keren banget anjing", "Keren banget ******"},
+		{"keeps the case of the rest", "SHIT, that was GOOD", "****, that was GOOD"},
+		{"word with a suffix", "fucking awesome", "******* awesome"},
+		{"several words", "bangsat, goblok", "*******, ******"},
+		{"keyword inside another word", "assassinate", "assassinate"},
+		{"reject patterns are left alone", "slot gacor", "slot gacor"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := b.Mask(tt.text); got != tt.want {
+				t.Fatalf("masked %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package moderation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/rxmy43/support-platform/internal/apperror"
+	"github.com/rxmy43/support-platform/internal/llm"
+)
+
+const classifierPrompt = `You are a content moderator for a creator support platform used mostly in Indonesia.
+Classify the user content below. Answer ONLY with JSON of the form {"decision":"allow|flag|reject","reason":"..."}.
+- "reject": scams, gambling promotion, hate speech, sexual content involving minors, doxxing, credible threats.
+- "flag": profanity, adult themes, harassment or anything a human should double check.
+- "allow": everything else.`
+
+// LLMClassifier asks the LLM used for AI captions to classify content.
+type LLMClassifier struct {
+	client llm.Client
+}
+
+func NewLLMClassifier(client llm.Client) *LLMClassifier {
+	return &LLMClassifier{client: client}
+}
+
+func (c *LLMClassifier) Moderate(ctx context.Context, content []Content) (Result, error) {
+	var sb strings.Builder
+	for _, item := range content {
+		fmt.Fprintf(&sb, "[%s]\n%s\n\n", item.Field, item.Text)
+	}
+
+	completion, err := c.client.Chat(ctx, llm.ChatRequest{
+		Messages: []llm.Message{
+			{Role: "system", Content: classifierPrompt},
+			{Role: "user", Content: sb.String()},
+		},
+		JSON: true,
+	})
+	if err != nil {
+		return Result{}, err
+	}
+	answer := completion.Content
+
+	var verdict struct {
+		Decision Decision `json:"decision"`
+		Reason   string   `json:"reason"`
+	}
+
+	// models like to wrap JSON in code fences
+	answer = strings.TrimSpace(strings.Trim(strings.TrimSpace(answer), "`"))
+	answer = strings.TrimPrefix(answer, "json")
+	if err := json.Unmarshal([]byte(answer), &verdict); err != nil {
+		return Result{}, fmt.Errorf("unexpected classifier answer %q: %w", answer, err)
+	}
+
+	if _, ok := severity[verdict.Decision]; !ok {
+		return Result{}, fmt.Errorf("unknown classifier decision %q", verdict.Decision)
+	}
+
+	res := Result{Decision: verdict.Decision, Reason: verdict.Reason}
+	if res.Decision == Reject {
+		for _, item := range content {
+			res.FieldErrors = append(res.FieldErrors, apperror.NewFieldError(item.Field, apperror.CodeContentRejected).WithMessage(verdict.Reason))
+		}
+	}
+
+	return res, nil
+}
@@ -0,0 +1,97 @@
+package moderation
+
+import (
+	"context"
+	"log"
+
+	"github.com/rxmy43/support-platform/internal/apperror"
+	"github.com/rxmy43/support-platform/internal/config"
+	"github.com/rxmy43/support-platform/internal/llm"
+)
+
+// Decision is what should happen to a piece of user content.
+type Decision string
+
+const (
+	Allow  Decision = "allow"
+	Flag   Decision = "flag"
+	Reject Decision = "reject"
+)
+
+// severity orders decisions so the strictest one wins when combining moderators.
+var severity = map[Decision]int{
+	Allow:  0,
+	Flag:   1,
+	Reject: 2,
+}
+
+// Content is one field of user input to moderate.
+type Content struct {
+	Field string
+	Text  string
+}
+
+// Result is the outcome of moderating content. FieldErrors is only set when
+// the decision is Reject.
+type Result struct {
+	Decision    Decision
+	Reason      string
+	FieldErrors []apperror.FieldError
+}
+
+// ContentModerator checks user content before it is published.
+type ContentModerator interface {
+	Moderate(ctx context.Context, content []Content) (Result, error)
+}
+
+// NewFromConfig builds the moderator used on post create and update: the
+// blocklist, plus the LLM classifier when it is enabled.
+func NewFromConfig(cfg *config.Config, client llm.Client) ContentModerator {
+	moderators := []ContentModerator{NewBlocklist(cfg.Moderation.Blocklist)}
+
+	if cfg.Moderation.LLMEnabled {
+		moderators = append(moderators, NewLLMClassifier(client))
+	}
+
+	return Chain(moderators...)
+}
+
+type chain struct {
+	moderators []ContentModerator
+}
+
+// Chain runs every moderator and keeps the strictest decision. A moderator
+// failing is logged and skipped so an outage of an external classifier does
+// not block publishing.
+func Chain(moderators ...ContentModerator) ContentModerator {
+	return &chain{moderators: moderators}
+}
+
+func (c *chain) Moderate(ctx context.Context, content []Content) (Result, error) {
+	final := Result{Decision: Allow}
+
+	for _, m := range c.moderators {
+		res, err := m.Moderate(ctx, content)
+		if err != nil {
+			log.Println("content moderator failed:", err)
+			continue
+		}
+
+		if severity[res.Decision] > severity[final.Decision] {
+			final.Decision = res.Decision
+			final.Reason = res.Reason
+		}
+		final.FieldErrors = append(final.FieldErrors, res.FieldErrors...)
+
+		// nothing is stricter than a rejection
+		if final.Decision == Reject {
+			break
+		}
+	}
+
+	if final.Decision != Reject {
+		final.FieldErrors = nil
+	}
+
+	return final, nil
+}
@@ -0,0 +1,25 @@
+package aiusage
+
+import "time"
+
+type QuotaUsage struct {
+	Used int `json:"used"`
+	// Limit and Remaining are null when the quota is disabled.
+	Limit     *int      `json:"limit"`
+	Remaining *int      `json:"remaining"`
+	ResetsAt  time.Time `json:"resets_at"`
+}
+
+type UsageResponse struct {
+	Daily            QuotaUsage `json:"daily"`
+	Monthly          QuotaUsage `json:"monthly"`
+	PromptTokens     int        `json:"month_prompt_tokens"`
+	CompletionTokens int        `json:"month_completion_tokens"`
+}
+
+type usageCount struct {
+	Today            int `db:"today"`
+	Month            int `db:"month"`
+	PromptTokens     int `db:"prompt_tokens"`
+	CompletionTokens int `db:"completion_tokens"`
+}
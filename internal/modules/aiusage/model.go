@@ -0,0 +1,28 @@
+package aiusage
+
+import "time"
+
+type AIUsage struct {
+	ID               uint       `db:"id"`
+	CreatorID        uint       `db:"creator_id"`
+	Feature          string     `db:"feature"`
+	Outcome          string     `db:"outcome"`
+	PromptTokens     int        `db:"prompt_tokens"`
+	CompletionTokens int        `db:"completion_tokens"`
+	LatencyMS        *int       `db:"latency_ms"`
+	ErrorMessage     *string    `db:"error_message"`
+	CreatedAt        time.Time  `db:"created_at"`
+	FinishedAt       *time.Time `db:"finished_at"`
+}
+
+const (
+	FeatureCaption       = "caption"
+	FeatureCaptionStream = "caption_stream"
+)
+
+const (
+	OutcomePending   = "pending"
+	OutcomeSuccess   = "success"
+	OutcomeFailed    = "failed"
+	OutcomeCancelled = "cancelled"
+)
@@ -0,0 +1,92 @@
+package aiusage
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/rxmy43/support-platform/internal/repo"
+)
+
+type AIUsageRepo struct {
+	*repo.BaseRepo[AIUsage]
+}
+
+func NewAIUsageRepo(DB *sqlx.DB) *AIUsageRepo {
+	return &AIUsageRepo{
+		BaseRepo: &repo.BaseRepo[AIUsage]{
+			DB:        DB,
+			TableName: "ai_usage",
+		},
+	}
+}
+
+var (
+	ErrDailyQuotaExceeded   = errors.New("daily ai quota exceeded")
+	ErrMonthlyQuotaExceeded = errors.New("monthly ai quota exceeded")
+)
+
+// countQuery counts the calls charged to a creator, failed and cancelled
+// calls are not charged. The token totals include every call, a failed or
+// cancelled call still used the tokens it streamed.
+const countQuery = `
+	SELECT
+		COUNT(*) FILTER (WHERE created_at >= $2 AND outcome IN ('pending', 'success')) AS today,
+		COUNT(*) FILTER (WHERE outcome IN ('pending', 'success')) AS month,
+		COALESCE(SUM(prompt_tokens), 0) AS prompt_tokens,
+		COALESCE(SUM(completion_tokens), 0) AS completion_tokens
+	FROM ai_usage
+	WHERE creator_id = $1 AND created_at >= $3
+`
+
+func (r *AIUsageRepo) Count(ctx context.Context, creatorID uint, dayStart, monthStart time.Time) (usageCount, error) {
+	var count usageCount
+	err := r.DB.GetContext(ctx, &count, countQuery, creatorID, dayStart, monthStart)
+	return count, err
+}
+
+// Reserve records a pending call when the creator is still within the quotas.
+// The creator row is locked so concurrent calls cannot both take the last
+// slot. A limit of 0 disables that quota.
+func (r *AIUsageRepo) Reserve(ctx context.Context, creatorID uint, feature string, dayStart, monthStart time.Time, dailyLimit, monthlyLimit int) (uint, error) {
+	tx, err := r.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "SELECT id FROM users WHERE id = $1 FOR UPDATE", creatorID); err != nil {
+		return 0, err
+	}
+
+	var count usageCount
+	if err := tx.GetContext(ctx, &count, countQuery, creatorID, dayStart, monthStart); err != nil {
+		return 0, err
+	}
+
+	if dailyLimit > 0 && count.Today >= dailyLimit {
+		return 0, ErrDailyQuotaExceeded
+	}
+	if monthlyLimit > 0 && count.Month >= monthlyLimit {
+		return 0, ErrMonthlyQuotaExceeded
+	}
+
+	var id uint
+	query := "INSERT INTO ai_usage (creator_id, feature) VALUES ($1, $2) RETURNING id"
+	if err := tx.GetContext(ctx, &id, query, creatorID, feature); err != nil {
+		return 0, err
+	}
+
+	return id, tx.Commit()
+}
+
+func (r *AIUsageRepo) Finish(ctx context.Context, u *AIUsage) error {
+	query := `
+		UPDATE ai_usage
+		SET outcome = $2, prompt_tokens = $3, completion_tokens = $4, latency_ms = $5, error_message = $6, finished_at = NOW()
+		WHERE id = $1
+	`
+	_, err := r.DB.ExecContext(ctx, query, u.ID, u.Outcome, u.PromptTokens, u.CompletionTokens, u.LatencyMS, u.ErrorMessage)
+	return err
+}
@@ -0,0 +1,155 @@
+package aiusage
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+	"unicode/utf8"
+
+	"github.com/rxmy43/support-platform/internal/apperror"
+	"github.com/rxmy43/support-platform/internal/config"
+	"github.com/rxmy43/support-platform/internal/llm"
+	"github.com/rxmy43/support-platform/internal/modules/user"
+)
+
+type AIUsageService struct {
+	aiUsageRepo *AIUsageRepo
+	userRepo    *user.UserRepo
+	quota       config.AIQuotaConfig
+}
+
+func NewAIUsageService(aiUsageRepo *AIUsageRepo, userRepo *user.UserRepo, quota config.AIQuotaConfig) *AIUsageService {
+	return &AIUsageService{
+		aiUsageRepo: aiUsageRepo,
+		userRepo:    userRepo,
+		quota:       quota,
+	}
+}
+
+// Call is one reserved AI call. Finish must be called once it is done.
+type Call struct {
+	id        uint
+	startedAt time.Time
+	usage     llm.Usage
+}
+
+// AddUsage adds the tokens of one LLM request made for this call.
+func (c *Call) AddUsage(u llm.Usage) {
+	c.usage.Add(u)
+}
+
+func periodStarts(now time.Time) (time.Time, time.Time) {
+	y, m, d := now.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, now.Location()), time.Date(y, m, 1, 0, 0, 0, 0, now.Location())
+}
+
+func (s *AIUsageService) checkCreator(ctx context.Context, creatorID uint) *apperror.AppError {
+	creator, err := s.userRepo.FindByID(ctx, creatorID)
+	if err != nil {
+		return apperror.Unauthorized("invalid user", apperror.CodeUnauthorizedOperation)
+	}
+
+	if creator.Role != "creator" {
+		return apperror.Forbidden("only creator can use AI features", apperror.CodeUnauthorizedOperation)
+	}
+
+	return nil
+}
+
+// Begin checks the creator's quotas and reserves one call against them.
+func (s *AIUsageService) Begin(ctx context.Context, creatorID uint, feature string) (*Call, *apperror.AppError) {
+	if appErr := s.checkCreator(ctx, creatorID); appErr != nil {
+		return nil, appErr
+	}
+
+	dayStart, monthStart := periodStarts(time.Now())
+
+	id, err := s.aiUsageRepo.Reserve(ctx, creatorID, feature, dayStart, monthStart, s.quota.CaptionDaily, s.quota.CaptionMonthly)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrDailyQuotaExceeded):
+			return nil, apperror.New(apperror.CodeQuotaExceeded, http.StatusTooManyRequests, "daily AI caption quota exceeded, try again tomorrow")
+		case errors.Is(err, ErrMonthlyQuotaExceeded):
+			return nil, apperror.New(apperror.CodeQuotaExceeded, http.StatusTooManyRequests, "monthly AI caption quota exceeded")
+		}
+		return nil, apperror.InternalServer("failed reserving AI usage").WithCause(err)
+	}
+
+	return &Call{id: id, startedAt: time.Now()}, nil
+}
+
+const maxErrorMessageLength = 500
+
+// truncate cuts s to at most n bytes without splitting a UTF-8 rune.
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
+
+// Finish records the outcome of a call. It runs on its own context so a call
+// cancelled by the client is still recorded.
+func (s *AIUsageService) Finish(call *Call, callErr error) {
+	latency := int(time.Since(call.startedAt).Milliseconds())
+
+	u := &AIUsage{
+		ID:               call.id,
+		Outcome:          OutcomeSuccess,
+		PromptTokens:     call.usage.PromptTokens,
+		CompletionTokens: call.usage.CompletionTokens,
+		LatencyMS:        &latency,
+	}
+
+	if callErr != nil {
+		u.Outcome = OutcomeFailed
+		if errors.Is(callErr, context.Canceled) {
+			u.Outcome = OutcomeCancelled
+		}
+
+		msg := truncate(callErr.Error(), maxErrorMessageLength)
+		u.ErrorMessage = &msg
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := s.aiUsageRepo.Finish(ctx, u); err != nil {
+		log.Println("failed recording ai usage:", err)
+	}
+}
+
+func quotaUsage(used, limit int, resetsAt time.Time) QuotaUsage {
+	q := QuotaUsage{Used: used, ResetsAt: resetsAt}
+	if limit > 0 {
+		remaining := max(limit-used, 0)
+		q.Limit = &limit
+		q.Remaining = &remaining
+	}
+	return q
+}
+
+func (s *AIUsageService) Usage(ctx context.Context, creatorID uint) (*UsageResponse, *apperror.AppError) {
+	if appErr := s.checkCreator(ctx, creatorID); appErr != nil {
+		return nil, appErr
+	}
+
+	dayStart, monthStart := periodStarts(time.Now())
+
+	count, err := s.aiUsageRepo.Count(ctx, creatorID, dayStart, monthStart)
+	if err != nil {
+		return nil, apperror.InternalServer("failed get AI usage").WithCause(err)
+	}
+
+	return &UsageResponse{
+		Daily:            quotaUsage(count.Today, s.quota.CaptionDaily, dayStart.AddDate(0, 0, 1)),
+		Monthly:          quotaUsage(count.Month, s.quota.CaptionMonthly, monthStart.AddDate(0, 1, 0)),
+		PromptTokens:     count.PromptTokens,
+		CompletionTokens: count.CompletionTokens,
+	}, nil
+}
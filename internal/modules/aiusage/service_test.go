@@ -0,0 +1,35 @@
+package aiusage
+
+import (
+	"testing"
+	"unicode/utf8"
+)
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		name string
+		s    string
+		n    int
+		want string
+	}{
+		{"shorter", "timeout", 10, "timeout"},
+		{"exact", "timeout", 7, "timeout"},
+		{"ascii", "timeout", 4, "time"},
+		{"cut on a rune boundary", "gagal: ✓ok", 10, "gagal: ✓"},
+		{"cut inside a rune", "gagal: ✓ok", 8, "gagal: "},
+		{"cut inside the first rune", "✓", 2, ""},
+		{"zero", "timeout", 0, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := truncate(tt.s, tt.n)
+			if got != tt.want {
+				t.Fatalf("truncated to %q, want %q", got, tt.want)
+			}
+			if !utf8.ValidString(got) {
+				t.Fatalf("truncated to invalid utf-8 %q", got)
+			}
+		})
+	}
+}
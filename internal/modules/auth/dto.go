@@ -8,10 +8,3 @@ type VerifyOTPRequest struct {
 	Phone string `json:"phone"`
 	OTP   string `json:"otp"`
 }
-
-type UserResponse struct {
-	ID    uint   `json:"id"`
-	Name  string `json:"name"`
-	Phone string `json:"phone"`
-	Role  string `json:"role"`
-}
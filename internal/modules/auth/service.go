@@ -45,22 +45,23 @@ func (s *AuthService) GenerateOTP(ctx context.Context, req GenerateOTPRequest) (
 	return otp, nil
 }
 
-func (s *AuthService) VerifyOTP(ctx context.Context, req VerifyOTPRequest) (*UserResponse, *apperror.AppError) {
+func (s *AuthService) VerifyOTP(ctx context.Context, req VerifyOTPRequest) (*user.UserResponse, *apperror.AppError) {
 	val, ok := otpStore.Load(req.Phone)
 	if !ok || val.(string) != req.OTP {
 		return nil, apperror.BadRequest("invalid otp", apperror.CodeInvalidCredentials)
 	}
 
-	user, err := s.userRepo.FindOneByPhone(ctx, req.Phone)
+	u, err := s.userRepo.FindOneByPhone(ctx, req.Phone)
 	if err != nil && err == sql.ErrNoRows {
 		return nil, apperror.NotFound("user not found", apperror.CodePhoneInvalid).WithNotFoundField("phone")
 	}
 
 	otpStore.Delete(req.Phone)
-	return &UserResponse{
-		ID:    user.ID,
-		Name:  user.Name,
-		Phone: user.Phone,
-		Role:  user.Role,
+	return &user.UserResponse{
+		ID:     u.ID,
+		Name:   u.Name,
+		Handle: u.Handle,
+		Phone:  u.Phone,
+		Role:   u.Role,
 	}, nil
 }
@@ -0,0 +1,29 @@
+package campaign
+
+import "time"
+
+type CampaignCreateRequest struct {
+	Title        string `json:"title"`
+	Description  string `json:"description"`
+	TargetAmount int64  `json:"target_amount"`
+	// Deadline is RFC3339, a campaign without one runs until it is reached
+	// or closed.
+	Deadline string `json:"deadline"`
+}
+
+// CampaignResponse is a campaign with its progress, computed from its paid
+// supports.
+type CampaignResponse struct {
+	ID              uint       `json:"id" db:"id"`
+	CreatorID       uint       `json:"creator_id" db:"creator_id"`
+	Title           string     `json:"title" db:"title"`
+	Description     *string    `json:"description" db:"description"`
+	TargetAmount    int64      `json:"target_amount" db:"target_amount"`
+	Deadline        *time.Time `json:"deadline" db:"deadline"`
+	Status          string     `json:"status" db:"status"`
+	ClosedAt        *time.Time `json:"closed_at" db:"closed_at"`
+	CreatedAt       time.Time  `json:"created_at" db:"created_at"`
+	RaisedAmount    int64      `json:"raised_amount" db:"raised_amount"`
+	SupportCount    int        `json:"support_count" db:"support_count"`
+	ProgressPercent int64      `json:"progress_percent" db:"-"`
+}
@@ -0,0 +1,34 @@
+package campaign
+
+import "time"
+
+type Campaign struct {
+	ID           uint       `db:"id"`
+	CreatorID    uint       `db:"creator_id"`
+	Title        string     `db:"title"`
+	Description  *string    `db:"description"`
+	TargetAmount int64      `db:"target_amount"`
+	Deadline     *time.Time `db:"deadline"`
+	Status       string     `db:"status"`
+	ClosedAt     *time.Time `db:"closed_at"`
+	CreatedAt    time.Time  `db:"created_at"`
+}
+
+// Campaign statuses. A campaign takes supports while active, it is closed
+// for good once its target is reached, its deadline passes or the creator
+// closes it.
+const (
+	StatusActive  = "active"
+	StatusReached = "reached"
+	StatusExpired = "expired"
+	StatusClosed  = "closed"
+)
+
+const (
+	maxTitleLength       = 100
+	maxDescriptionLength = 1000
+)
+
+// JobProgress updates the campaign of a paid support and tells the creator
+// about its progress over the socket.
+const JobProgress = "campaign.progress"
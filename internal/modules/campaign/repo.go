@@ -0,0 +1,115 @@
+package campaign
+
+import (
+	"context"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/rxmy43/support-platform/internal/repo"
+)
+
+type CampaignRepo struct {
+	*repo.BaseRepo[Campaign]
+}
+
+func NewCampaignRepo(DB *sqlx.DB) *CampaignRepo {
+	return &CampaignRepo{
+		BaseRepo: &repo.BaseRepo[Campaign]{
+			DB:        DB,
+			TableName: "campaigns",
+		},
+	}
+}
+
+func (r *CampaignRepo) Insert(ctx context.Context, c *Campaign) error {
+	query := `
+		INSERT INTO campaigns (creator_id, title, description, target_amount, deadline)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, status, created_at
+	`
+	return r.DB.QueryRowxContext(ctx, query,
+		c.CreatorID, c.Title, c.Description, c.TargetAmount, c.Deadline,
+	).Scan(&c.ID, &c.Status, &c.CreatedAt)
+}
+
+// selectProgress selects campaigns with the amount raised by their paid
+// supports, the caller adds the WHERE clause before groupProgress.
+const selectProgress = `
+	SELECT c.id, c.creator_id, c.title, c.description, c.target_amount, c.deadline, c.status, c.closed_at, c.created_at,
+		COALESCE(SUM(s.amount), 0)::BIGINT AS raised_amount,
+		COUNT(s.id) AS support_count
+	FROM campaigns c
+	LEFT JOIN supports s ON s.campaign_id = c.id AND s.status = 'paid'
+`
+
+const groupProgress = " GROUP BY c.id"
+
+func (r *CampaignRepo) FindWithProgress(ctx context.Context, id uint) (*CampaignResponse, error) {
+	var campaign CampaignResponse
+	if err := r.DB.GetContext(ctx, &campaign, selectProgress+" WHERE c.id = $1"+groupProgress, id); err != nil {
+		return nil, err
+	}
+	return &campaign, nil
+}
+
+// FindByCreator lists the campaigns of a creator, newest first.
+func (r *CampaignRepo) FindByCreator(ctx context.Context, creatorID uint) ([]CampaignResponse, error) {
+	campaigns := []CampaignResponse{}
+	err := r.DB.SelectContext(ctx, &campaigns, selectProgress+" WHERE c.creator_id = $1"+groupProgress+" ORDER BY c.id DESC", creatorID)
+	return campaigns, err
+}
+
+// CloseOwn closes an active campaign of the creator, it reports false when
+// the creator has no such campaign.
+func (r *CampaignRepo) CloseOwn(ctx context.Context, id, creatorID uint) (bool, error) {
+	res, err := r.DB.ExecContext(ctx, `
+		UPDATE campaigns SET status = 'closed', closed_at = NOW()
+		WHERE id = $1 AND creator_id = $2 AND status = 'active'
+	`, id, creatorID)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected > 0, nil
+}
+
+// MarkReached closes an active campaign whose paid supports reached its
+// target, it reports whether the campaign was closed.
+func (r *CampaignRepo) MarkReached(ctx context.Context, id uint) (bool, error) {
+	res, err := r.DB.ExecContext(ctx, `
+		UPDATE campaigns c SET status = 'reached', closed_at = NOW()
+		WHERE c.id = $1 AND c.status = 'active'
+		AND c.target_amount <= (
+			SELECT COALESCE(SUM(s.amount), 0) FROM supports s
+			WHERE s.campaign_id = c.id AND s.status = 'paid'
+		)
+	`, id)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected > 0, nil
+}
+
+// ExpireOverdue closes the active campaigns past their deadline and returns
+// how many were expired.
+func (r *CampaignRepo) ExpireOverdue(ctx context.Context) (int64, error) {
+	res, err := r.DB.ExecContext(ctx, `
+		UPDATE campaigns SET status = 'expired', closed_at = NOW()
+		WHERE status = 'active' AND deadline <= NOW()
+	`)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
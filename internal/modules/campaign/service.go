@@ -0,0 +1,177 @@
+package campaign
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/rxmy43/support-platform/internal/apperror"
+	"github.com/rxmy43/support-platform/internal/modules/support"
+	"github.com/rxmy43/support-platform/internal/outbox"
+	"github.com/rxmy43/support-platform/internal/socket"
+)
+
+type CampaignService struct {
+	campaignRepo *CampaignRepo
+	hub          *socket.Hub
+}
+
+func NewCampaignService(campaignRepo *CampaignRepo, hub *socket.Hub) *CampaignService {
+	return &CampaignService{
+		campaignRepo: campaignRepo,
+		hub:          hub,
+	}
+}
+
+// withProgress sets the progress of a campaign, it goes past 100 when the
+// campaign raised more than its target.
+func withProgress(c *CampaignResponse) *CampaignResponse {
+	c.ProgressPercent = c.RaisedAmount * 100 / c.TargetAmount
+	return c
+}
+
+func (s *CampaignService) Create(ctx context.Context, creatorID uint, req CampaignCreateRequest) (*CampaignResponse, *apperror.AppError) {
+	var fieldErrs []apperror.FieldError
+
+	title := strings.TrimSpace(req.Title)
+	if title == "" {
+		fieldErrs = append(fieldErrs, apperror.NewFieldError("title", apperror.CodeFieldRequired))
+	} else if len([]rune(title)) > maxTitleLength {
+		fieldErrs = append(fieldErrs, apperror.NewFieldError("title", apperror.CodeFieldTooLong))
+	}
+
+	description := strings.TrimSpace(req.Description)
+	if len([]rune(description)) > maxDescriptionLength {
+		fieldErrs = append(fieldErrs, apperror.NewFieldError("description", apperror.CodeFieldTooLong))
+	}
+
+	if req.TargetAmount <= 0 {
+		fieldErrs = append(fieldErrs, apperror.NewFieldError("target_amount", apperror.CodePositiveRequired))
+	}
+
+	var deadline *time.Time
+	if req.Deadline != "" {
+		t, err := time.Parse(time.RFC3339, req.Deadline)
+		if err != nil {
+			fieldErrs = append(fieldErrs, apperror.NewFieldError("deadline", apperror.CodeDateTimeInvalid).WithExpect("RFC3339"))
+		} else if !t.After(time.Now()) {
+			fieldErrs = append(fieldErrs, apperror.NewFieldError("deadline", apperror.CodeDateInPast))
+		} else {
+			deadline = &t
+		}
+	}
+
+	if len(fieldErrs) > 0 {
+		return nil, apperror.ValidationError("create campaign validation error", fieldErrs)
+	}
+
+	c := &Campaign{
+		CreatorID:    creatorID,
+		Title:        title,
+		TargetAmount: req.TargetAmount,
+		Deadline:     deadline,
+	}
+	if description != "" {
+		c.Description = &description
+	}
+
+	if err := s.campaignRepo.Insert(ctx, c); err != nil {
+		return nil, apperror.InternalServer("failed creating campaign").WithCause(err)
+	}
+
+	return withProgress(&CampaignResponse{
+		ID:           c.ID,
+		CreatorID:    c.CreatorID,
+		Title:        c.Title,
+		Description:  c.Description,
+		TargetAmount: c.TargetAmount,
+		Deadline:     c.Deadline,
+		Status:       c.Status,
+		CreatedAt:    c.CreatedAt,
+	}), nil
+}
+
+func (s *CampaignService) FindAll(ctx context.Context, creatorID uint) ([]CampaignResponse, *apperror.AppError) {
+	campaigns, err := s.campaignRepo.FindByCreator(ctx, creatorID)
+	if err != nil {
+		return nil, apperror.InternalServer("failed get campaigns").WithCause(err)
+	}
+
+	for i := range campaigns {
+		withProgress(&campaigns[i])
+	}
+
+	return campaigns, nil
+}
+
+// GetProgress returns a campaign with the amount its paid supports raised.
+func (s *CampaignService) GetProgress(ctx context.Context, id uint) (*CampaignResponse, *apperror.AppError) {
+	c, err := s.campaignRepo.FindWithProgress(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, apperror.NotFound("campaign not found", apperror.CodeResourceNotFound)
+		}
+		return nil, apperror.InternalServer("failed get campaign").WithCause(err)
+	}
+
+	return withProgress(c), nil
+}
+
+// Close stops an active campaign of the creator from taking supports.
+func (s *CampaignService) Close(ctx context.Context, id, creatorID uint) *apperror.AppError {
+	closed, err := s.campaignRepo.CloseOwn(ctx, id, creatorID)
+	if err != nil {
+		return apperror.InternalServer("failed closing campaign").WithCause(err)
+	}
+
+	if !closed {
+		return apperror.NotFound("active campaign not found", apperror.CodeResourceNotFound)
+	}
+
+	return nil
+}
+
+// ExpireOverdue expires the active campaigns past their deadline.
+func (s *CampaignService) ExpireOverdue(ctx context.Context) (int64, error) {
+	return s.campaignRepo.ExpireOverdue(ctx)
+}
+
+// HandleSupportPaid runs the JobProgress jobs of support.EventSupportPaid,
+// closing the campaign when the support reached its target.
+func (s *CampaignService) HandleSupportPaid(ctx context.Context, payload json.RawMessage) error {
+	var paid support.SupportPaidEvent
+	if _, err := outbox.Decode(payload, &paid); err != nil {
+		return err
+	}
+
+	if paid.CampaignID == nil {
+		return nil
+	}
+
+	if _, err := s.campaignRepo.MarkReached(ctx, *paid.CampaignID); err != nil {
+		return err
+	}
+
+	c, err := s.campaignRepo.FindWithProgress(ctx, *paid.CampaignID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			// deleted since, nothing to report
+			return nil
+		}
+		return err
+	}
+
+	msg := socket.EventMessage{
+		Event: "campaign_progress",
+		Data:  withProgress(c),
+	}
+
+	// the creator, and the fans following the campaign page
+	if err := s.hub.SendToCreator(ctx, c.CreatorID, msg); err != nil {
+		return err
+	}
+	return s.hub.SendToCampaign(ctx, c.ID, msg)
+}
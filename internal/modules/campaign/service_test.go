@@ -0,0 +1,227 @@
+package campaign
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	_ "github.com/lib/pq"
+	"github.com/rxmy43/support-platform/internal/modules/support"
+)
+
+func TestWithProgress(t *testing.T) {
+	tests := []struct {
+		name   string
+		raised int64
+		target int64
+		want   int64
+	}{
+		{"nothing raised", 0, 2000000, 0},
+		{"rounds down", 999999, 2000000, 49},
+		{"half way", 1000000, 2000000, 50},
+		{"reached", 2000000, 2000000, 100},
+		{"raised past the target", 3000000, 2000000, 150},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := withProgress(&CampaignResponse{RaisedAmount: tt.raised, TargetAmount: tt.target})
+			if c.ProgressPercent != tt.want {
+				t.Fatalf("progress is %d, want %d", c.ProgressPercent, tt.want)
+			}
+		})
+	}
+}
+
+// fixture is a creator and a fan seeded in the database of TEST_DATABASE_URL.
+type fixture struct {
+	db        *sqlx.DB
+	suffix    int64
+	creatorID uint
+	fanID     uint
+	supports  int
+}
+
+func newFixture(t *testing.T) *fixture {
+	t.Helper()
+
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+
+	db, err := sqlx.Connect("postgres", dsn)
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	f := &fixture{db: db, suffix: time.Now().UnixNano()}
+	f.creatorID = f.user(t, "creator")
+	f.fanID = f.user(t, "fan")
+
+	return f
+}
+
+func (f *fixture) user(t *testing.T, role string) uint {
+	t.Helper()
+
+	var id uint
+	n := time.Now().UnixNano()
+	query := "INSERT INTO users (name, handle, phone, role) VALUES ($1, $2, $3, $4) RETURNING id"
+	if err := f.db.Get(&id, query, role, fmt.Sprintf("%s-%d", role, n), fmt.Sprintf("+3%d", n%1e12), role); err != nil {
+		t.Fatalf("insert %s: %v", role, err)
+	}
+	t.Cleanup(func() { f.db.Exec("DELETE FROM users WHERE id = $1", id) })
+
+	return id
+}
+
+func (f *fixture) campaign(t *testing.T, creatorID uint, target int64, status string, deadline *time.Time) uint {
+	t.Helper()
+
+	var id uint
+	query := `
+		INSERT INTO campaigns (creator_id, title, target_amount, status, deadline)
+		VALUES ($1, 'New mic', $2, $3, $4)
+		RETURNING id
+	`
+	if err := f.db.Get(&id, query, creatorID, target, status, deadline); err != nil {
+		t.Fatalf("insert campaign: %v", err)
+	}
+
+	return id
+}
+
+func (f *fixture) support(t *testing.T, campaignID uint, amount int64, status string) {
+	t.Helper()
+
+	f.supports++
+	_, err := f.db.Exec(`
+		INSERT INTO supports (fan_id, creator_id, amount, status, support_id, campaign_id)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`, f.fanID, f.creatorID, amount, status, fmt.Sprintf("SUPPORT/TEST/%d/%d", f.suffix, f.supports), campaignID)
+	if err != nil {
+		t.Fatalf("insert support: %v", err)
+	}
+}
+
+func (f *fixture) status(t *testing.T, campaignID uint) string {
+	t.Helper()
+
+	var status string
+	if err := f.db.Get(&status, "SELECT status FROM campaigns WHERE id = $1", campaignID); err != nil {
+		t.Fatalf("get campaign: %v", err)
+	}
+
+	return status
+}
+
+func TestMarkReached(t *testing.T) {
+	f := newFixture(t)
+	ctx := context.Background()
+	repo := NewCampaignRepo(f.db)
+
+	id := f.campaign(t, f.creatorID, 100000, StatusActive, nil)
+
+	steps := []struct {
+		name    string
+		amount  int64
+		status  string
+		reached bool
+	}{
+		{"below the target", 60000, support.StatusPaid, false},
+		{"pending supports do not count", 50000, support.StatusPending, false},
+		{"paid support reaching the target", 40000, support.StatusPaid, true},
+		{"already reached", 10000, support.StatusPaid, false},
+	}
+
+	for _, step := range steps {
+		f.support(t, id, step.amount, step.status)
+
+		reached, err := repo.MarkReached(ctx, id)
+		if err != nil {
+			t.Fatalf("%s: %v", step.name, err)
+		}
+		if reached != step.reached {
+			t.Fatalf("%s: reached is %v, want %v", step.name, reached, step.reached)
+		}
+	}
+
+	if status := f.status(t, id); status != StatusReached {
+		t.Fatalf("campaign status is %q, want %q", status, StatusReached)
+	}
+}
+
+func TestExpireOverdue(t *testing.T) {
+	f := newFixture(t)
+	ctx := context.Background()
+	repo := NewCampaignRepo(f.db)
+
+	past := time.Now().Add(-time.Hour)
+	future := time.Now().Add(time.Hour)
+
+	overdue := f.campaign(t, f.creatorID, 100000, StatusActive, &past)
+	running := f.campaign(t, f.creatorID, 100000, StatusActive, &future)
+	open := f.campaign(t, f.creatorID, 100000, StatusActive, nil)
+	closed := f.campaign(t, f.creatorID, 100000, StatusClosed, &past)
+
+	expired, err := repo.ExpireOverdue(ctx)
+	if err != nil {
+		t.Fatalf("expire: %v", err)
+	}
+	if expired < 1 {
+		t.Fatalf("expired %d campaigns, want at least 1", expired)
+	}
+
+	want := map[uint]string{
+		overdue: StatusExpired,
+		running: StatusActive,
+		open:    StatusActive,
+		closed:  StatusClosed,
+	}
+	for id, status := range want {
+		if got := f.status(t, id); got != status {
+			t.Fatalf("campaign %d status is %q, want %q", id, got, status)
+		}
+	}
+}
+
+func TestCampaignOpen(t *testing.T) {
+	f := newFixture(t)
+	ctx := context.Background()
+	repo := support.NewSupportRepo(f.db)
+
+	past := time.Now().Add(-time.Hour)
+	otherCreator := f.user(t, "creator")
+
+	tests := []struct {
+		name     string
+		campaign uint
+		open     bool
+		err      error
+	}{
+		{"active", f.campaign(t, f.creatorID, 100000, StatusActive, nil), true, nil},
+		{"closed", f.campaign(t, f.creatorID, 100000, StatusClosed, nil), false, nil},
+		{"reached", f.campaign(t, f.creatorID, 100000, StatusReached, nil), false, nil},
+		{"past its deadline", f.campaign(t, f.creatorID, 100000, StatusActive, &past), false, nil},
+		{"of another creator", f.campaign(t, otherCreator, 100000, StatusActive, nil), false, sql.ErrNoRows},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			open, err := repo.CampaignOpen(ctx, tt.campaign, f.creatorID)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("error is %v, want %v", err, tt.err)
+			}
+			if open != tt.open {
+				t.Fatalf("open is %v, want %v", open, tt.open)
+			}
+		})
+	}
+}
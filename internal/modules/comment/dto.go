@@ -0,0 +1,27 @@
+package comment
+
+import "time"
+
+type CommentCreateRequest struct {
+	Text     string `json:"text"`
+	ParentID *uint  `json:"parent_id"`
+}
+
+type CommentUpdateRequest struct {
+	Text string `json:"text"`
+}
+
+type CommentResponse struct {
+	ID          uint      `json:"id" db:"id"`
+	PostID      uint      `json:"post_id" db:"post_id"`
+	UserID      uint      `json:"user_id" db:"user_id"`
+	UserName    string    `json:"user_name" db:"user_name"`
+	ParentID    *uint     `json:"parent_id" db:"parent_id"`
+	Text        string    `json:"text" db:"text"`
+	IsHidden    bool      `json:"is_hidden" db:"is_hidden"`
+	IsPinned    bool      `json:"is_pinned" db:"is_pinned"`
+	IsSupporter bool      `json:"is_supporter" db:"is_supporter"`
+	ReplyCount  int       `json:"reply_count" db:"reply_count"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
+}
@@ -0,0 +1,16 @@
+package comment
+
+import "time"
+
+type Comment struct {
+	ID          uint       `db:"id"`
+	PostID      uint       `db:"post_id"`
+	UserID      uint       `db:"user_id"`
+	ParentID    *uint      `db:"parent_id"`
+	Text        string     `db:"text"`
+	IsHidden    bool       `db:"is_hidden"`
+	IsPinned    bool       `db:"is_pinned"`
+	CreatedAt   time.Time  `db:"created_at"`
+	UpdatedAt   time.Time  `db:"updated_at"`
+	TakenDownAt *time.Time `db:"taken_down_at"`
+}
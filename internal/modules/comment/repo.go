@@ -0,0 +1,217 @@
+package comment
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/rxmy43/support-platform/internal/pagination"
+	"github.com/rxmy43/support-platform/internal/repo"
+)
+
+type CommentRepo struct {
+	*repo.BaseRepo[Comment]
+}
+
+func NewCommentRepo(DB *sqlx.DB) *CommentRepo {
+	return &CommentRepo{
+		BaseRepo: &repo.BaseRepo[Comment]{
+			DB:        DB,
+			TableName: "comments",
+		},
+	}
+}
+
+const commentSelect = `
+	SELECT
+		c.id,
+		c.post_id,
+		c.user_id,
+		u.name AS user_name,
+		c.parent_id,
+		c.text,
+		c.is_hidden,
+		c.is_pinned,
+		EXISTS (
+			SELECT 1 FROM supports s
+			WHERE s.fan_id = c.user_id
+			AND s.creator_id = p.creator_id
+			AND s.status = 'paid'
+		) AS is_supporter,
+		(
+			SELECT COUNT(*) FROM comments rc
+			WHERE rc.parent_id = c.id
+			AND rc.taken_down_at IS NULL
+			AND ($2 OR NOT rc.is_hidden)
+		) AS reply_count,
+		c.created_at,
+		c.updated_at
+	FROM comments c
+	JOIN users u ON u.id = c.user_id
+	JOIN posts p ON p.id = c.post_id
+`
+
+func (r *CommentRepo) Insert(ctx context.Context, c *Comment) error {
+	query := `
+		INSERT INTO comments (post_id, user_id, parent_id, text)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at, updated_at
+	`
+
+	return r.DB.QueryRowxContext(ctx, query, c.PostID, c.UserID, c.ParentID, c.Text).
+		Scan(&c.ID, &c.CreatedAt, &c.UpdatedAt)
+}
+
+func (r *CommentRepo) UpdateText(ctx context.Context, id uint, text string) error {
+	query := `
+		UPDATE comments
+		SET text = $2, updated_at = NOW()
+		WHERE id = $1
+	`
+	_, err := r.DB.ExecContext(ctx, query, id, text)
+	return err
+}
+
+func (r *CommentRepo) SetHidden(ctx context.Context, id uint, hidden bool) error {
+	query := `
+		UPDATE comments
+		SET is_hidden = $2, updated_at = NOW()
+		WHERE id = $1
+	`
+	_, err := r.DB.ExecContext(ctx, query, id, hidden)
+	return err
+}
+
+// Pin makes the comment the only pinned comment of its post.
+func (r *CommentRepo) Pin(ctx context.Context, postID, commentID uint) error {
+	tx, err := r.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "UPDATE comments SET is_pinned = FALSE WHERE post_id = $1 AND is_pinned", postID); err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, "UPDATE comments SET is_pinned = TRUE WHERE id = $1", commentID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *CommentRepo) Unpin(ctx context.Context, id uint) error {
+	_, err := r.DB.ExecContext(ctx, "UPDATE comments SET is_pinned = FALSE WHERE id = $1", id)
+	return err
+}
+
+func (r *CommentRepo) GetComment(ctx context.Context, id uint) (*CommentResponse, error) {
+	var c CommentResponse
+	query := commentSelect + " WHERE c.id = $1"
+	if err := r.DB.GetContext(ctx, &c, query, id, true); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+type commentCursor struct {
+	ID uint `json:"id"`
+}
+
+// GetComments returns top level comments of a post, newest first. The pinned
+// comment is only returned on the first page, ahead of the others.
+func (r *CommentRepo) GetComments(ctx context.Context, postID uint, params pagination.Params, includeHidden bool) (pagination.Page[CommentResponse], error) {
+	pinned := []CommentResponse{}
+
+	cursor, err := pagination.Decode[commentCursor](params.Cursor)
+	if err != nil {
+		return pagination.Page[CommentResponse]{}, err
+	}
+
+	if cursor == nil {
+		query := commentSelect + `
+			WHERE c.post_id = $1
+			AND c.parent_id IS NULL
+			AND c.is_pinned
+			AND c.taken_down_at IS NULL
+			AND u.taken_down_at IS NULL
+			AND ($2 OR NOT c.is_hidden)
+		`
+		if err := r.DB.SelectContext(ctx, &pinned, query, postID, includeHidden); err != nil {
+			return pagination.Page[CommentResponse]{}, err
+		}
+	}
+
+	where := `
+		WHERE c.post_id = $1
+		AND c.parent_id IS NULL
+		AND NOT c.is_pinned
+		AND c.taken_down_at IS NULL
+		AND u.taken_down_at IS NULL
+		AND ($2 OR NOT c.is_hidden)
+	`
+	args := []any{postID, includeHidden}
+	if cursor != nil {
+		args = append(args, cursor.ID)
+		where += fmt.Sprintf(" AND c.id < $%d", len(args))
+	}
+
+	args = append(args, params.FetchLimit())
+	query := fmt.Sprintf(`
+		%s
+		%s
+		ORDER BY c.id DESC
+		LIMIT $%d
+	`, commentSelect, where, len(args))
+
+	comments := []CommentResponse{}
+	if err := r.DB.SelectContext(ctx, &comments, query, args...); err != nil {
+		return pagination.Page[CommentResponse]{}, err
+	}
+
+	page := pagination.NewPage(comments, params.Limit, func(c CommentResponse) commentCursor {
+		return commentCursor{ID: c.ID}
+	})
+	page.Items = append(pinned, page.Items...)
+
+	return page, nil
+}
+
+// GetReplies returns the replies of a comment in the order they were written.
+func (r *CommentRepo) GetReplies(ctx context.Context, parentID uint, params pagination.Params, includeHidden bool) (pagination.Page[CommentResponse], error) {
+	replies := []CommentResponse{}
+
+	cursor, err := pagination.Decode[commentCursor](params.Cursor)
+	if err != nil {
+		return pagination.Page[CommentResponse]{}, err
+	}
+
+	where := `
+		WHERE c.parent_id = $1
+		AND c.taken_down_at IS NULL
+		AND u.taken_down_at IS NULL
+		AND ($2 OR NOT c.is_hidden)
+	`
+	args := []any{parentID, includeHidden}
+	if cursor != nil {
+		args = append(args, cursor.ID)
+		where += fmt.Sprintf(" AND c.id > $%d", len(args))
+	}
+
+	args = append(args, params.FetchLimit())
+	query := fmt.Sprintf(`
+		%s
+		%s
+		ORDER BY c.id ASC
+		LIMIT $%d
+	`, commentSelect, where, len(args))
+
+	if err := r.DB.SelectContext(ctx, &replies, query, args...); err != nil {
+		return pagination.Page[CommentResponse]{}, err
+	}
+
+	return pagination.NewPage(replies, params.Limit, func(c CommentResponse) commentCursor {
+		return commentCursor{ID: c.ID}
+	}), nil
+}
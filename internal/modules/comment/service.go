@@ -0,0 +1,322 @@
+package comment
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"strings"
+
+	"github.com/rxmy43/support-platform/internal/apperror"
+	"github.com/rxmy43/support-platform/internal/modules/post"
+	"github.com/rxmy43/support-platform/internal/pagination"
+	"github.com/rxmy43/support-platform/internal/socket"
+)
+
+type CommentService struct {
+	commentRepo *CommentRepo
+	postRepo    *post.PostRepo
+	hub         *socket.Hub
+}
+
+func NewCommentService(commentRepo *CommentRepo, postRepo *post.PostRepo, hub *socket.Hub) *CommentService {
+	return &CommentService{
+		commentRepo: commentRepo,
+		postRepo:    postRepo,
+		hub:         hub,
+	}
+}
+
+const maxCommentLength = 1000
+
+func validateText(text string) []apperror.FieldError {
+	var fieldErrs []apperror.FieldError
+
+	if strings.TrimSpace(text) == "" {
+		fieldErrs = append(fieldErrs, apperror.NewFieldError("text", apperror.CodeFieldRequired))
+	} else if len([]rune(text)) > maxCommentLength {
+		fieldErrs = append(fieldErrs, apperror.NewFieldError("text", apperror.CodeFieldTooLong))
+	}
+
+	return fieldErrs
+}
+
+// findPost loads a post the user can see, comments of posts that are not
+// published, were taken down or are hidden from the user are not found.
+func (s *CommentService) findPost(ctx context.Context, postID, userID uint) (*post.Post, *apperror.AppError) {
+	p, err := s.postRepo.FindVisible(ctx, postID, userID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, apperror.NotFound("post not found", apperror.CodeResourceNotFound)
+		}
+		return nil, apperror.InternalServer("failed find post by id").WithCause(err)
+	}
+	return p, nil
+}
+
+func (s *CommentService) findComment(ctx context.Context, commentID uint) (*Comment, *apperror.AppError) {
+	c, err := s.commentRepo.FindByID(ctx, commentID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, apperror.NotFound("comment not found", apperror.CodeResourceNotFound)
+		}
+		return nil, apperror.InternalServer("failed find comment by id").WithCause(err)
+	}
+	return c, nil
+}
+
+// checkParent tells whether a reply can be added under the parent comment.
+// Parents of another post and replies are invalid selections, parents that
+// were taken down are not found and hidden parents are closed for replies.
+func checkParent(parent *Comment, postID uint) ([]apperror.FieldError, *apperror.AppError) {
+	switch {
+	case parent.PostID != postID:
+		return []apperror.FieldError{apperror.NewFieldError("parent_id", apperror.CodeSelectionInvalid)}, nil
+	case parent.TakenDownAt != nil:
+		return nil, apperror.NotFound("parent comment not found", apperror.CodeResourceNotFound)
+	case parent.IsHidden:
+		return nil, apperror.Conflict("cannot reply to a hidden comment", apperror.CodeSelectionInvalid)
+	case parent.ParentID != nil:
+		// only one level of replies is allowed
+		return []apperror.FieldError{apperror.NewFieldError("parent_id", apperror.CodeSelectionInvalid).WithMessage("cannot reply to a reply")}, nil
+	}
+	return nil, nil
+}
+
+// checkTakenDown rejects changes to a comment that was taken down by an admin.
+func checkTakenDown(c *Comment) *apperror.AppError {
+	if c.TakenDownAt != nil {
+		return apperror.Conflict("comment was taken down", apperror.CodeContentRejected)
+	}
+	return nil
+}
+
+func (s *CommentService) Create(ctx context.Context, postID, userID uint, req CommentCreateRequest) (*CommentResponse, *apperror.AppError) {
+	p, appErr := s.findPost(ctx, postID, userID)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	fieldErrs := validateText(req.Text)
+
+	if req.ParentID != nil {
+		parent, err := s.commentRepo.FindByID(ctx, *req.ParentID)
+		switch {
+		case err == sql.ErrNoRows:
+			fieldErrs = append(fieldErrs, apperror.NewFieldError("parent_id", apperror.CodeResourceNotFound))
+		case err != nil:
+			return nil, apperror.InternalServer("failed find parent comment").WithCause(err)
+		default:
+			parentErrs, appErr := checkParent(parent, postID)
+			if appErr != nil {
+				return nil, appErr
+			}
+			fieldErrs = append(fieldErrs, parentErrs...)
+		}
+	}
+
+	if len(fieldErrs) > 0 {
+		return nil, apperror.ValidationError("create comment validation error", fieldErrs)
+	}
+
+	newComment := &Comment{
+		PostID:   postID,
+		UserID:   userID,
+		ParentID: req.ParentID,
+		Text:     strings.TrimSpace(req.Text),
+	}
+
+	if err := s.commentRepo.Insert(ctx, newComment); err != nil {
+		return nil, apperror.InternalServer("failed when creating new comment").WithCause(err)
+	}
+
+	created, err := s.commentRepo.GetComment(ctx, newComment.ID)
+	if err != nil {
+		return nil, apperror.InternalServer("failed get created comment").WithCause(err)
+	}
+
+	if p.CreatorID != userID {
+		err := s.hub.SendToCreator(ctx, p.CreatorID, socket.EventMessage{
+			Event: "comment_created",
+			Data: map[string]interface{}{
+				"comment_id":   created.ID,
+				"post_id":      created.PostID,
+				"parent_id":    created.ParentID,
+				"user_id":      created.UserID,
+				"user_name":    created.UserName,
+				"text":         created.Text,
+				"is_supporter": created.IsSupporter,
+			},
+		})
+		if err != nil {
+			log.Println("failed sending comment_created:", err)
+		}
+	}
+
+	return created, nil
+}
+
+func (s *CommentService) Update(ctx context.Context, commentID, userID uint, req CommentUpdateRequest) (*CommentResponse, *apperror.AppError) {
+	c, appErr := s.findComment(ctx, commentID)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	if c.UserID != userID {
+		return nil, apperror.Forbidden("only the author can edit this comment", apperror.CodeUnauthorizedOperation)
+	}
+
+	if appErr := checkTakenDown(c); appErr != nil {
+		return nil, appErr
+	}
+
+	if _, appErr := s.findPost(ctx, c.PostID, userID); appErr != nil {
+		return nil, appErr
+	}
+
+	if fieldErrs := validateText(req.Text); len(fieldErrs) > 0 {
+		return nil, apperror.ValidationError("update comment validation error", fieldErrs)
+	}
+
+	if err := s.commentRepo.UpdateText(ctx, c.ID, strings.TrimSpace(req.Text)); err != nil {
+		return nil, apperror.InternalServer("failed updating comment").WithCause(err)
+	}
+
+	updated, err := s.commentRepo.GetComment(ctx, c.ID)
+	if err != nil {
+		return nil, apperror.InternalServer("failed get updated comment").WithCause(err)
+	}
+
+	return updated, nil
+}
+
+// Delete removes a comment and its replies. Authors can delete their own
+// comments, the creator owning the post can delete any of them.
+func (s *CommentService) Delete(ctx context.Context, commentID, userID uint) *apperror.AppError {
+	c, appErr := s.findComment(ctx, commentID)
+	if appErr != nil {
+		return appErr
+	}
+
+	if c.UserID != userID {
+		p, appErr := s.findPost(ctx, c.PostID, userID)
+		if appErr != nil {
+			return appErr
+		}
+
+		if p.CreatorID != userID {
+			return apperror.Forbidden("you are not allowed to delete this comment", apperror.CodeUnauthorizedOperation)
+		}
+	}
+
+	if err := s.commentRepo.Delete(ctx, c.ID); err != nil {
+		return apperror.InternalServer("failed deleting comment").WithCause(err)
+	}
+
+	return nil
+}
+
+// moderate loads a comment and makes sure the user is the creator owning the post.
+func (s *CommentService) moderate(ctx context.Context, commentID, userID uint) (*Comment, *apperror.AppError) {
+	c, appErr := s.findComment(ctx, commentID)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	p, appErr := s.findPost(ctx, c.PostID, userID)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	if p.CreatorID != userID {
+		return nil, apperror.Forbidden("only the post creator can moderate comments", apperror.CodeUnauthorizedOperation)
+	}
+
+	return c, nil
+}
+
+func (s *CommentService) SetHidden(ctx context.Context, commentID, userID uint, hidden bool) *apperror.AppError {
+	c, appErr := s.moderate(ctx, commentID, userID)
+	if appErr != nil {
+		return appErr
+	}
+
+	if err := s.commentRepo.SetHidden(ctx, c.ID, hidden); err != nil {
+		return apperror.InternalServer("failed updating comment visibility").WithCause(err)
+	}
+
+	return nil
+}
+
+func (s *CommentService) Pin(ctx context.Context, commentID, userID uint) *apperror.AppError {
+	c, appErr := s.moderate(ctx, commentID, userID)
+	if appErr != nil {
+		return appErr
+	}
+
+	if c.ParentID != nil {
+		return apperror.BadRequest("replies cannot be pinned", apperror.CodeSelectionInvalid)
+	}
+
+	if appErr := checkTakenDown(c); appErr != nil {
+		return appErr
+	}
+
+	if err := s.commentRepo.Pin(ctx, c.PostID, c.ID); err != nil {
+		return apperror.InternalServer("failed pinning comment").WithCause(err)
+	}
+
+	return nil
+}
+
+func (s *CommentService) Unpin(ctx context.Context, commentID, userID uint) *apperror.AppError {
+	c, appErr := s.moderate(ctx, commentID, userID)
+	if appErr != nil {
+		return appErr
+	}
+
+	if err := s.commentRepo.Unpin(ctx, c.ID); err != nil {
+		return apperror.InternalServer("failed unpinning comment").WithCause(err)
+	}
+
+	return nil
+}
+
+func (s *CommentService) FindAll(ctx context.Context, postID uint, params pagination.Params, viewerID uint) (pagination.Page[CommentResponse], *apperror.AppError) {
+	p, appErr := s.findPost(ctx, postID, viewerID)
+	if appErr != nil {
+		return pagination.Page[CommentResponse]{}, appErr
+	}
+
+	page, err := s.commentRepo.GetComments(ctx, p.ID, params, p.CreatorID == viewerID)
+	if err != nil {
+		if errors.Is(err, pagination.ErrInvalidCursor) {
+			return page, apperror.BadRequest("invalid cursor", apperror.CodeUnknown)
+		}
+		return page, apperror.InternalServer("failed get comments").WithCause(err)
+	}
+
+	return page, nil
+}
+
+func (s *CommentService) FindReplies(ctx context.Context, commentID uint, params pagination.Params, viewerID uint) (pagination.Page[CommentResponse], *apperror.AppError) {
+	c, appErr := s.findComment(ctx, commentID)
+	if appErr != nil {
+		return pagination.Page[CommentResponse]{}, appErr
+	}
+
+	p, appErr := s.findPost(ctx, c.PostID, viewerID)
+	if appErr != nil {
+		return pagination.Page[CommentResponse]{}, appErr
+	}
+
+	page, err := s.commentRepo.GetReplies(ctx, c.ID, params, p.CreatorID == viewerID)
+	if err != nil {
+		if errors.Is(err, pagination.ErrInvalidCursor) {
+			return page, apperror.BadRequest("invalid cursor", apperror.CodeUnknown)
+		}
+		return page, apperror.InternalServer("failed get replies").WithCause(err)
+	}
+
+	return page, nil
+}
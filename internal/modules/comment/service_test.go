@@ -0,0 +1,79 @@
+package comment
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/rxmy43/support-platform/internal/apperror"
+)
+
+func TestCheckParent(t *testing.T) {
+	parentID := uint(7)
+	takenDownAt := time.Now()
+
+	tests := []struct {
+		name       string
+		parent     Comment
+		postID     uint
+		wantStatus int
+		wantField  apperror.ErrorCode
+	}{
+		{"top level comment", Comment{PostID: 1}, 1, 0, ""},
+		{"comment of another post", Comment{PostID: 2}, 1, 0, apperror.CodeSelectionInvalid},
+		{"reply", Comment{PostID: 1, ParentID: &parentID}, 1, 0, apperror.CodeSelectionInvalid},
+		{"taken down", Comment{PostID: 1, TakenDownAt: &takenDownAt}, 1, http.StatusNotFound, ""},
+		{"hidden", Comment{PostID: 1, IsHidden: true}, 1, http.StatusConflict, ""},
+		{"hidden and taken down", Comment{PostID: 1, IsHidden: true, TakenDownAt: &takenDownAt}, 1, http.StatusNotFound, ""},
+		{"hidden comment of another post", Comment{PostID: 2, IsHidden: true}, 1, 0, apperror.CodeSelectionInvalid},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fieldErrs, appErr := checkParent(&tt.parent, tt.postID)
+
+			status := 0
+			if appErr != nil {
+				status = appErr.HttpStatus
+			}
+			if status != tt.wantStatus {
+				t.Fatalf("status is %d, want %d", status, tt.wantStatus)
+			}
+
+			var code apperror.ErrorCode
+			if len(fieldErrs) > 0 {
+				code = fieldErrs[0].Code
+			}
+			if code != tt.wantField {
+				t.Fatalf("field error is %q, want %q", code, tt.wantField)
+			}
+		})
+	}
+}
+
+func TestCheckTakenDown(t *testing.T) {
+	takenDownAt := time.Now()
+
+	tests := []struct {
+		name    string
+		comment Comment
+		wantErr bool
+	}{
+		{"visible", Comment{}, false},
+		{"hidden", Comment{IsHidden: true}, false},
+		{"pinned", Comment{IsPinned: true}, false},
+		{"taken down", Comment{TakenDownAt: &takenDownAt}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			appErr := checkTakenDown(&tt.comment)
+			if (appErr != nil) != tt.wantErr {
+				t.Fatalf("error is %v, want error %v", appErr, tt.wantErr)
+			}
+			if appErr != nil && appErr.HttpStatus != http.StatusConflict {
+				t.Fatalf("status is %d, want %d", appErr.HttpStatus, http.StatusConflict)
+			}
+		})
+	}
+}
@@ -0,0 +1,197 @@
+package feed
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/xml"
+	"fmt"
+	"mime"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/rxmy43/support-platform/internal/apperror"
+	"github.com/rxmy43/support-platform/internal/modules/post"
+	"github.com/rxmy43/support-platform/internal/modules/user"
+)
+
+type FeedService struct {
+	postRepo *post.PostRepo
+	userRepo *user.UserRepo
+	appURL   string
+	webURL   string
+}
+
+// NewFeedService serves the feeds from appURL and links their entries to the
+// frontend at webURL.
+func NewFeedService(postRepo *post.PostRepo, userRepo *user.UserRepo, appURL, webURL string) *FeedService {
+	return &FeedService{
+		postRepo: postRepo,
+		userRepo: userRepo,
+		appURL:   strings.TrimRight(appURL, "/"),
+		webURL:   strings.TrimRight(webURL, "/"),
+	}
+}
+
+const (
+	FormatRSS  = "rss"
+	FormatAtom = "atom"
+)
+
+var contentTypes = map[string]string{
+	FormatRSS:  "application/rss+xml; charset=utf-8",
+	FormatAtom: "application/atom+xml; charset=utf-8",
+}
+
+const (
+	feedSize       = 50
+	maxTitleLength = 80
+)
+
+// Document is a rendered feed ready to be served.
+type Document struct {
+	Body         []byte
+	ContentType  string
+	LastModified time.Time
+}
+
+// Render builds the feed of a creator's public posts. Supporter-only posts
+// never appear in feeds.
+func (s *FeedService) Render(ctx context.Context, handle, format string) (*Document, *apperror.AppError) {
+	creator, err := s.userRepo.FindCreatorByHandle(ctx, strings.ToLower(handle))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, apperror.NotFound("creator not found", apperror.CodeResourceNotFound)
+		}
+		return nil, apperror.InternalServer("failed find creator by handle").WithCause(err)
+	}
+
+	posts, err := s.postRepo.GetFeedPosts(ctx, creator.ID, feedSize)
+	if err != nil {
+		return nil, apperror.InternalServer("failed get feed posts").WithCause(err)
+	}
+
+	var lastModified time.Time
+	if len(posts) > 0 {
+		lastModified = posts[0].PublishedAt
+	}
+
+	var doc any
+	if format == FormatAtom {
+		doc = s.atom(creator, posts, lastModified)
+	} else {
+		doc = s.rss(creator, posts, lastModified)
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	enc := xml.NewEncoder(&buf)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return nil, apperror.InternalServer("failed encoding feed").WithCause(err)
+	}
+
+	return &Document{
+		Body:         buf.Bytes(),
+		ContentType:  contentTypes[format],
+		LastModified: lastModified,
+	}, nil
+}
+
+// postURL is the frontend page readers land on from a feed. It leaves the
+// handle out so entry ids survive a handle change.
+func (s *FeedService) postURL(p post.PostResponse) string {
+	return fmt.Sprintf("%s/posts/%d", s.webURL, p.ID)
+}
+
+func (s *FeedService) feedURL(creator *user.User, ext string) string {
+	return fmt.Sprintf("%s/creators/%s/feed.%s", s.appURL, creator.Handle, ext)
+}
+
+func (s *FeedService) creatorURL(creator *user.User) string {
+	return fmt.Sprintf("%s/creators/%s", s.webURL, creator.Handle)
+}
+
+// postTitle is the first line of the post, shortened to fit feed readers.
+func postTitle(text string) string {
+	title, _, _ := strings.Cut(strings.TrimSpace(text), "\n")
+	if r := []rune(title); len(r) > maxTitleLength {
+		title = string(r[:maxTitleLength-1]) + "…"
+	}
+	return title
+}
+
+func mediaType(mediaURL string) string {
+	if t := mime.TypeByExtension(strings.ToLower(path.Ext(mediaURL))); t != "" {
+		return t
+	}
+	return "application/octet-stream"
+}
+
+func (s *FeedService) rss(creator *user.User, posts []post.PostResponse, lastModified time.Time) *RSS {
+	channel := RSSChannel{
+		Title:       creator.Name,
+		Link:        s.creatorURL(creator),
+		Description: fmt.Sprintf("Latest public posts from %s", creator.Name),
+		AtomLink:    AtomLink{Href: s.feedURL(creator, "xml"), Rel: "self", Type: contentTypes[FormatRSS]},
+		Items:       []RSSItem{},
+	}
+	if !lastModified.IsZero() {
+		channel.LastBuildDate = lastModified.UTC().Format(time.RFC1123Z)
+	}
+
+	for _, p := range posts {
+		item := RSSItem{
+			Title:       postTitle(p.Text),
+			Link:        s.postURL(p),
+			Description: p.Text,
+			GUID:        RSSGUID{IsPermaLink: true, Value: s.postURL(p)},
+			PubDate:     p.PublishedAt.UTC().Format(time.RFC1123Z),
+		}
+		// the size of the upload is not stored, 0 is the accepted value for unknown
+		if p.MediaURL != "" {
+			item.Enclosure = &RSSEnclosure{URL: p.MediaURL, Length: 0, Type: mediaType(p.MediaURL)}
+		}
+		channel.Items = append(channel.Items, item)
+	}
+
+	return &RSS{Version: "2.0", AtomNS: "http://www.w3.org/2005/Atom", Channel: channel}
+}
+
+func (s *FeedService) atom(creator *user.User, posts []post.PostResponse, lastModified time.Time) *AtomFeed {
+	updated := lastModified
+	if updated.IsZero() {
+		updated = time.Unix(0, 0)
+	}
+
+	feed := &AtomFeed{
+		ID:      s.feedURL(creator, "atom"),
+		Title:   creator.Name,
+		Updated: updated.UTC().Format(time.RFC3339),
+		Links: []AtomLink{
+			{Href: s.feedURL(creator, "atom"), Rel: "self", Type: contentTypes[FormatAtom]},
+			{Href: s.creatorURL(creator), Rel: "alternate"},
+		},
+		Author:  AtomAuthor{Name: creator.Name},
+		Entries: []AtomEntry{},
+	}
+
+	for _, p := range posts {
+		published := p.PublishedAt.UTC().Format(time.RFC3339)
+		entry := AtomEntry{
+			ID:        s.postURL(p),
+			Title:     postTitle(p.Text),
+			Updated:   published,
+			Published: published,
+			Links:     []AtomLink{{Href: s.postURL(p), Rel: "alternate"}},
+			Content:   AtomContent{Type: "text", Value: p.Text},
+		}
+		if p.MediaURL != "" {
+			entry.Links = append(entry.Links, AtomLink{Href: p.MediaURL, Rel: "enclosure", Type: mediaType(p.MediaURL)})
+		}
+		feed.Entries = append(feed.Entries, entry)
+	}
+
+	return feed
+}
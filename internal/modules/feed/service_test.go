@@ -0,0 +1,43 @@
+package feed
+
+import (
+	"testing"
+	"time"
+
+	"github.com/rxmy43/support-platform/internal/modules/post"
+	"github.com/rxmy43/support-platform/internal/modules/user"
+)
+
+func TestFeedLinks(t *testing.T) {
+	s := NewFeedService(nil, nil, "https://api.example.com/", "https://example.com/")
+	creator := &user.User{ID: 1, Name: "Jane", Handle: "jane"}
+	posts := []post.PostResponse{{ID: 42, Text: "Hello", PublishedAt: time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)}}
+	published := posts[0].PublishedAt
+
+	rss := s.rss(creator, posts, published)
+	atom := s.atom(creator, posts, published)
+
+	tests := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{"rss channel link", rss.Channel.Link, "https://example.com/creators/jane"},
+		{"rss self link", rss.Channel.AtomLink.Href, "https://api.example.com/creators/jane/feed.xml"},
+		{"rss item link", rss.Channel.Items[0].Link, "https://example.com/posts/42"},
+		{"rss item guid", rss.Channel.Items[0].GUID.Value, "https://example.com/posts/42"},
+		{"atom id", atom.ID, "https://api.example.com/creators/jane/feed.atom"},
+		{"atom self link", atom.Links[0].Href, "https://api.example.com/creators/jane/feed.atom"},
+		{"atom alternate link", atom.Links[1].Href, "https://example.com/creators/jane"},
+		{"atom entry id", atom.Entries[0].ID, "https://example.com/posts/42"},
+		{"atom entry link", atom.Entries[0].Links[0].Href, "https://example.com/posts/42"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got != tt.want {
+				t.Fatalf("link is %q, want %q", tt.got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,30 @@
+package notification
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type Notification struct {
+	ID        uint            `db:"id"`
+	UserID    uint            `db:"user_id"`
+	EventID   *uint           `db:"event_id"`
+	Type      string          `db:"type"`
+	Title     string          `db:"title"`
+	Body      string          `db:"body"`
+	Data      json.RawMessage `db:"data"`
+	ReadAt    *time.Time      `db:"read_at"`
+	CreatedAt time.Time       `db:"created_at"`
+}
+
+const (
+	TypeSupportReceived  = "support_received"
+	TypeSupportSent      = "support_sent"
+	TypeContentTakenDown = "content_taken_down"
+)
+
+// JobSupportPaid notifies both sides of a paid support.
+const JobSupportPaid = "notification.support_paid"
+
+// JobContentTakenDown tells the owner of content that it was taken down.
+const JobContentTakenDown = "notification.content_taken_down"
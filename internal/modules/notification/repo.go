@@ -0,0 +1,111 @@
+package notification
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/rxmy43/support-platform/internal/pagination"
+	"github.com/rxmy43/support-platform/internal/repo"
+)
+
+type NotificationRepo struct {
+	*repo.BaseRepo[Notification]
+}
+
+func NewNotificationRepo(DB *sqlx.DB) *NotificationRepo {
+	return &NotificationRepo{
+		BaseRepo: &repo.BaseRepo[Notification]{
+			DB:        DB,
+			TableName: "notifications",
+		},
+	}
+}
+
+// Insert stores the notifications, skipping the ones already made for the
+// same event and user.
+func (r *NotificationRepo) Insert(ctx context.Context, notifications ...Notification) error {
+	tx, err := r.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO notifications (user_id, event_id, type, title, body, data)
+		VALUES (:user_id, :event_id, :type, :title, :body, :data)
+		ON CONFLICT (event_id, user_id) DO NOTHING
+	`
+	for _, n := range notifications {
+		if _, err := tx.NamedExecContext(ctx, query, n); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// FindSupportFan returns the fan who made a support, the support events
+// leave them out when the support is anonymous.
+func (r *NotificationRepo) FindSupportFan(ctx context.Context, supportID uint) (uint, error) {
+	var fanID uint
+	err := r.DB.GetContext(ctx, &fanID, "SELECT fan_id FROM supports WHERE id = $1", supportID)
+	return fanID, err
+}
+
+type notificationCursor struct {
+	ID uint `json:"id"`
+}
+
+// GetUserNotifications lists the notifications of a user, newest first.
+func (r *NotificationRepo) GetUserNotifications(ctx context.Context, params pagination.Params, userID uint, unreadOnly bool) (pagination.Page[NotificationResponse], error) {
+	notifications := []NotificationResponse{}
+
+	cursor, err := pagination.Decode[notificationCursor](params.Cursor)
+	if err != nil {
+		return pagination.Page[NotificationResponse]{}, err
+	}
+
+	where := "WHERE user_id = $1"
+	args := []any{userID}
+	if unreadOnly {
+		where += " AND read_at IS NULL"
+	}
+	if cursor != nil {
+		args = append(args, cursor.ID)
+		where += fmt.Sprintf(" AND id < $%d", len(args))
+	}
+
+	args = append(args, params.FetchLimit())
+	query := fmt.Sprintf(`
+		SELECT id, type, title, body, data, read_at, created_at
+		FROM notifications
+		%s
+		ORDER BY id DESC
+		LIMIT $%d
+	`, where, len(args))
+
+	if err := r.DB.SelectContext(ctx, &notifications, query, args...); err != nil {
+		return pagination.Page[NotificationResponse]{}, err
+	}
+
+	return pagination.NewPage(notifications, params.Limit, func(n NotificationResponse) notificationCursor {
+		return notificationCursor{ID: n.ID}
+	}), nil
+}
+
+// MarkRead marks a notification of the user read, it reports false when the
+// user has no such notification.
+func (r *NotificationRepo) MarkRead(ctx context.Context, id, userID uint) (bool, error) {
+	res, err := r.DB.ExecContext(ctx, "UPDATE notifications SET read_at = COALESCE(read_at, NOW()) WHERE id = $1 AND user_id = $2", id, userID)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected > 0, nil
+}
@@ -0,0 +1,116 @@
+package notification
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/rxmy43/support-platform/internal/apperror"
+	"github.com/rxmy43/support-platform/internal/modules/report"
+	"github.com/rxmy43/support-platform/internal/modules/support"
+	"github.com/rxmy43/support-platform/internal/outbox"
+	"github.com/rxmy43/support-platform/internal/pagination"
+)
+
+type NotificationService struct {
+	notificationRepo *NotificationRepo
+}
+
+func NewNotificationService(notificationRepo *NotificationRepo) *NotificationService {
+	return &NotificationService{
+		notificationRepo: notificationRepo,
+	}
+}
+
+func (s *NotificationService) FindAll(ctx context.Context, params pagination.Params, userID uint, unreadOnly bool) (pagination.Page[NotificationResponse], *apperror.AppError) {
+	page, err := s.notificationRepo.GetUserNotifications(ctx, params, userID, unreadOnly)
+	if err != nil {
+		if errors.Is(err, pagination.ErrInvalidCursor) {
+			return page, apperror.BadRequest("invalid cursor", apperror.CodeUnknown)
+		}
+		return page, apperror.InternalServer("failed get notifications").WithCause(err)
+	}
+
+	return page, nil
+}
+
+func (s *NotificationService) MarkRead(ctx context.Context, id, userID uint) *apperror.AppError {
+	updated, err := s.notificationRepo.MarkRead(ctx, id, userID)
+	if err != nil {
+		return apperror.InternalServer("failed marking notification read").WithCause(err)
+	}
+
+	if !updated {
+		return apperror.NotFound("notification not found", apperror.CodeResourceNotFound)
+	}
+
+	return nil
+}
+
+// HandleSupportPaid runs the JobSupportPaid jobs of support.EventSupportPaid.
+func (s *NotificationService) HandleSupportPaid(ctx context.Context, payload json.RawMessage) error {
+	var paid support.SupportPaidEvent
+	event, err := outbox.Decode(payload, &paid)
+	if err != nil {
+		return err
+	}
+
+	fanID, err := s.notificationRepo.FindSupportFan(ctx, paid.SupportID)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(map[string]any{"support_id": paid.SupportID, "amount": paid.Amount})
+	if err != nil {
+		return err
+	}
+
+	return s.notificationRepo.Insert(ctx,
+		Notification{
+			UserID:  paid.CreatorID,
+			EventID: &event.ID,
+			Type:    TypeSupportReceived,
+			Title:   "You received a support",
+			Body:    fmt.Sprintf("%s supported you with IDR %d", paid.FanName, paid.Amount),
+			Data:    data,
+		},
+		Notification{
+			UserID:  fanID,
+			EventID: &event.ID,
+			Type:    TypeSupportSent,
+			Title:   "Your support was received",
+			Body:    fmt.Sprintf("Your support of IDR %d to %s has been paid", paid.Amount, paid.CreatorName),
+			Data:    data,
+		},
+	)
+}
+
+// HandleContentTakenDown runs the JobContentTakenDown jobs of
+// report.EventContentTakenDown.
+func (s *NotificationService) HandleContentTakenDown(ctx context.Context, payload json.RawMessage) error {
+	var takenDown report.ContentTakenDownEvent
+	event, err := outbox.Decode(payload, &takenDown)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(takenDown)
+	if err != nil {
+		return err
+	}
+
+	body := fmt.Sprintf("Your %s was taken down after a report for %s", takenDown.TargetType, takenDown.Reason)
+	if takenDown.Note != "" {
+		body += ": " + takenDown.Note
+	}
+
+	return s.notificationRepo.Insert(ctx, Notification{
+		UserID:  event.UserID,
+		EventID: &event.ID,
+		Type:    TypeContentTakenDown,
+		Title:   "Your content was taken down",
+		Body:    body,
+		Data:    data,
+	})
+}
@@ -0,0 +1,251 @@
+package post
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/rxmy43/support-platform/internal/apperror"
+	"github.com/rxmy43/support-platform/internal/llm"
+	"github.com/rxmy43/support-platform/internal/modules/aiusage"
+)
+
+const (
+	defaultCaptionTone  = "friendly and engaging"
+	captionTemperature  = 0.85
+	maxCaptionVariants  = 5
+	maxCaptionDraftText = 2000
+	captionSystemPrompt = "You are a creative writing assistant for a content creator support platform."
+	captionPrompt       = `You are an AI assistant helping creators write short, emotionally engaging captions 
+for their posts on a creator support platform (like Patreon, Trakteer, or Saweria). 
+Use a %s tone and write in %s. The caption should sound natural, personal, and subtly encourage appreciation or donations.
+Output ONLY the caption text — no explanations, no quotes, no introductions, no phrases like "Here’s your caption."
+The caption must not exceed %d characters.`
+	imageDescriptionPrompt = "Describe this image in one or two sentences so a copywriter can write a caption for it. Mention the subject, mood and setting only."
+)
+
+var captionLanguages = map[string]string{
+	"id": "Indonesian",
+	"en": "English",
+}
+
+// captionLengths maps the requested length to the maximum caption size.
+var captionLengths = map[string]int{
+	"short":  100,
+	"medium": 250,
+	"long":   500,
+}
+
+// validateCaption fills in the defaults of a caption request and returns the
+// language name and maximum caption length it asks for.
+func validateCaption(req *CaptionRequest) (string, int, *apperror.AppError) {
+	if req.Tone == "" {
+		req.Tone = defaultCaptionTone
+	}
+	if req.Language == "" {
+		req.Language = "en"
+	}
+	if req.Length == "" {
+		req.Length = "long"
+	}
+	if req.Variants == 0 {
+		req.Variants = 1
+	}
+
+	var fieldErrs []apperror.FieldError
+
+	language, ok := captionLanguages[req.Language]
+	if !ok {
+		fieldErrs = append(fieldErrs, apperror.NewFieldError("language", apperror.CodeSelectionInvalid).WithExpect("id or en"))
+	}
+
+	maxChars, ok := captionLengths[req.Length]
+	if !ok {
+		fieldErrs = append(fieldErrs, apperror.NewFieldError("length", apperror.CodeSelectionInvalid).WithExpect("short, medium or long"))
+	}
+
+	if req.Variants < 1 || req.Variants > maxCaptionVariants {
+		fieldErrs = append(fieldErrs, apperror.NewFieldError("variants", apperror.CodeFieldOutOfRange).WithExpect(fmt.Sprintf("1-%d", maxCaptionVariants)))
+	}
+
+	if len([]rune(req.Text)) > maxCaptionDraftText {
+		fieldErrs = append(fieldErrs, apperror.NewFieldError("text", apperror.CodeFieldTooLong))
+	}
+
+	if req.ImageURL != "" {
+		if u, err := url.ParseRequestURI(req.ImageURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+			fieldErrs = append(fieldErrs, apperror.NewFieldError("image_url", apperror.CodeURLInvalid))
+		}
+	}
+
+	if len(fieldErrs) > 0 {
+		return "", 0, apperror.ValidationError("generate caption validation error", fieldErrs)
+	}
+
+	return language, maxChars, nil
+}
+
+// describeImage asks the vision model what the post image shows, it returns
+// nil when the request has no image.
+func (s *PostService) describeImage(ctx context.Context, call *aiusage.Call, imageURL string) (*string, error) {
+	if imageURL == "" {
+		return nil, nil
+	}
+
+	completion, err := s.llmClient.Chat(ctx, llm.ChatRequest{
+		Messages: []llm.Message{
+			{Role: "user", Content: imageDescriptionPrompt, ImageURLs: []string{imageURL}},
+		},
+		Vision: true,
+	})
+	call.AddUsage(completion.Usage)
+	if err != nil {
+		return nil, err
+	}
+
+	return &completion.Content, nil
+}
+
+func (s *PostService) GenerateCaption(ctx context.Context, creatorID uint, req CaptionRequest) (*CaptionResponse, *apperror.AppError) {
+	language, maxChars, appErr := validateCaption(&req)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	call, appErr := s.aiUsage.Begin(ctx, creatorID, aiusage.FeatureCaption)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	resp, err := s.generateCaptions(ctx, call, req, language, maxChars)
+	s.aiUsage.Finish(call, err)
+	if err != nil {
+		return nil, captionError(err)
+	}
+
+	return resp, nil
+}
+
+func (s *PostService) generateCaptions(ctx context.Context, call *aiusage.Call, req CaptionRequest, language string, maxChars int) (*CaptionResponse, error) {
+	imageDescription, err := s.describeImage(ctx, call, req.ImageURL)
+	if err != nil {
+		return nil, err
+	}
+
+	messages := captionMessages(req, language, maxChars, imageDescription)
+
+	// one request per variant, the sampling temperature keeps them apart
+	completions := make([]llm.Completion, req.Variants)
+	errs := make([]error, req.Variants)
+
+	var wg sync.WaitGroup
+	for i := range completions {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			variant := append(messages[:len(messages):len(messages)], llm.Message{
+				Role:    "user",
+				Content: fmt.Sprintf("Write suggestion %d of %d.", i+1, req.Variants),
+			})
+			completions[i], errs[i] = s.llmClient.Chat(ctx, llm.ChatRequest{
+				Messages:    variant,
+				Temperature: captionTemperature,
+			})
+		}(i)
+	}
+	wg.Wait()
+
+	captions := make([]string, len(completions))
+	for i, c := range completions {
+		call.AddUsage(c.Usage)
+		captions[i] = c.Content
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+
+	return &CaptionResponse{
+		Captions:         captions,
+		ImageDescription: imageDescription,
+	}, nil
+}
+
+// StreamCaption generates a single caption and hands it to onDelta piece by
+// piece. onStart runs once the request is validated and the image described,
+// before the first delta, so the caller can still answer validation errors
+// with a normal response.
+func (s *PostService) StreamCaption(ctx context.Context, creatorID uint, req CaptionRequest, onStart func(imageDescription *string) error, onDelta func(delta string) error) *apperror.AppError {
+	req.Variants = 1
+
+	language, maxChars, appErr := validateCaption(&req)
+	if appErr != nil {
+		return appErr
+	}
+
+	call, appErr := s.aiUsage.Begin(ctx, creatorID, aiusage.FeatureCaptionStream)
+	if appErr != nil {
+		return appErr
+	}
+
+	err := s.streamCaption(ctx, call, req, language, maxChars, onStart, onDelta)
+	s.aiUsage.Finish(call, err)
+	if err != nil {
+		return captionError(err)
+	}
+
+	return nil
+}
+
+func (s *PostService) streamCaption(ctx context.Context, call *aiusage.Call, req CaptionRequest, language string, maxChars int, onStart func(imageDescription *string) error, onDelta func(delta string) error) error {
+	imageDescription, err := s.describeImage(ctx, call, req.ImageURL)
+	if err != nil {
+		return err
+	}
+
+	if err := onStart(imageDescription); err != nil {
+		return err
+	}
+
+	usage, err := s.llmClient.ChatStream(ctx, llm.ChatRequest{
+		Messages:    captionMessages(req, language, maxChars, imageDescription),
+		Temperature: captionTemperature,
+	}, onDelta)
+	call.AddUsage(usage)
+
+	return err
+}
+
+func captionMessages(req CaptionRequest, language string, maxChars int, imageDescription *string) []llm.Message {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, captionPrompt, req.Tone, language, maxChars)
+
+	if draft := strings.TrimSpace(req.Text); draft != "" {
+		fmt.Fprintf(&sb, "\n\nThe creator's draft for this post:\n%s", draft)
+	}
+
+	if imageDescription != nil {
+		fmt.Fprintf(&sb, "\n\nThe post image shows: %s", *imageDescription)
+	}
+
+	return []llm.Message{
+		{Role: "system", Content: captionSystemPrompt},
+		{Role: "user", Content: sb.String()},
+	}
+}
+
+func captionError(err error) *apperror.AppError {
+	var apiErr *llm.APIError
+	if errors.As(err, &apiErr) {
+		return apperror.Wrap(apperror.CodeExternalAPIRequestFailed, apiErr.StatusCode, "LLM API error", errors.New(apiErr.Body))
+	}
+	return apperror.InternalServer("failed to generate caption").WithCause(err)
+}
+
+func (s *PostService) AIUsage(ctx context.Context, creatorID uint) (*aiusage.UsageResponse, *apperror.AppError) {
+	return s.aiUsage.Usage(ctx, creatorID)
+}
@@ -6,17 +6,137 @@ import (
 )
 
 type PostCreateRequest struct {
-	CreatorID uint
-	Text      string
-	File      multipart.File
-	Header    *multipart.FileHeader
+	CreatorID  uint
+	Text       string
+	Visibility string
+	File       multipart.File
+	Header     *multipart.FileHeader
+	Poll       *PollRequest
+	// PublishAt schedules the post, RFC3339, empty publishes it now.
+	PublishAt string
+}
+
+type PollRequest struct {
+	Question string
+	Options  []string
+	Mode     string
+	ClosesAt string
+}
+
+type VoteRequest struct {
+	OptionID uint `json:"option_id"`
+}
+
+type PostUpdateRequest struct {
+	Text       string `json:"text"`
+	Visibility string `json:"visibility"`
+}
+
+type ReactionRequest struct {
+	Reaction string `json:"reaction"`
+}
+
+type ReorderPinsRequest struct {
+	PostIDs []uint `json:"post_ids"`
 }
 
 type PostResponse struct {
-	ID          uint      `json:"id" db:"id"`
-	CreatorID   uint      `json:"creator_id" db:"creator_id"`
-	CreatorName string    `json:"creator_name" db:"creator_name"`
-	Text        string    `json:"text" db:"text"`
-	MediaURL    string    `json:"media_url" db:"media_url"`
-	PublishedAt time.Time `json:"published_at" db:"published_at"`
+	ID          uint           `json:"id" db:"id"`
+	CreatorID   uint           `json:"creator_id" db:"creator_id"`
+	CreatorName string         `json:"creator_name" db:"creator_name"`
+	Text        string         `json:"text" db:"text"`
+	MediaURL    string         `json:"media_url" db:"media_url"`
+	Visibility  string         `json:"visibility" db:"visibility"`
+	PinPosition *int           `json:"pin_position" db:"pin_position"`
+	PublishedAt time.Time      `json:"published_at" db:"published_at"`
+	Reactions   map[string]int `json:"reactions" db:"-"`
+	MyReaction  *string        `json:"my_reaction" db:"-"`
+	Poll        *PollResponse  `json:"poll" db:"-"`
+}
+
+type PollResponse struct {
+	ID          uint                 `json:"id" db:"id"`
+	PostID      uint                 `json:"-" db:"post_id"`
+	Question    string               `json:"question" db:"question"`
+	Mode        string               `json:"mode" db:"mode"`
+	ClosesAt    *time.Time           `json:"closes_at" db:"closes_at"`
+	IsClosed    bool                 `json:"is_closed" db:"is_closed"`
+	TotalVotes  int                  `json:"total_votes" db:"-"`
+	TotalWeight int64                `json:"total_weight" db:"-"`
+	Options     []PollOptionResponse `json:"options" db:"-"`
+	MyVote      *uint                `json:"my_vote" db:"-"`
+}
+
+type PollOptionResponse struct {
+	ID     uint   `json:"id" db:"id"`
+	PollID uint   `json:"-" db:"poll_id"`
+	Label  string `json:"label" db:"label"`
+	Votes  int    `json:"votes" db:"votes"`
+	Weight int64  `json:"weight" db:"weight"`
+}
+
+type pollVote struct {
+	PollID   uint `db:"poll_id"`
+	OptionID uint `db:"option_id"`
+}
+
+type reactionCount struct {
+	PostID   uint   `db:"post_id"`
+	Reaction string `db:"reaction"`
+	Total    int    `db:"total"`
+}
+
+type searchRow struct {
+	PostResponse
+	Rank float32 `db:"rank"`
+}
+
+type TrendingTag struct {
+	Tag   string `json:"tag" db:"tag"`
+	Total int    `json:"total" db:"total"`
+}
+
+type PostAnalyticsDay struct {
+	Day           string `json:"day" db:"day"`
+	Views         int    `json:"views" db:"views"`
+	UniqueViewers int    `json:"unique_viewers" db:"unique_viewers"`
+	Reactions     int    `json:"reactions" db:"reactions"`
+	Comments      int    `json:"comments" db:"comments"`
+	Supports      int    `json:"supports" db:"supports"`
+	SupportAmount int64  `json:"support_amount" db:"support_amount"`
+}
+
+type PostAnalyticsTotals struct {
+	Views         int   `json:"views"`
+	UniqueViewers int   `json:"unique_viewers"`
+	Reactions     int   `json:"reactions"`
+	Comments      int   `json:"comments"`
+	Supports      int   `json:"supports"`
+	SupportAmount int64 `json:"support_amount"`
+}
+
+type PostAnalyticsResponse struct {
+	PostID uint                `json:"post_id"`
+	Days   int                 `json:"days"`
+	Totals PostAnalyticsTotals `json:"totals"`
+	Daily  []PostAnalyticsDay  `json:"daily"`
+}
+
+type PendingPostResponse struct {
+	PostResponse
+	ModerationReason *string `json:"moderation_reason" db:"moderation_reason"`
+}
+
+type CaptionRequest struct {
+	Tone     string `json:"tone"`
+	Text     string `json:"text"`
+	Language string `json:"language"`
+	Length   string `json:"length"`
+	Variants int    `json:"variants"`
+	ImageURL string `json:"image_url"`
+}
+
+type CaptionResponse struct {
+	Captions         []string `json:"captions"`
+	ImageDescription *string  `json:"image_description"`
 }
@@ -3,9 +3,71 @@ package post
 import "time"
 
 type Post struct {
-	ID          uint      `db:"id"`
-	CreatorID   uint      `db:"creator_id"`
-	Text        string    `db:"text"`
-	MediaURL    string    `db:"media_url"`
-	PublishedAt time.Time `db:"published_at"`
+	ID               uint      `db:"id"`
+	CreatorID        uint      `db:"creator_id"`
+	Text             string    `db:"text"`
+	MediaURL         string    `db:"media_url"`
+	Visibility       string    `db:"visibility"`
+	PinPosition      *int      `db:"pin_position"`
+	Status           string    `db:"status"`
+	ModerationReason *string   `db:"moderation_reason"`
+	PublishedAt      time.Time `db:"published_at"`
 }
+
+const (
+	VisibilityPublic     = "public"
+	VisibilitySupporters = "supporters"
+)
+
+const (
+	StatusPublished = "published"
+	StatusPending   = "pending"
+	StatusTakenDown = "taken_down"
+	StatusScheduled = "scheduled"
+)
+
+// MaxPinnedPosts is how many posts a creator can keep at the top of their page.
+const MaxPinnedPosts = 3
+
+type PostReaction struct {
+	ID        uint      `db:"id"`
+	PostID    uint      `db:"post_id"`
+	UserID    uint      `db:"user_id"`
+	Reaction  string    `db:"reaction"`
+	CreatedAt time.Time `db:"created_at"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
+
+// Reactions is the fixed emoji set a user can react with. The keys are stored
+// in post_reactions.reaction, the values are what the frontend renders.
+var Reactions = map[string]string{
+	"love":  "❤️",
+	"fire":  "🔥",
+	"laugh": "😂",
+	"wow":   "😮",
+	"sad":   "😢",
+	"clap":  "👏",
+}
+
+type Poll struct {
+	ID        uint       `db:"id"`
+	PostID    uint       `db:"post_id"`
+	Question  string     `db:"question"`
+	Mode      string     `db:"mode"`
+	ClosesAt  *time.Time `db:"closes_at"`
+	CreatedAt time.Time  `db:"created_at"`
+	Options   []string   `db:"-"`
+}
+
+// Poll modes: anyone who can see the post votes once, only paying supporters
+// vote, or supporters vote with the total they paid the creator as weight.
+const (
+	PollModeOpen       = "open"
+	PollModeSupporters = "supporters"
+	PollModeWeighted   = "weighted"
+)
+
+const (
+	MinPollOptions = 2
+	MaxPollOptions = 6
+)
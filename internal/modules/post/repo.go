@@ -2,12 +2,22 @@ package post
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"time"
 
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/rxmy43/support-platform/internal/pagination"
 	"github.com/rxmy43/support-platform/internal/repo"
 )
 
+var (
+	ErrAlreadyPinned   = errors.New("post is already pinned")
+	ErrPinLimitReached = errors.New("pinned post limit reached")
+	ErrPinsMismatch    = errors.New("post ids do not match the pinned posts")
+)
+
 type PostRepo struct {
 	*repo.BaseRepo[Post]
 }
@@ -21,52 +31,889 @@ func NewPostRepo(DB *sqlx.DB) *PostRepo {
 	}
 }
 
-func (r *PostRepo) GetPosts(ctx context.Context, cursor, userID *uint) ([]PostResponse, *uint, error) {
-	posts := []PostResponse{}
-	var err error
+const postSelect = `
+	SELECT p.id, p.creator_id, u.name AS creator_name, p.text, p.media_url, p.visibility, p.pin_position, p.published_at
+	FROM posts p
+	JOIN users u ON u.id = p.creator_id
+`
+
+// visibleTo builds the condition listing published posts of creators that
+// were not taken down, hiding supporter-only posts from viewers who are
+// neither the creator nor one of their paying supporters.
+func visibleTo(viewerParam string) string {
+	return fmt.Sprintf(`p.status = 'published'
+	AND u.taken_down_at IS NULL
+	AND (
+		p.visibility = 'public'
+		OR p.creator_id = %[1]s
+		OR EXISTS (
+			SELECT 1 FROM supports s
+			WHERE s.creator_id = p.creator_id
+			AND s.fan_id = %[1]s
+			AND s.status = 'paid'
+		)
+	)`, viewerParam)
+}
 
-	queryBase := `
-		SELECT p.id, p.creator_id, u.name AS creator_name, p.text, p.media_url, p.published_at
+func (r *PostRepo) FindByID(ctx context.Context, id uint) (*Post, error) {
+	var p Post
+	query := `
+		SELECT id, creator_id, text, media_url, visibility, pin_position, status, moderation_reason, published_at
+		FROM posts
+		WHERE id = $1
+	`
+	if err := r.DB.GetContext(ctx, &p, query, id); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// FindVisible is FindByID for viewers, it returns sql.ErrNoRows when the post
+// is not published, was taken down or is hidden from the viewer.
+func (r *PostRepo) FindVisible(ctx context.Context, id, viewerID uint) (*Post, error) {
+	var p Post
+	query := `
+		SELECT p.id, p.creator_id, p.text, p.media_url, p.visibility, p.pin_position, p.status, p.moderation_reason, p.published_at
 		FROM posts p
 		JOIN users u ON u.id = p.creator_id
+		WHERE ` + visibleTo("$1") + `
+		AND p.id = $2
+	`
+	if err := r.DB.GetContext(ctx, &p, query, viewerID, id); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// Insert stores the post together with the hashtags found in its text and
+// its poll, if any.
+func (r *PostRepo) Insert(ctx context.Context, p *Post, tags []string, poll *Poll) error {
+	tx, err := r.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var publishAt *time.Time
+	if !p.PublishedAt.IsZero() {
+		publishAt = &p.PublishedAt
+	}
+
+	query := `
+		INSERT INTO posts (creator_id, text, media_url, visibility, status, moderation_reason, published_at)
+		VALUES ($1, $2, $3, $4, $5, $6, COALESCE($7, NOW()))
+		RETURNING id, published_at
+	`
+	err = tx.QueryRowxContext(ctx, query, p.CreatorID, p.Text, p.MediaURL, p.Visibility, p.Status, p.ModerationReason, publishAt).
+		Scan(&p.ID, &p.PublishedAt)
+	if err != nil {
+		return err
+	}
+
+	if err := insertTags(ctx, tx, p.ID, tags); err != nil {
+		return err
+	}
+
+	if poll != nil {
+		poll.PostID = p.ID
+		if err := insertPoll(ctx, tx, poll); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func insertPoll(ctx context.Context, tx *sqlx.Tx, poll *Poll) error {
+	query := `
+		INSERT INTO post_polls (post_id, question, mode, closes_at)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at
+	`
+	if err := tx.QueryRowxContext(ctx, query, poll.PostID, poll.Question, poll.Mode, poll.ClosesAt).Scan(&poll.ID, &poll.CreatedAt); err != nil {
+		return err
+	}
+
+	query = `
+		INSERT INTO poll_options (poll_id, position, label)
+		SELECT $1, o.position, o.label
+		FROM UNNEST($2::text[]) WITH ORDINALITY AS o(label, position)
+	`
+	_, err := tx.ExecContext(ctx, query, poll.ID, pq.Array(poll.Options))
+	return err
+}
+
+func insertTags(ctx context.Context, tx *sqlx.Tx, postID uint, tags []string) error {
+	if len(tags) == 0 {
+		return nil
+	}
+
+	query := `
+		INSERT INTO post_tags (post_id, tag)
+		SELECT $1, UNNEST($2::text[])
+		ON CONFLICT DO NOTHING
+	`
+	_, err := tx.ExecContext(ctx, query, postID, pq.Array(tags))
+	return err
+}
+
+// UpdateContent saves the edited text, visibility and moderation outcome of
+// a post and replaces its hashtags.
+func (r *PostRepo) UpdateContent(ctx context.Context, p *Post, tags []string) error {
+	tx, err := r.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		UPDATE posts
+		SET text = $2, visibility = $3, status = $4, moderation_reason = $5
+		WHERE id = $1
 	`
+	if _, err := tx.ExecContext(ctx, query, p.ID, p.Text, p.Visibility, p.Status, p.ModerationReason); err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM post_tags WHERE post_id = $1", p.ID); err != nil {
+		return err
+	}
+
+	if err := insertTags(ctx, tx, p.ID, tags); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// SetStatus moves a post from one status to another, it reports false when
+// the post was not in the expected status anymore.
+func (r *PostRepo) SetStatus(ctx context.Context, postID uint, from, to string) (bool, error) {
+	res, err := r.DB.ExecContext(ctx, "UPDATE posts SET status = $3 WHERE id = $1 AND status = $2", postID, from, to)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
 
+	return affected > 0, nil
+}
+
+// PublishScheduled publishes the scheduled posts whose time has come and
+// returns how many were published.
+func (r *PostRepo) PublishScheduled(ctx context.Context) (int64, error) {
+	res, err := r.DB.ExecContext(ctx, "UPDATE posts SET status = 'published' WHERE status = 'scheduled' AND published_at <= NOW()")
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
+
+type pendingCursor struct {
+	ID uint `json:"id"`
+}
+
+// GetPendingPosts lists posts waiting for a moderation review, oldest first.
+func (r *PostRepo) GetPendingPosts(ctx context.Context, params pagination.Params) (pagination.Page[PendingPostResponse], error) {
+	posts := []PendingPostResponse{}
+
+	cursor, err := pagination.Decode[pendingCursor](params.Cursor)
+	if err != nil {
+		return pagination.Page[PendingPostResponse]{}, err
+	}
+
+	where := "WHERE p.status = 'pending'"
 	args := []any{}
-	where := ""
+	if cursor != nil {
+		args = append(args, cursor.ID)
+		where += fmt.Sprintf(" AND p.id > $%d", len(args))
+	}
+
+	args = append(args, params.FetchLimit())
+	query := fmt.Sprintf(`
+		SELECT p.id, p.creator_id, u.name AS creator_name, p.text, p.media_url, p.visibility, p.pin_position, p.published_at,
+			p.moderation_reason
+		FROM posts p
+		JOIN users u ON u.id = p.creator_id
+		%s
+		ORDER BY p.id ASC
+		LIMIT $%d
+	`, where, len(args))
+
+	if err := r.DB.SelectContext(ctx, &posts, query, args...); err != nil {
+		return pagination.Page[PendingPostResponse]{}, err
+	}
 
-	// filter user
+	return pagination.NewPage(posts, params.Limit, func(p PendingPostResponse) pendingCursor {
+		return pendingCursor{ID: p.ID}
+	}), nil
+}
+
+type postCursor struct {
+	PublishedAt time.Time `json:"published_at"`
+	ID          uint      `json:"id"`
+}
+
+func (r *PostRepo) GetPosts(ctx context.Context, params pagination.Params, userID, viewerID *uint) (pagination.Page[PostResponse], error) {
+	posts := []PostResponse{}
+
+	cursor, err := pagination.Decode[postCursor](params.Cursor)
+	if err != nil {
+		return pagination.Page[PostResponse]{}, err
+	}
+
+	args := []any{viewerID}
+	where := "WHERE " + visibleTo("$1")
+
+	// filter user, their pinned posts are served by GetPinnedPosts
 	if userID != nil {
-		where = "WHERE u.id = $1"
 		args = append(args, *userID)
+		where += fmt.Sprintf(" AND u.id = $%d AND p.pin_position IS NULL", len(args))
 	}
 
 	// filter cursor
 	if cursor != nil {
-		if where == "" {
-			where = "WHERE p.id < $1"
-			args = append(args, *cursor)
-		} else {
-			where += " AND p.id < $2"
-			args = append(args, *cursor)
-		}
+		args = append(args, cursor.PublishedAt, cursor.ID)
+		where += fmt.Sprintf(" AND (p.published_at, p.id) < ($%d, $%d)", len(args)-1, len(args))
 	}
 
+	args = append(args, params.FetchLimit())
 	query := fmt.Sprintf(`
 		%s
 		%s
-		ORDER BY p.id DESC
-		LIMIT 10
-	`, queryBase, where)
+		ORDER BY p.published_at DESC, p.id DESC
+		LIMIT $%d
+	`, postSelect, where, len(args))
+
+	if err := r.DB.SelectContext(ctx, &posts, query, args...); err != nil {
+		return pagination.Page[PostResponse]{}, err
+	}
+
+	return pagination.NewPage(posts, params.Limit, func(p PostResponse) postCursor {
+		return postCursor{PublishedAt: p.PublishedAt, ID: p.ID}
+	}), nil
+}
+
+// GetFeedPosts lists the latest public posts of a creator for their feeds.
+func (r *PostRepo) GetFeedPosts(ctx context.Context, creatorID uint, limit int) ([]PostResponse, error) {
+	posts := []PostResponse{}
+
+	query := postSelect + `
+		WHERE p.creator_id = $1
+		AND p.status = 'published'
+		AND p.visibility = 'public'
+		ORDER BY p.published_at DESC, p.id DESC
+		LIMIT $2
+	`
+
+	err := r.DB.SelectContext(ctx, &posts, query, creatorID, limit)
+	return posts, err
+}
+
+func (r *PostRepo) GetPinnedPosts(ctx context.Context, creatorID uint, viewerID *uint) ([]PostResponse, error) {
+	posts := []PostResponse{}
+
+	query := postSelect + `
+		WHERE ` + visibleTo("$1") + `
+		AND p.creator_id = $2
+		AND p.pin_position IS NOT NULL
+		ORDER BY p.pin_position ASC
+	`
+
+	if err := r.DB.SelectContext(ctx, &posts, query, viewerID, creatorID); err != nil {
+		return nil, err
+	}
+
+	return posts, nil
+}
+
+// GetPost returns a single post if the viewer is allowed to see it.
+func (r *PostRepo) GetPost(ctx context.Context, postID uint, viewerID *uint) (*PostResponse, error) {
+	var p PostResponse
+
+	query := postSelect + `
+		WHERE ` + visibleTo("$1") + `
+		AND p.id = $2
+	`
+
+	if err := r.DB.GetContext(ctx, &p, query, viewerID, postID); err != nil {
+		return nil, err
+	}
+
+	return &p, nil
+}
+
+// PruneViewWindows deletes the view windows older than before, only the ones
+// of the current day are needed to count unique viewers.
+func (r *PostRepo) PruneViewWindows(ctx context.Context, before time.Time) (int64, error) {
+	res, err := r.DB.ExecContext(ctx, "DELETE FROM post_view_windows WHERE window_start < $1", before)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
+
+// RecordViews counts a view of each post, at most once per viewer per hour,
+// into the post_daily_stats rollup.
+func (r *PostRepo) RecordViews(ctx context.Context, postIDs []uint, viewerID uint) error {
+	if len(postIDs) == 0 {
+		return nil
+	}
+
+	ids := make([]int64, len(postIDs))
+	for i, id := range postIDs {
+		ids[i] = int64(id)
+	}
+
+	query := `
+		WITH inserted AS (
+			INSERT INTO post_view_windows (post_id, viewer_id, window_start)
+			SELECT UNNEST($1::bigint[]), $2, DATE_TRUNC('hour', NOW())
+			ON CONFLICT DO NOTHING
+			RETURNING post_id
+		)
+		INSERT INTO post_daily_stats (post_id, day, views, unique_viewers)
+		SELECT
+			i.post_id,
+			CURRENT_DATE,
+			1,
+			CASE WHEN EXISTS (
+				SELECT 1 FROM post_view_windows w
+				WHERE w.post_id = i.post_id
+				AND w.viewer_id = $2
+				AND w.window_start >= DATE_TRUNC('day', NOW())
+				AND w.window_start < DATE_TRUNC('hour', NOW())
+			) THEN 0 ELSE 1 END
+		FROM inserted i
+		ON CONFLICT (post_id, day) DO UPDATE
+		SET views = post_daily_stats.views + EXCLUDED.views,
+			unique_viewers = post_daily_stats.unique_viewers + EXCLUDED.unique_viewers
+	`
+
+	_, err := r.DB.ExecContext(ctx, query, pq.Array(ids), viewerID)
+	return err
+}
+
+// GetAnalytics returns the daily series of the last `days` days, today included.
+func (r *PostRepo) GetAnalytics(ctx context.Context, postID uint, days int) ([]PostAnalyticsDay, int, error) {
+	daily := []PostAnalyticsDay{}
+
+	query := `
+		SELECT
+			TO_CHAR(d.day, 'YYYY-MM-DD') AS day,
+			COALESCE(ps.views, 0) AS views,
+			COALESCE(ps.unique_viewers, 0) AS unique_viewers,
+			(
+				SELECT COUNT(*) FROM post_reactions r
+				WHERE r.post_id = $1 AND r.created_at::date = d.day
+			) AS reactions,
+			(
+				SELECT COUNT(*) FROM comments c
+				WHERE c.post_id = $1 AND c.created_at::date = d.day
+			) AS comments,
+			(
+				SELECT COUNT(*) FROM supports s
+				WHERE s.post_id = $1 AND s.status = 'paid' AND s.sent_at::date = d.day
+			) AS supports,
+			(
+				SELECT COALESCE(SUM(s.amount), 0)::bigint FROM supports s
+				WHERE s.post_id = $1 AND s.status = 'paid' AND s.sent_at::date = d.day
+			) AS support_amount
+		FROM GENERATE_SERIES(CURRENT_DATE - ($2::int - 1), CURRENT_DATE, INTERVAL '1 day') AS g(day)
+		CROSS JOIN LATERAL (SELECT g.day::date AS day) d
+		LEFT JOIN post_daily_stats ps ON ps.post_id = $1 AND ps.day = d.day
+		ORDER BY d.day ASC
+	`
+
+	if err := r.DB.SelectContext(ctx, &daily, query, postID, days); err != nil {
+		return nil, 0, err
+	}
+
+	// unique viewers of the whole range, summing the daily values would count
+	// a viewer coming back on another day twice
+	var uniqueViewers int
+	query = `
+		SELECT COUNT(DISTINCT viewer_id)
+		FROM post_view_windows
+		WHERE post_id = $1
+		AND window_start >= CURRENT_DATE - ($2::int - 1)
+	`
+	if err := r.DB.GetContext(ctx, &uniqueViewers, query, postID, days); err != nil {
+		return nil, 0, err
+	}
+
+	return daily, uniqueViewers, nil
+}
+
+// lockCreatorPins serializes pin changes of one creator for the rest of tx.
+func lockCreatorPins(ctx context.Context, tx *sqlx.Tx, creatorID uint) error {
+	_, err := tx.ExecContext(ctx, "SELECT id FROM users WHERE id = $1 FOR UPDATE", creatorID)
+	return err
+}
+
+// PinPost puts the post at the end of the creator's pinned posts.
+func (r *PostRepo) PinPost(ctx context.Context, creatorID, postID uint) error {
+	tx, err := r.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := lockCreatorPins(ctx, tx, creatorID); err != nil {
+		return err
+	}
+
+	var pinned []uint
+	if err := tx.SelectContext(ctx, &pinned, "SELECT id FROM posts WHERE creator_id = $1 AND pin_position IS NOT NULL", creatorID); err != nil {
+		return err
+	}
+
+	for _, id := range pinned {
+		if id == postID {
+			return ErrAlreadyPinned
+		}
+	}
+
+	if len(pinned) >= MaxPinnedPosts {
+		return ErrPinLimitReached
+	}
+
+	if _, err := tx.ExecContext(ctx, "UPDATE posts SET pin_position = $3 WHERE id = $1 AND creator_id = $2", postID, creatorID, len(pinned)+1); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// UnpinPost removes the post from the pinned posts and closes the gap it leaves.
+func (r *PostRepo) UnpinPost(ctx context.Context, creatorID, postID uint) (bool, error) {
+	tx, err := r.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	if err := lockCreatorPins(ctx, tx, creatorID); err != nil {
+		return false, err
+	}
+
+	res, err := tx.ExecContext(ctx, "UPDATE posts SET pin_position = NULL WHERE id = $1 AND creator_id = $2 AND pin_position IS NOT NULL", postID, creatorID)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	if affected == 0 {
+		return false, nil
+	}
+
+	query := `
+		UPDATE posts p
+		SET pin_position = ordered.position
+		FROM (
+			SELECT id, ROW_NUMBER() OVER (ORDER BY pin_position) AS position
+			FROM posts
+			WHERE creator_id = $1
+			AND pin_position IS NOT NULL
+		) ordered
+		WHERE p.id = ordered.id
+	`
+	if _, err := tx.ExecContext(ctx, query, creatorID); err != nil {
+		return false, err
+	}
+
+	return true, tx.Commit()
+}
+
+// ReorderPins sets the pin order to the given post ids, which must be exactly
+// the currently pinned posts of the creator.
+func (r *PostRepo) ReorderPins(ctx context.Context, creatorID uint, postIDs []uint) error {
+	tx, err := r.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := lockCreatorPins(ctx, tx, creatorID); err != nil {
+		return err
+	}
+
+	var pinned []uint
+	if err := tx.SelectContext(ctx, &pinned, "SELECT id FROM posts WHERE creator_id = $1 AND pin_position IS NOT NULL", creatorID); err != nil {
+		return err
+	}
+
+	if len(pinned) != len(postIDs) {
+		return ErrPinsMismatch
+	}
+
+	current := make(map[uint]bool, len(pinned))
+	for _, id := range pinned {
+		current[id] = true
+	}
+
+	ids := make([]int64, len(postIDs))
+	for i, id := range postIDs {
+		if !current[id] {
+			return ErrPinsMismatch
+		}
+		delete(current, id)
+		ids[i] = int64(id)
+	}
+
+	query := `
+		UPDATE posts p
+		SET pin_position = ordered.position
+		FROM UNNEST($2::bigint[]) WITH ORDINALITY AS ordered(id, position)
+		WHERE p.id = ordered.id
+		AND p.creator_id = $1
+	`
+	if _, err := tx.ExecContext(ctx, query, creatorID, pq.Array(ids)); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+type searchCursor struct {
+	Rank float32 `json:"rank"`
+	ID   uint    `json:"id"`
+}
+
+// SearchPosts returns posts matching the full-text query and/or hashtag,
+// best match first.
+func (r *PostRepo) SearchPosts(ctx context.Context, q, tag string, params pagination.Params, viewerID *uint) (pagination.Page[PostResponse], error) {
+	results := []searchRow{}
+
+	cursor, err := pagination.Decode[searchCursor](params.Cursor)
+	if err != nil {
+		return pagination.Page[PostResponse]{}, err
+	}
+
+	args := []any{viewerID, q}
+	where := "WHERE " + visibleTo("$1")
+
+	rank := "0::real"
+	if q != "" {
+		rank = "ts_rank_cd(p.search_vector, websearch_to_tsquery('simple', $2))"
+		where += " AND p.search_vector @@ websearch_to_tsquery('simple', $2)"
+	}
+
+	if tag != "" {
+		args = append(args, tag)
+		where += fmt.Sprintf(" AND EXISTS (SELECT 1 FROM post_tags t WHERE t.post_id = p.id AND t.tag = $%d)", len(args))
+	}
+
+	outerWhere := ""
+	if cursor != nil {
+		args = append(args, cursor.Rank, cursor.ID)
+		outerWhere = fmt.Sprintf("WHERE (rank, id) < ($%d::real, $%d)", len(args)-1, len(args))
+	}
+
+	args = append(args, params.FetchLimit())
+	query := fmt.Sprintf(`
+		SELECT * FROM (
+			SELECT p.id, p.creator_id, u.name AS creator_name, p.text, p.media_url, p.visibility, p.pin_position, p.published_at,
+				%s AS rank
+			FROM posts p
+			JOIN users u ON u.id = p.creator_id
+			%s
+		) ranked
+		%s
+		ORDER BY rank DESC, id DESC
+		LIMIT $%d
+	`, rank, where, outerWhere, len(args))
+
+	if err := r.DB.SelectContext(ctx, &results, query, args...); err != nil {
+		return pagination.Page[PostResponse]{}, err
+	}
+
+	page := pagination.NewPage(results, params.Limit, func(row searchRow) searchCursor {
+		return searchCursor{Rank: row.Rank, ID: row.ID}
+	})
+
+	posts := make([]PostResponse, len(page.Items))
+	for i, row := range page.Items {
+		posts[i] = row.PostResponse
+	}
 
-	err = r.DB.SelectContext(ctx, &posts, query, args...)
+	return pagination.Page[PostResponse]{
+		Items:      posts,
+		NextCursor: page.NextCursor,
+		HasMore:    page.HasMore,
+	}, nil
+}
+
+func (r *PostRepo) GetTrendingTags(ctx context.Context, since time.Time) ([]TrendingTag, error) {
+	tags := []TrendingTag{}
+
+	query := `
+		SELECT t.tag, COUNT(*) AS total
+		FROM post_tags t
+		JOIN posts p ON p.id = t.post_id
+		JOIN users u ON u.id = p.creator_id
+		WHERE p.visibility = 'public'
+		AND p.status = 'published'
+		AND u.taken_down_at IS NULL
+		AND p.published_at >= $1
+		GROUP BY t.tag
+		ORDER BY total DESC, t.tag ASC
+		LIMIT 20
+	`
+
+	if err := r.DB.SelectContext(ctx, &tags, query, since); err != nil {
+		return nil, err
+	}
+
+	return tags, nil
+}
+
+func (r *PostRepo) AddReaction(ctx context.Context, postID, userID uint, reaction string) (bool, error) {
+	query := `
+		INSERT INTO post_reactions (post_id, user_id, reaction)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (post_id, user_id) DO NOTHING
+	`
+
+	res, err := r.DB.ExecContext(ctx, query, postID, userID, reaction)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := res.RowsAffected()
 	if err != nil {
-		return nil, nil, err
+		return false, err
 	}
 
-	var nextCursor *uint
-	if len(posts) > 0 {
-		nextCursor = &posts[len(posts)-1].ID
+	return affected > 0, nil
+}
+
+func (r *PostRepo) UpdateReaction(ctx context.Context, postID, userID uint, reaction string) (bool, error) {
+	query := `
+		UPDATE post_reactions
+		SET reaction = $3, updated_at = NOW()
+		WHERE post_id = $1
+		AND user_id = $2
+	`
+
+	res, err := r.DB.ExecContext(ctx, query, postID, userID, reaction)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected > 0, nil
+}
+
+func (r *PostRepo) DeleteReaction(ctx context.Context, postID, userID uint) (bool, error) {
+	query := `
+		DELETE FROM post_reactions
+		WHERE post_id = $1
+		AND user_id = $2
+	`
+
+	res, err := r.DB.ExecContext(ctx, query, postID, userID)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected > 0, nil
+}
+
+// AttachReactions fills the reaction counts and the viewer's own reaction for
+// a whole page of posts using two grouped queries instead of one per post.
+func (r *PostRepo) AttachReactions(ctx context.Context, posts []PostResponse, viewerID *uint) error {
+	if len(posts) == 0 {
+		return nil
+	}
+
+	ids := make([]int64, len(posts))
+	index := make(map[uint]int, len(posts))
+	for i := range posts {
+		ids[i] = int64(posts[i].ID)
+		index[posts[i].ID] = i
+		posts[i].Reactions = map[string]int{}
+	}
+
+	counts := []reactionCount{}
+	query := `
+		SELECT post_id, reaction, COUNT(*) AS total
+		FROM post_reactions
+		WHERE post_id = ANY($1)
+		GROUP BY post_id, reaction
+	`
+	if err := r.DB.SelectContext(ctx, &counts, query, pq.Array(ids)); err != nil {
+		return err
+	}
+
+	for _, c := range counts {
+		posts[index[c.PostID]].Reactions[c.Reaction] = c.Total
+	}
+
+	if viewerID == nil {
+		return nil
+	}
+
+	mine := []reactionCount{}
+	query = `
+		SELECT post_id, reaction
+		FROM post_reactions
+		WHERE post_id = ANY($1)
+		AND user_id = $2
+	`
+	if err := r.DB.SelectContext(ctx, &mine, query, pq.Array(ids), *viewerID); err != nil {
+		return err
+	}
+
+	for _, m := range mine {
+		reaction := m.Reaction
+		posts[index[m.PostID]].MyReaction = &reaction
+	}
+
+	return nil
+}
+
+// AttachPolls loads the poll results of the posts that have one, with the
+// option the viewer voted for.
+func (r *PostRepo) AttachPolls(ctx context.Context, posts []PostResponse, viewerID *uint) error {
+	if len(posts) == 0 {
+		return nil
+	}
+
+	ids := make([]int64, len(posts))
+	for i := range posts {
+		ids[i] = int64(posts[i].ID)
+	}
+
+	polls := []PollResponse{}
+	query := `
+		SELECT id, post_id, question, mode, closes_at, COALESCE(closes_at <= NOW(), FALSE) AS is_closed
+		FROM post_polls
+		WHERE post_id = ANY($1)
+	`
+	if err := r.DB.SelectContext(ctx, &polls, query, pq.Array(ids)); err != nil {
+		return err
+	}
+
+	if len(polls) == 0 {
+		return nil
+	}
+
+	pollIDs := make([]int64, len(polls))
+	index := make(map[uint]*PollResponse, len(polls))
+	for i := range polls {
+		pollIDs[i] = int64(polls[i].ID)
+		polls[i].Options = []PollOptionResponse{}
+		index[polls[i].ID] = &polls[i]
+	}
+
+	options := []PollOptionResponse{}
+	query = `
+		SELECT o.id, o.poll_id, o.label, COUNT(v.id) AS votes, COALESCE(SUM(v.weight), 0) AS weight
+		FROM poll_options o
+		LEFT JOIN poll_votes v ON v.option_id = o.id
+		WHERE o.poll_id = ANY($1)
+		GROUP BY o.id
+		ORDER BY o.poll_id, o.position
+	`
+	if err := r.DB.SelectContext(ctx, &options, query, pq.Array(pollIDs)); err != nil {
+		return err
+	}
+
+	for _, o := range options {
+		poll := index[o.PollID]
+		poll.Options = append(poll.Options, o)
+		poll.TotalVotes += o.Votes
+		poll.TotalWeight += o.Weight
+	}
+
+	if viewerID != nil {
+		votes := []pollVote{}
+		query = "SELECT poll_id, option_id FROM poll_votes WHERE poll_id = ANY($1) AND user_id = $2"
+		if err := r.DB.SelectContext(ctx, &votes, query, pq.Array(pollIDs), *viewerID); err != nil {
+			return err
+		}
+
+		for _, v := range votes {
+			optionID := v.OptionID
+			index[v.PollID].MyVote = &optionID
+		}
+	}
+
+	byPost := make(map[uint]*PollResponse, len(polls))
+	for i := range polls {
+		byPost[polls[i].PostID] = &polls[i]
+	}
+
+	for i := range posts {
+		posts[i].Poll = byPost[posts[i].ID]
+	}
+
+	return nil
+}
+
+// GetPostPoll returns the poll of a post, sql.ErrNoRows when it has none.
+func (r *PostRepo) GetPostPoll(ctx context.Context, postID uint) (*Poll, error) {
+	var poll Poll
+	err := r.DB.GetContext(ctx, &poll, "SELECT * FROM post_polls WHERE post_id = $1", postID)
+	if err != nil {
+		return nil, err
+	}
+	return &poll, nil
+}
+
+func (r *PostRepo) PollOptionExists(ctx context.Context, pollID, optionID uint) (bool, error) {
+	var exists bool
+	err := r.DB.GetContext(ctx, &exists, "SELECT EXISTS (SELECT 1 FROM poll_options WHERE id = $1 AND poll_id = $2)", optionID, pollID)
+	return exists, err
+}
+
+// PaidSupportTotal is how much a fan paid a creator so far.
+func (r *PostRepo) PaidSupportTotal(ctx context.Context, creatorID, fanID uint) (int64, error) {
+	var total int64
+	query := `
+		SELECT COALESCE(FLOOR(SUM(amount)), 0)::BIGINT
+		FROM supports
+		WHERE creator_id = $1 AND fan_id = $2 AND status = 'paid'
+	`
+	err := r.DB.GetContext(ctx, &total, query, creatorID, fanID)
+	return total, err
+}
+
+// Vote stores the user's vote, it reports false when they already voted.
+func (r *PostRepo) Vote(ctx context.Context, pollID, optionID, userID uint, weight int64) (bool, error) {
+	query := `
+		INSERT INTO poll_votes (poll_id, option_id, user_id, weight)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (poll_id, user_id) DO NOTHING
+	`
+	res, err := r.DB.ExecContext(ctx, query, pollID, optionID, userID, weight)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
 	}
 
-	return posts, nextCursor, nil
+	return affected > 0, nil
 }
@@ -1,56 +1,193 @@
 package post
 
 import (
-	"bytes"
 	"context"
 	"database/sql"
-	"encoding/json"
 	"errors"
 	"fmt"
-	"io"
-	"net/http"
+	"log"
 	"path/filepath"
+	"regexp"
 	"strings"
 	"time"
 
 	"github.com/rxmy43/support-platform/internal/apperror"
-	"github.com/rxmy43/support-platform/internal/config"
 	"github.com/rxmy43/support-platform/internal/helper"
+	"github.com/rxmy43/support-platform/internal/llm"
+	"github.com/rxmy43/support-platform/internal/moderation"
+	"github.com/rxmy43/support-platform/internal/modules/aiusage"
 	"github.com/rxmy43/support-platform/internal/modules/user"
+	"github.com/rxmy43/support-platform/internal/pagination"
 )
 
 type PostService struct {
-	postRepo *PostRepo
-	userRepo *user.UserRepo
+	postRepo  *PostRepo
+	userRepo  *user.UserRepo
+	moderator moderation.ContentModerator
+	llmClient llm.Client
+	aiUsage   *aiusage.AIUsageService
+	// viewWrites holds a slot for every view write in flight
+	viewWrites chan struct{}
 }
 
-func NewPostService(postRepo *PostRepo, userRepo *user.UserRepo) *PostService {
+func NewPostService(postRepo *PostRepo, userRepo *user.UserRepo, moderator moderation.ContentModerator, llmClient llm.Client, aiUsage *aiusage.AIUsageService) *PostService {
 	return &PostService{
-		postRepo: postRepo,
-		userRepo: userRepo,
+		postRepo:   postRepo,
+		userRepo:   userRepo,
+		moderator:  moderator,
+		llmClient:  llmClient,
+		aiUsage:    aiUsage,
+		viewWrites: make(chan struct{}, maxViewWrites),
 	}
 }
 
 const maxFileSize = 5 * 1024 * 1024
 
+var hashtagPattern = regexp.MustCompile(`#([\p{L}\p{N}_]{1,50})`)
+
+// extractHashtags returns the unique, lowercased hashtags of a post text.
+func extractHashtags(text string) []string {
+	seen := map[string]bool{}
+	tags := []string{}
+
+	for _, m := range hashtagPattern.FindAllStringSubmatch(text, -1) {
+		tag := strings.ToLower(m[1])
+		if !seen[tag] {
+			seen[tag] = true
+			tags = append(tags, tag)
+		}
+	}
+
+	return tags
+}
+
+func normalizeTag(tag string) string {
+	return strings.ToLower(strings.TrimPrefix(strings.TrimSpace(tag), "#"))
+}
+
 var allowedExts = map[string]bool{
 	".jpg":  true,
 	".png":  true,
 	".jpeg": true,
 }
 
-func (s *PostService) Create(ctx context.Context, req PostCreateRequest) *apperror.AppError {
+// moderate runs the post content through the content moderator and returns
+// the status the post should be saved with. Rejected content is returned as a
+// validation error.
+func (s *PostService) moderate(ctx context.Context, content []moderation.Content, op string) (string, *string, *apperror.AppError) {
+	res, err := s.moderator.Moderate(ctx, content)
+	if err != nil {
+		return "", nil, apperror.InternalServer("failed moderating post").WithCause(err)
+	}
+
+	switch res.Decision {
+	case moderation.Reject:
+		return "", nil, apperror.ValidationError(op+" post validation error", res.FieldErrors)
+	case moderation.Flag:
+		reason := res.Reason
+		return StatusPending, &reason, nil
+	}
+
+	return StatusPublished, nil, nil
+}
+
+const (
+	maxPollQuestionLength = 200
+	maxPollOptionLength   = 100
+)
+
+var pollModes = map[string]bool{
+	PollModeOpen:       true,
+	PollModeSupporters: true,
+	PollModeWeighted:   true,
+}
+
+// validatePoll turns the poll fields of a create request into a Poll.
+func validatePoll(req *PollRequest) (*Poll, []apperror.FieldError) {
+	var fieldErrs []apperror.FieldError
+
+	poll := &Poll{
+		Question: strings.TrimSpace(req.Question),
+		Mode:     req.Mode,
+	}
+
+	if poll.Question == "" {
+		fieldErrs = append(fieldErrs, apperror.NewFieldError("poll_question", apperror.CodeFieldRequired))
+	} else if len([]rune(poll.Question)) > maxPollQuestionLength {
+		fieldErrs = append(fieldErrs, apperror.NewFieldError("poll_question", apperror.CodeFieldTooLong))
+	}
+
+	for _, o := range req.Options {
+		o = strings.TrimSpace(o)
+		if o == "" {
+			fieldErrs = append(fieldErrs, apperror.NewFieldError("poll_options", apperror.CodeFieldRequired).WithMessage("poll options cannot be empty"))
+			break
+		}
+		if len([]rune(o)) > maxPollOptionLength {
+			fieldErrs = append(fieldErrs, apperror.NewFieldError("poll_options", apperror.CodeFieldTooLong))
+			break
+		}
+		poll.Options = append(poll.Options, o)
+	}
+
+	if len(req.Options) < MinPollOptions || len(req.Options) > MaxPollOptions {
+		fieldErrs = append(fieldErrs, apperror.NewFieldError("poll_options", apperror.CodeFieldOutOfRange).WithExpect(fmt.Sprintf("%d-%d options", MinPollOptions, MaxPollOptions)))
+	}
+
+	if poll.Mode == "" {
+		poll.Mode = PollModeOpen
+	}
+	if !pollModes[poll.Mode] {
+		fieldErrs = append(fieldErrs, apperror.NewFieldError("poll_mode", apperror.CodeSelectionInvalid).WithExpect("open, supporters or weighted"))
+	}
+
+	if req.ClosesAt != "" {
+		closesAt, err := time.Parse(time.RFC3339, req.ClosesAt)
+		switch {
+		case err != nil:
+			fieldErrs = append(fieldErrs, apperror.NewFieldError("poll_closes_at", apperror.CodeDateTimeInvalid).WithExpect("RFC3339"))
+		case !closesAt.After(time.Now()):
+			fieldErrs = append(fieldErrs, apperror.NewFieldError("poll_closes_at", apperror.CodeFieldOutOfRange).WithMessage("poll must close in the future"))
+		default:
+			poll.ClosesAt = &closesAt
+		}
+	}
+
+	return poll, fieldErrs
+}
+
+func pollContent(poll *Poll) []moderation.Content {
+	if poll == nil {
+		return nil
+	}
+
+	content := []moderation.Content{{Field: "poll_question", Text: poll.Question}}
+	for _, o := range poll.Options {
+		content = append(content, moderation.Content{Field: "poll_options", Text: o})
+	}
+	return content
+}
+
+func (s *PostService) Create(ctx context.Context, req PostCreateRequest) (*Post, *apperror.AppError) {
 	var fieldErrs []apperror.FieldError
 
 	if req.Text == "" {
 		fieldErrs = append(fieldErrs, apperror.NewFieldError("text", apperror.CodeFieldRequired))
 	}
 
+	if req.Visibility == "" {
+		req.Visibility = VisibilityPublic
+	}
+
+	if req.Visibility != VisibilityPublic && req.Visibility != VisibilitySupporters {
+		fieldErrs = append(fieldErrs, apperror.NewFieldError("visibility", apperror.CodeSelectionInvalid))
+	}
+
 	if _, err := s.userRepo.FindByID(ctx, req.CreatorID); err != nil {
 		if err == sql.ErrNoRows {
-			return apperror.Unauthorized("creator id not found", apperror.CodeUnauthorizedOperation)
+			return nil, apperror.Unauthorized("creator id not found", apperror.CodeUnauthorizedOperation)
 		}
-		return apperror.InternalServer("failed executing find user by creator id").WithCause(err)
+		return nil, apperror.InternalServer("failed executing find user by creator id").WithCause(err)
 	}
 
 	if req.Header.Size > maxFileSize {
@@ -62,110 +199,565 @@ func (s *PostService) Create(ctx context.Context, req PostCreateRequest) *apperr
 		fieldErrs = append(fieldErrs, apperror.NewFieldError("file", apperror.CodeFileTypeInvalid))
 	}
 
+	var poll *Poll
+	if req.Poll != nil {
+		var pollErrs []apperror.FieldError
+		poll, pollErrs = validatePoll(req.Poll)
+		fieldErrs = append(fieldErrs, pollErrs...)
+	}
+
+	var publishAt time.Time
+	if req.PublishAt != "" {
+		t, err := time.Parse(time.RFC3339, req.PublishAt)
+		switch {
+		case err != nil:
+			fieldErrs = append(fieldErrs, apperror.NewFieldError("publish_at", apperror.CodeDateTimeInvalid).WithExpect("RFC3339"))
+		case !t.After(time.Now()):
+			fieldErrs = append(fieldErrs, apperror.NewFieldError("publish_at", apperror.CodeDateInPast))
+		default:
+			publishAt = t
+		}
+	}
+
 	if len(fieldErrs) > 0 {
-		return apperror.ValidationError("create post validation error", fieldErrs)
+		return nil, apperror.ValidationError("create post validation error", fieldErrs)
+	}
+
+	content := append([]moderation.Content{{Field: "text", Text: req.Text}}, pollContent(poll)...)
+	status, reason, appErr := s.moderate(ctx, content, "create")
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	if status == StatusPublished && !publishAt.IsZero() {
+		status = StatusScheduled
 	}
 
 	secureURL, err := helper.SaveUploadedFile(req.File, req.Header)
 	if err != nil {
-		return apperror.InternalServer("failed when uploading file").WithCause(err)
+		return nil, apperror.InternalServer("failed when uploading file").WithCause(err)
 	}
 
 	newPost := &Post{
-		CreatorID: req.CreatorID,
-		Text:      req.Text,
-		MediaURL:  secureURL,
+		CreatorID:        req.CreatorID,
+		Text:             req.Text,
+		MediaURL:         secureURL,
+		Visibility:       req.Visibility,
+		Status:           status,
+		ModerationReason: reason,
+		PublishedAt:      publishAt,
+	}
+
+	if err := s.postRepo.Insert(ctx, newPost, extractHashtags(req.Text), poll); err != nil {
+		return nil, apperror.InternalServer("failed when creating new post").WithCause(err)
+	}
+
+	return newPost, nil
+}
+
+func (s *PostService) Update(ctx context.Context, postID, creatorID uint, req PostUpdateRequest) (*Post, *apperror.AppError) {
+	p, appErr := s.findOwnPost(ctx, postID, creatorID)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	if p.Status == StatusTakenDown {
+		return nil, apperror.Forbidden("post has been taken down", apperror.CodeUnauthorizedOperation)
+	}
+
+	var fieldErrs []apperror.FieldError
+
+	if req.Text == "" {
+		fieldErrs = append(fieldErrs, apperror.NewFieldError("text", apperror.CodeFieldRequired))
+	}
+
+	if req.Visibility == "" {
+		req.Visibility = p.Visibility
+	}
+
+	if req.Visibility != VisibilityPublic && req.Visibility != VisibilitySupporters {
+		fieldErrs = append(fieldErrs, apperror.NewFieldError("visibility", apperror.CodeSelectionInvalid))
+	}
+
+	if len(fieldErrs) > 0 {
+		return nil, apperror.ValidationError("update post validation error", fieldErrs)
+	}
+
+	status, reason, appErr := s.moderate(ctx, []moderation.Content{{Field: "text", Text: req.Text}}, "update")
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	// a post waiting for review stays in review, only a published or
+	// scheduled post can be published by an edit
+	if p.Status == StatusPending {
+		status = StatusPending
+		if reason == nil {
+			reason = p.ModerationReason
+		}
+	}
+
+	// editing a scheduled post does not publish it early
+	if status == StatusPublished && p.PublishedAt.After(time.Now()) {
+		status = StatusScheduled
+	}
+
+	p.Text = req.Text
+	p.Visibility = req.Visibility
+	p.Status = status
+	p.ModerationReason = reason
+
+	if err := s.postRepo.UpdateContent(ctx, p, extractHashtags(req.Text)); err != nil {
+		return nil, apperror.InternalServer("failed updating post").WithCause(err)
+	}
+
+	return p, nil
+}
+
+func (s *PostService) FindPending(ctx context.Context, params pagination.Params) (pagination.Page[PendingPostResponse], *apperror.AppError) {
+	page, err := s.postRepo.GetPendingPosts(ctx, params)
+	if err != nil {
+		if errors.Is(err, pagination.ErrInvalidCursor) {
+			return page, apperror.BadRequest("invalid cursor", apperror.CodeUnknown)
+		}
+		return page, apperror.InternalServer("failed get pending posts").WithCause(err)
+	}
+
+	return page, nil
+}
+
+// Review resolves a post flagged by moderation: approving publishes it,
+// rejecting takes it down.
+func (s *PostService) Review(ctx context.Context, postID uint, approve bool) *apperror.AppError {
+	status := StatusTakenDown
+	if approve {
+		p, err := s.postRepo.FindByID(ctx, postID)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return apperror.NotFound("pending post not found", apperror.CodeResourceNotFound)
+			}
+			return apperror.InternalServer("failed reviewing post").WithCause(err)
+		}
+
+		// an approved post that was scheduled still waits for its time
+		status = StatusPublished
+		if p.PublishedAt.After(time.Now()) {
+			status = StatusScheduled
+		}
+	}
+
+	updated, err := s.postRepo.SetStatus(ctx, postID, StatusPending, status)
+	if err != nil {
+		return apperror.InternalServer("failed reviewing post").WithCause(err)
+	}
+
+	if !updated {
+		return apperror.NotFound("pending post not found", apperror.CodeResourceNotFound)
+	}
+
+	return nil
+}
+
+func (s *PostService) FindAll(ctx context.Context, params pagination.Params, userID, viewerID *uint) (pagination.Page[PostResponse], *apperror.AppError) {
+	// a creator's own page starts with their pinned posts, they count in the
+	// page limit. The page still shows every pinned post and at least one
+	// other post when the limit is smaller, so the cursor can move on.
+	var pinned []PostResponse
+	postParams := params
+	if userID != nil && params.Cursor == "" {
+		var err error
+		pinned, err = s.postRepo.GetPinnedPosts(ctx, *userID, viewerID)
+		if err != nil {
+			return pagination.Page[PostResponse]{}, apperror.InternalServer("failed get pinned posts").WithCause(err)
+		}
+		postParams.Limit = max(params.Limit-len(pinned), 1)
+	}
+
+	page, err := s.postRepo.GetPosts(ctx, postParams, userID, viewerID)
+	if err != nil {
+		if errors.Is(err, pagination.ErrInvalidCursor) {
+			return page, apperror.BadRequest("invalid cursor", apperror.CodeUnknown)
+		}
+		return page, apperror.InternalServer("failed get all posts").WithCause(err)
+	}
+
+	if len(pinned) > 0 {
+		page.Items = append(pinned, page.Items...)
+	}
+
+	if appErr := s.attachDetails(ctx, page.Items, viewerID); appErr != nil {
+		return page, appErr
+	}
+
+	s.trackViews(page.Items, viewerID)
+
+	return page, nil
+}
+
+func (s *PostService) FindOne(ctx context.Context, postID uint, viewerID *uint) (*PostResponse, *apperror.AppError) {
+	p, err := s.postRepo.GetPost(ctx, postID, viewerID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, apperror.NotFound("post not found", apperror.CodeResourceNotFound)
+		}
+		return nil, apperror.InternalServer("failed get post").WithCause(err)
+	}
+
+	posts := []PostResponse{*p}
+	if appErr := s.attachDetails(ctx, posts, viewerID); appErr != nil {
+		return nil, appErr
 	}
 
-	if err := s.postRepo.Create(ctx, newPost); err != nil {
-		return apperror.InternalServer("failed when creating new post").WithCause(err)
+	s.trackViews(posts, viewerID)
+
+	return &posts[0], nil
+}
+
+// attachDetails loads the reactions and poll results shown with each post.
+func (s *PostService) attachDetails(ctx context.Context, posts []PostResponse, viewerID *uint) *apperror.AppError {
+	if err := s.postRepo.AttachReactions(ctx, posts, viewerID); err != nil {
+		return apperror.InternalServer("failed get post reactions").WithCause(err)
+	}
+
+	if err := s.postRepo.AttachPolls(ctx, posts, viewerID); err != nil {
+		return apperror.InternalServer("failed get post polls").WithCause(err)
 	}
 
 	return nil
 }
 
-func (s *PostService) GenerateCaption(ctx context.Context, tone string) (string, *apperror.AppError) {
-	cfg := config.Load()
-	apiKey := cfg.GroqAPIKey
+const (
+	// maxViewWrites caps the view writes running at once, views listed while
+	// all of them are busy are not counted
+	maxViewWrites = 16
+	viewsTimeout  = 5 * time.Second
+)
 
-	url := "https://api.groq.com/openai/v1/chat/completions"
+// trackViews records the views in the background so listing posts does not
+// wait on the analytics writes. Creators viewing their own posts are skipped.
+func (s *PostService) trackViews(posts []PostResponse, viewerID *uint) {
+	if viewerID == nil {
+		return
+	}
 
-	if tone == "" {
-		tone = "friendly and engaging"
+	ids := make([]uint, 0, len(posts))
+	for _, p := range posts {
+		if p.CreatorID != *viewerID {
+			ids = append(ids, p.ID)
+		}
 	}
 
-	prompt := fmt.Sprintf(
-		`You are an AI assistant helping creators write short, emotionally engaging captions 
-for their posts on a creator support platform (like Patreon, Trakteer, or Saweria). 
-Use a %s tone. The caption should sound natural, personal, and subtly encourage appreciation or donations.
-Output ONLY the caption text — no explanations, no quotes, no introductions, no phrases like "Here’s your caption."
-The caption must not exceed 500 characters.`,
-		tone,
-	)
+	if len(ids) == 0 {
+		return
+	}
 
-	payload := map[string]interface{}{
-		"model": "llama-3.3-70b-versatile",
-		"messages": []map[string]string{
-			{
-				"role":    "system",
-				"content": "You are a creative writing assistant for a content creator support platform.",
-			},
-			{
-				"role":    "user",
-				"content": prompt,
-			},
-		},
-		"temperature": 0.85,
+	select {
+	case s.viewWrites <- struct{}{}:
+	default:
+		log.Printf("dropped %d post views, too many view writes in flight", len(ids))
+		return
 	}
 
-	body, _ := json.Marshal(payload)
+	viewer := *viewerID
+	go func() {
+		defer func() { <-s.viewWrites }()
 
-	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(body))
+		ctx, cancel := context.WithTimeout(context.Background(), viewsTimeout)
+		defer cancel()
+
+		if err := s.postRepo.RecordViews(ctx, ids, viewer); err != nil {
+			log.Println("failed recording post views:", err)
+		}
+	}()
+}
+
+// MaxAnalyticsDays is the longest analytics range, view windows are kept
+// that long to count its unique viewers.
+const MaxAnalyticsDays = 90
+
+func (s *PostService) Analytics(ctx context.Context, postID, creatorID uint, days int) (*PostAnalyticsResponse, *apperror.AppError) {
+	if days < 1 || days > MaxAnalyticsDays {
+		return nil, apperror.ValidationError("post analytics validation error", []apperror.FieldError{
+			apperror.NewFieldError("days", apperror.CodeFieldOutOfRange).WithExpect(fmt.Sprintf("1-%d", MaxAnalyticsDays)),
+		})
+	}
+
+	p, appErr := s.findOwnPost(ctx, postID, creatorID)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	daily, uniqueViewers, err := s.postRepo.GetAnalytics(ctx, p.ID, days)
+	if err != nil {
+		return nil, apperror.InternalServer("failed get post analytics").WithCause(err)
+	}
+
+	totals := PostAnalyticsTotals{UniqueViewers: uniqueViewers}
+	for _, d := range daily {
+		totals.Views += d.Views
+		totals.Reactions += d.Reactions
+		totals.Comments += d.Comments
+		totals.Supports += d.Supports
+		totals.SupportAmount += d.SupportAmount
+	}
+
+	return &PostAnalyticsResponse{
+		PostID: p.ID,
+		Days:   days,
+		Totals: totals,
+		Daily:  daily,
+	}, nil
+}
+
+func (s *PostService) Search(ctx context.Context, q, tag string, params pagination.Params, viewerID *uint) (pagination.Page[PostResponse], *apperror.AppError) {
+	q = strings.TrimSpace(q)
+	tag = normalizeTag(tag)
+
+	if q == "" && tag == "" {
+		return pagination.Page[PostResponse]{}, apperror.ValidationError("search validation error", []apperror.FieldError{
+			apperror.NewFieldError("q", apperror.CodeFieldRequired).WithMessage("q or tag is required"),
+		})
+	}
+
+	page, err := s.postRepo.SearchPosts(ctx, q, tag, params, viewerID)
 	if err != nil {
-		return "", apperror.InternalServer("failed to connect to Groq API").WithCause(err)
+		if errors.Is(err, pagination.ErrInvalidCursor) {
+			return page, apperror.BadRequest("invalid cursor", apperror.CodeUnknown)
+		}
+		return page, apperror.InternalServer("failed searching posts").WithCause(err)
+	}
+
+	if appErr := s.attachDetails(ctx, page.Items, viewerID); appErr != nil {
+		return page, appErr
+	}
+
+	return page, nil
+}
+
+var trendingWindows = map[string]time.Duration{
+	"24h": 24 * time.Hour,
+	"7d":  7 * 24 * time.Hour,
+}
+
+func (s *PostService) TrendingTags(ctx context.Context, window string) ([]TrendingTag, *apperror.AppError) {
+	if window == "" {
+		window = "24h"
 	}
-	req.Header.Set("Authorization", "Bearer "+apiKey)
-	req.Header.Set("Content-Type", "application/json")
 
-	client := &http.Client{Timeout: 15 * time.Second}
-	resp, err := client.Do(req)
+	d, ok := trendingWindows[window]
+	if !ok {
+		return nil, apperror.ValidationError("trending tags validation error", []apperror.FieldError{
+			apperror.NewFieldError("window", apperror.CodeSelectionInvalid).WithExpect("24h or 7d"),
+		})
+	}
+
+	tags, err := s.postRepo.GetTrendingTags(ctx, time.Now().Add(-d))
 	if err != nil {
-		return "", apperror.InternalServer("failed to send request to Groq").WithCause(err)
+		return nil, apperror.InternalServer("failed get trending tags").WithCause(err)
 	}
-	defer resp.Body.Close()
 
-	resBody, err := io.ReadAll(resp.Body)
+	return tags, nil
+}
+
+func (s *PostService) findOwnPost(ctx context.Context, postID, creatorID uint) (*Post, *apperror.AppError) {
+	p, err := s.postRepo.FindByID(ctx, postID)
 	if err != nil {
-		return "", apperror.InternalServer("failed to read Groq response").WithCause(err)
+		if err == sql.ErrNoRows {
+			return nil, apperror.NotFound("post not found", apperror.CodeResourceNotFound)
+		}
+		return nil, apperror.InternalServer("failed find post by id").WithCause(err)
 	}
 
-	if resp.StatusCode != http.StatusOK {
-		return "", apperror.Wrap(apperror.CodeExternalAPIRequestFailed, resp.StatusCode, "Groq API error", errors.New(string(resBody)))
+	if p.CreatorID != creatorID {
+		return nil, apperror.Forbidden("you can only manage your own posts", apperror.CodeUnauthorizedOperation)
 	}
 
-	var result struct {
-		Choices []struct {
-			Message struct {
-				Content string `json:"content"`
-			} `json:"message"`
-		} `json:"choices"`
+	return p, nil
+}
+
+func (s *PostService) Pin(ctx context.Context, postID, creatorID uint) *apperror.AppError {
+	p, appErr := s.findOwnPost(ctx, postID, creatorID)
+	if appErr != nil {
+		return appErr
 	}
 
-	if err := json.Unmarshal(resBody, &result); err != nil {
-		return "", apperror.InternalServer("failed to parse Groq response").WithCause(err)
+	if p.Status == StatusTakenDown {
+		return apperror.Forbidden("post has been taken down", apperror.CodeUnauthorizedOperation)
 	}
 
-	if len(result.Choices) == 0 {
-		return "", apperror.InternalServer("failed generated caption").WithCause(err)
+	if err := s.postRepo.PinPost(ctx, creatorID, p.ID); err != nil {
+		switch {
+		case errors.Is(err, ErrAlreadyPinned):
+			return apperror.Conflict("post is already pinned", apperror.CodeItemAlreadyAdded)
+		case errors.Is(err, ErrPinLimitReached):
+			return apperror.Conflict(fmt.Sprintf("you can pin at most %d posts", MaxPinnedPosts), apperror.CodeFieldOutOfRange)
+		}
+		return apperror.InternalServer("failed pinning post").WithCause(err)
 	}
 
-	return strings.TrimSpace(result.Choices[0].Message.Content), nil
+	return nil
 }
 
-func (s *PostService) FindAll(ctx context.Context, cursor, userID *uint) ([]PostResponse, *uint, *apperror.AppError) {
-	posts, nextCursor, err := s.postRepo.GetPosts(ctx, cursor, userID)
+func (s *PostService) Unpin(ctx context.Context, postID, creatorID uint) *apperror.AppError {
+	p, appErr := s.findOwnPost(ctx, postID, creatorID)
+	if appErr != nil {
+		return appErr
+	}
+
+	unpinned, err := s.postRepo.UnpinPost(ctx, creatorID, p.ID)
 	if err != nil {
-		return []PostResponse{}, nil, apperror.InternalServer("failed get all posts").WithCause(err)
+		return apperror.InternalServer("failed unpinning post").WithCause(err)
+	}
+
+	if !unpinned {
+		return apperror.NotFound("post is not pinned", apperror.CodeResourceNotFound)
+	}
+
+	return nil
+}
+
+func (s *PostService) ReorderPins(ctx context.Context, creatorID uint, req ReorderPinsRequest) *apperror.AppError {
+	if err := s.postRepo.ReorderPins(ctx, creatorID, req.PostIDs); err != nil {
+		if errors.Is(err, ErrPinsMismatch) {
+			return apperror.ValidationError("reorder pins validation error", []apperror.FieldError{
+				apperror.NewFieldError("post_ids", apperror.CodeSelectionInvalid).WithMessage("post_ids must list every pinned post exactly once"),
+			})
+		}
+		return apperror.InternalServer("failed reordering pinned posts").WithCause(err)
+	}
+
+	return nil
+}
+
+func (s *PostService) validateReaction(ctx context.Context, postID, userID uint, reaction string) *apperror.AppError {
+	if _, err := s.postRepo.FindVisible(ctx, postID, userID); err != nil {
+		if err == sql.ErrNoRows {
+			return apperror.NotFound("post not found", apperror.CodeResourceNotFound)
+		}
+		return apperror.InternalServer("failed find post by id").WithCause(err)
+	}
+
+	if reaction == "" {
+		return apperror.ValidationError("reaction validation error", []apperror.FieldError{
+			apperror.NewFieldError("reaction", apperror.CodeFieldRequired),
+		})
 	}
-	return posts, nextCursor, nil
+
+	if _, ok := Reactions[reaction]; !ok {
+		return apperror.ValidationError("reaction validation error", []apperror.FieldError{
+			apperror.NewFieldError("reaction", apperror.CodeSelectionInvalid),
+		})
+	}
+
+	return nil
+}
+
+func (s *PostService) AddReaction(ctx context.Context, postID, userID uint, req ReactionRequest) *apperror.AppError {
+	if appErr := s.validateReaction(ctx, postID, userID, req.Reaction); appErr != nil {
+		return appErr
+	}
+
+	added, err := s.postRepo.AddReaction(ctx, postID, userID, req.Reaction)
+	if err != nil {
+		return apperror.InternalServer("failed adding reaction").WithCause(err)
+	}
+
+	if !added {
+		return apperror.Conflict("you already reacted to this post", apperror.CodeItemAlreadyAdded)
+	}
+
+	return nil
+}
+
+func (s *PostService) ChangeReaction(ctx context.Context, postID, userID uint, req ReactionRequest) *apperror.AppError {
+	if appErr := s.validateReaction(ctx, postID, userID, req.Reaction); appErr != nil {
+		return appErr
+	}
+
+	updated, err := s.postRepo.UpdateReaction(ctx, postID, userID, req.Reaction)
+	if err != nil {
+		return apperror.InternalServer("failed changing reaction").WithCause(err)
+	}
+
+	if !updated {
+		return apperror.NotFound("reaction not found", apperror.CodeResourceNotFound)
+	}
+
+	return nil
+}
+
+func (s *PostService) RemoveReaction(ctx context.Context, postID, userID uint) *apperror.AppError {
+	removed, err := s.postRepo.DeleteReaction(ctx, postID, userID)
+	if err != nil {
+		return apperror.InternalServer("failed removing reaction").WithCause(err)
+	}
+
+	if !removed {
+		return apperror.NotFound("reaction not found", apperror.CodeResourceNotFound)
+	}
+
+	return nil
+}
+
+func (s *PostService) Vote(ctx context.Context, postID, userID uint, req VoteRequest) *apperror.AppError {
+	p, err := s.postRepo.GetPost(ctx, postID, &userID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return apperror.NotFound("post not found", apperror.CodeResourceNotFound)
+		}
+		return apperror.InternalServer("failed get post").WithCause(err)
+	}
+
+	poll, err := s.postRepo.GetPostPoll(ctx, p.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return apperror.NotFound("post has no poll", apperror.CodeResourceNotFound)
+		}
+		return apperror.InternalServer("failed get poll").WithCause(err)
+	}
+
+	if poll.ClosesAt != nil && !poll.ClosesAt.After(time.Now()) {
+		return apperror.Conflict("poll is closed", apperror.CodePollClosed)
+	}
+
+	if req.OptionID == 0 {
+		return apperror.ValidationError("vote validation error", []apperror.FieldError{
+			apperror.NewFieldError("option_id", apperror.CodeFieldRequired),
+		})
+	}
+
+	exists, err := s.postRepo.PollOptionExists(ctx, poll.ID, req.OptionID)
+	if err != nil {
+		return apperror.InternalServer("failed find poll option").WithCause(err)
+	}
+	if !exists {
+		return apperror.NotFound("poll option not found", apperror.CodeResourceNotFound).WithNotFoundField("option_id")
+	}
+
+	weight := int64(1)
+	if poll.Mode != PollModeOpen {
+		paid, err := s.postRepo.PaidSupportTotal(ctx, p.CreatorID, userID)
+		if err != nil {
+			return apperror.InternalServer("failed get support total").WithCause(err)
+		}
+
+		if paid <= 0 {
+			return apperror.Forbidden("only supporters can vote in this poll", apperror.CodePollSupportersOnly)
+		}
+
+		if poll.Mode == PollModeWeighted {
+			weight = paid
+		}
+	}
+
+	voted, err := s.postRepo.Vote(ctx, poll.ID, req.OptionID, userID, weight)
+	if err != nil {
+		return apperror.InternalServer("failed voting").WithCause(err)
+	}
+
+	if !voted {
+		return apperror.Conflict("you already voted in this poll", apperror.CodePollAlreadyVoted)
+	}
+
+	return nil
 }
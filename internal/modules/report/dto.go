@@ -0,0 +1,30 @@
+package report
+
+import "time"
+
+type ReportCreateRequest struct {
+	TargetType string `json:"target_type"`
+	TargetID   uint   `json:"target_id"`
+	Reason     string `json:"reason"`
+	Details    string `json:"details"`
+}
+
+type ReviewRequest struct {
+	Note string `json:"note"`
+}
+
+type ReportResponse struct {
+	ID             uint       `json:"id" db:"id"`
+	ReporterID     uint       `json:"reporter_id" db:"reporter_id"`
+	ReporterName   string     `json:"reporter_name" db:"reporter_name"`
+	TargetType     string     `json:"target_type" db:"target_type"`
+	TargetID       uint       `json:"target_id" db:"target_id"`
+	Reason         string     `json:"reason" db:"reason"`
+	Details        *string    `json:"details" db:"details"`
+	Status         string     `json:"status" db:"status"`
+	ReviewedBy     *uint      `json:"reviewed_by" db:"reviewed_by"`
+	ReviewedAt     *time.Time `json:"reviewed_at" db:"reviewed_at"`
+	ResolutionNote *string    `json:"resolution_note" db:"resolution_note"`
+	OpenReports    int        `json:"open_reports" db:"open_reports"`
+	CreatedAt      time.Time  `json:"created_at" db:"created_at"`
+}
@@ -0,0 +1,53 @@
+package report
+
+import "time"
+
+type Report struct {
+	ID             uint       `db:"id"`
+	ReporterID     uint       `db:"reporter_id"`
+	TargetType     string     `db:"target_type"`
+	TargetID       uint       `db:"target_id"`
+	Reason         string     `db:"reason"`
+	Details        *string    `db:"details"`
+	Status         string     `db:"status"`
+	ReviewedBy     *uint      `db:"reviewed_by"`
+	ReviewedAt     *time.Time `db:"reviewed_at"`
+	ResolutionNote *string    `db:"resolution_note"`
+	CreatedAt      time.Time  `db:"created_at"`
+}
+
+const (
+	TargetPost    = "post"
+	TargetComment = "comment"
+	TargetUser    = "user"
+)
+
+// EventContentTakenDown is written to the outbox when reported content is
+// taken down, the event belongs to the owner of the content.
+const EventContentTakenDown = "content.taken_down"
+
+// JobContentTakenDown tells the owner about a takedown over the socket.
+const JobContentTakenDown = "report.content_taken_down"
+
+type ContentTakenDownEvent struct {
+	TargetType string `json:"target_type"`
+	TargetID   uint   `json:"target_id"`
+	Reason     string `json:"reason"`
+	Note       string `json:"note"`
+}
+
+const (
+	StatusOpen      = "open"
+	StatusDismissed = "dismissed"
+	StatusActioned  = "actioned"
+)
+
+var reasons = map[string]bool{
+	"spam":       true,
+	"harassment": true,
+	"hate":       true,
+	"nudity":     true,
+	"violence":   true,
+	"scam":       true,
+	"other":      true,
+}
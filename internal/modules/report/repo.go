@@ -0,0 +1,179 @@
+package report
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/rxmy43/support-platform/internal/outbox"
+	"github.com/rxmy43/support-platform/internal/pagination"
+	"github.com/rxmy43/support-platform/internal/repo"
+)
+
+type ReportRepo struct {
+	*repo.BaseRepo[Report]
+}
+
+func NewReportRepo(DB *sqlx.DB) *ReportRepo {
+	return &ReportRepo{
+		BaseRepo: &repo.BaseRepo[Report]{
+			DB:        DB,
+			TableName: "reports",
+		},
+	}
+}
+
+// ownerQueries return the user owning a target that is still listed.
+var ownerQueries = map[string]string{
+	TargetPost:    "SELECT creator_id FROM posts WHERE id = $1 AND status <> 'taken_down'",
+	TargetComment: "SELECT user_id FROM comments WHERE id = $1 AND taken_down_at IS NULL",
+	TargetUser:    "SELECT id FROM users WHERE id = $1 AND taken_down_at IS NULL",
+}
+
+// takedownQueries hide a target everywhere it is listed.
+var takedownQueries = map[string]string{
+	TargetPost:    "UPDATE posts SET status = 'taken_down', pin_position = NULL WHERE id = $1",
+	TargetComment: "UPDATE comments SET taken_down_at = NOW(), is_pinned = FALSE WHERE id = $1",
+	TargetUser:    "UPDATE users SET taken_down_at = NOW() WHERE id = $1",
+}
+
+func (r *ReportRepo) TargetOwner(ctx context.Context, targetType string, targetID uint) (uint, error) {
+	var ownerID uint
+	err := r.DB.GetContext(ctx, &ownerID, ownerQueries[targetType], targetID)
+	return ownerID, err
+}
+
+// Insert stores the report unless the reporter already has an open report on
+// the same target.
+func (r *ReportRepo) Insert(ctx context.Context, rep *Report) (bool, error) {
+	query := `
+		INSERT INTO reports (reporter_id, target_type, target_id, reason, details)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (reporter_id, target_type, target_id) WHERE status = 'open' DO NOTHING
+		RETURNING id, status, created_at
+	`
+
+	rows, err := r.DB.QueryxContext(ctx, query, rep.ReporterID, rep.TargetType, rep.TargetID, rep.Reason, rep.Details)
+	if err != nil {
+		return false, err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		return false, rows.Err()
+	}
+
+	return true, rows.Scan(&rep.ID, &rep.Status, &rep.CreatedAt)
+}
+
+type reportCursor struct {
+	ID uint `json:"id"`
+}
+
+// GetReports lists reports with the given status, oldest first so the queue
+// is worked through in order.
+func (r *ReportRepo) GetReports(ctx context.Context, status string, params pagination.Params) (pagination.Page[ReportResponse], error) {
+	reports := []ReportResponse{}
+
+	cursor, err := pagination.Decode[reportCursor](params.Cursor)
+	if err != nil {
+		return pagination.Page[ReportResponse]{}, err
+	}
+
+	where := "WHERE r.status = $1"
+	args := []any{status}
+	if cursor != nil {
+		args = append(args, cursor.ID)
+		where += fmt.Sprintf(" AND r.id > $%d", len(args))
+	}
+
+	args = append(args, params.FetchLimit())
+	query := fmt.Sprintf(`
+		SELECT
+			r.id,
+			r.reporter_id,
+			u.name AS reporter_name,
+			r.target_type,
+			r.target_id,
+			r.reason,
+			r.details,
+			r.status,
+			r.reviewed_by,
+			r.reviewed_at,
+			r.resolution_note,
+			(
+				SELECT COUNT(*) FROM reports o
+				WHERE o.target_type = r.target_type
+				AND o.target_id = r.target_id
+				AND o.status = 'open'
+			) AS open_reports,
+			r.created_at
+		FROM reports r
+		JOIN users u ON u.id = r.reporter_id
+		%s
+		ORDER BY r.id ASC
+		LIMIT $%d
+	`, where, len(args))
+
+	if err := r.DB.SelectContext(ctx, &reports, query, args...); err != nil {
+		return pagination.Page[ReportResponse]{}, err
+	}
+
+	return pagination.NewPage(reports, params.Limit, func(rep ReportResponse) reportCursor {
+		return reportCursor{ID: rep.ID}
+	}), nil
+}
+
+func (r *ReportRepo) Dismiss(ctx context.Context, id, adminID uint, note string) (bool, error) {
+	query := `
+		UPDATE reports
+		SET status = 'dismissed', reviewed_by = $2, reviewed_at = NOW(), resolution_note = NULLIF($3, '')
+		WHERE id = $1
+		AND status = 'open'
+	`
+
+	res, err := r.DB.ExecContext(ctx, query, id, adminID, note)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected > 0, nil
+}
+
+// TakeDown hides the reported target and closes every open report on it.
+func (r *ReportRepo) TakeDown(ctx context.Context, rep *Report, adminID uint, note string, ownerID uint) error {
+	tx, err := r.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, takedownQueries[rep.TargetType], rep.TargetID); err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE reports
+		SET status = 'actioned', reviewed_by = $3, reviewed_at = NOW(), resolution_note = NULLIF($4, '')
+		WHERE target_type = $1
+		AND target_id = $2
+		AND status = 'open'
+	`
+	if _, err := tx.ExecContext(ctx, query, rep.TargetType, rep.TargetID, adminID, note); err != nil {
+		return err
+	}
+
+	if ownerID != 0 {
+		event := ContentTakenDownEvent{TargetType: rep.TargetType, TargetID: rep.TargetID, Reason: rep.Reason, Note: note}
+		if err := outbox.Write(ctx, tx, EventContentTakenDown, ownerID, event); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,210 @@
+package report
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"github.com/rxmy43/support-platform/internal/apperror"
+	"github.com/rxmy43/support-platform/internal/modules/user"
+	"github.com/rxmy43/support-platform/internal/outbox"
+	"github.com/rxmy43/support-platform/internal/pagination"
+	"github.com/rxmy43/support-platform/internal/socket"
+)
+
+type ReportService struct {
+	reportRepo *ReportRepo
+	userRepo   *user.UserRepo
+	hub        *socket.Hub
+}
+
+func NewReportService(reportRepo *ReportRepo, userRepo *user.UserRepo, hub *socket.Hub) *ReportService {
+	return &ReportService{
+		reportRepo: reportRepo,
+		userRepo:   userRepo,
+		hub:        hub,
+	}
+}
+
+const maxDetailsLength = 1000
+
+func (s *ReportService) Create(ctx context.Context, reporterID uint, req ReportCreateRequest) (*Report, *apperror.AppError) {
+	var fieldErrs []apperror.FieldError
+
+	if _, ok := ownerQueries[req.TargetType]; !ok {
+		fieldErrs = append(fieldErrs, apperror.NewFieldError("target_type", apperror.CodeSelectionInvalid))
+	}
+
+	if req.TargetID == 0 {
+		fieldErrs = append(fieldErrs, apperror.NewFieldError("target_id", apperror.CodeFieldRequired))
+	}
+
+	if !reasons[req.Reason] {
+		fieldErrs = append(fieldErrs, apperror.NewFieldError("reason", apperror.CodeSelectionInvalid))
+	}
+
+	details := strings.TrimSpace(req.Details)
+	if len([]rune(details)) > maxDetailsLength {
+		fieldErrs = append(fieldErrs, apperror.NewFieldError("details", apperror.CodeFieldTooLong))
+	}
+
+	if len(fieldErrs) > 0 {
+		return nil, apperror.ValidationError("create report validation error", fieldErrs)
+	}
+
+	ownerID, err := s.reportRepo.TargetOwner(ctx, req.TargetType, req.TargetID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, apperror.NotFound("reported content not found", apperror.CodeResourceNotFound).WithNotFoundField("target_id")
+		}
+		return nil, apperror.InternalServer("failed find reported content").WithCause(err)
+	}
+
+	if ownerID == reporterID {
+		return nil, apperror.BadRequest("you cannot report your own content", apperror.CodeUnauthorizedOperation)
+	}
+
+	newReport := &Report{
+		ReporterID: reporterID,
+		TargetType: req.TargetType,
+		TargetID:   req.TargetID,
+		Reason:     req.Reason,
+	}
+	if details != "" {
+		newReport.Details = &details
+	}
+
+	created, err := s.reportRepo.Insert(ctx, newReport)
+	if err != nil {
+		return nil, apperror.InternalServer("failed creating report").WithCause(err)
+	}
+
+	if !created {
+		return nil, apperror.Conflict("you already reported this content", apperror.CodeFieldDuplicate)
+	}
+
+	return newReport, nil
+}
+
+func (s *ReportService) checkAdmin(ctx context.Context, adminID uint) *apperror.AppError {
+	admin, err := s.userRepo.FindByID(ctx, adminID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return apperror.Unauthorized("invalid user", apperror.CodeUnauthorizedOperation)
+		}
+		return apperror.InternalServer("failed checking admin").WithCause(err)
+	}
+
+	if admin.Role != "admin" {
+		return apperror.Forbidden("only admin can review reports", apperror.CodeAdminAccessRequired)
+	}
+
+	return nil
+}
+
+func (s *ReportService) FindAll(ctx context.Context, adminID uint, status string, params pagination.Params) (pagination.Page[ReportResponse], *apperror.AppError) {
+	if appErr := s.checkAdmin(ctx, adminID); appErr != nil {
+		return pagination.Page[ReportResponse]{}, appErr
+	}
+
+	if status == "" {
+		status = StatusOpen
+	}
+
+	if status != StatusOpen && status != StatusDismissed && status != StatusActioned {
+		return pagination.Page[ReportResponse]{}, apperror.ValidationError("report list validation error", []apperror.FieldError{
+			apperror.NewFieldError("status", apperror.CodeSelectionInvalid),
+		})
+	}
+
+	page, err := s.reportRepo.GetReports(ctx, status, params)
+	if err != nil {
+		if errors.Is(err, pagination.ErrInvalidCursor) {
+			return page, apperror.BadRequest("invalid cursor", apperror.CodeUnknown)
+		}
+		return page, apperror.InternalServer("failed get reports").WithCause(err)
+	}
+
+	return page, nil
+}
+
+func (s *ReportService) findOpenReport(ctx context.Context, reportID uint) (*Report, *apperror.AppError) {
+	rep, err := s.reportRepo.FindByID(ctx, reportID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, apperror.NotFound("report not found", apperror.CodeResourceNotFound)
+		}
+		return nil, apperror.InternalServer("failed find report").WithCause(err)
+	}
+
+	if rep.Status != StatusOpen {
+		return nil, apperror.Conflict("report was already reviewed", apperror.CodeUnknown)
+	}
+
+	return rep, nil
+}
+
+func (s *ReportService) Dismiss(ctx context.Context, adminID, reportID uint, req ReviewRequest) *apperror.AppError {
+	if appErr := s.checkAdmin(ctx, adminID); appErr != nil {
+		return appErr
+	}
+
+	rep, appErr := s.findOpenReport(ctx, reportID)
+	if appErr != nil {
+		return appErr
+	}
+
+	dismissed, err := s.reportRepo.Dismiss(ctx, rep.ID, adminID, strings.TrimSpace(req.Note))
+	if err != nil {
+		return apperror.InternalServer("failed dismissing report").WithCause(err)
+	}
+
+	if !dismissed {
+		return apperror.Conflict("report was already reviewed", apperror.CodeUnknown)
+	}
+
+	return nil
+}
+
+// TakeDown hides the reported content. Its owner is told through an
+// EventContentTakenDown event, which keeps a notification for them.
+func (s *ReportService) TakeDown(ctx context.Context, adminID, reportID uint, req ReviewRequest) *apperror.AppError {
+	if appErr := s.checkAdmin(ctx, adminID); appErr != nil {
+		return appErr
+	}
+
+	rep, appErr := s.findOpenReport(ctx, reportID)
+	if appErr != nil {
+		return appErr
+	}
+
+	ownerID, err := s.reportRepo.TargetOwner(ctx, rep.TargetType, rep.TargetID)
+	if err != nil && err != sql.ErrNoRows {
+		return apperror.InternalServer("failed find reported content").WithCause(err)
+	}
+
+	// the content could have been taken down through another report
+	// already, its owner was told then
+	if err := s.reportRepo.TakeDown(ctx, rep, adminID, strings.TrimSpace(req.Note), ownerID); err != nil {
+		return apperror.InternalServer("failed taking down content").WithCause(err)
+	}
+
+	return nil
+}
+
+// HandleContentTakenDown runs the JobContentTakenDown jobs of
+// EventContentTakenDown.
+func (s *ReportService) HandleContentTakenDown(ctx context.Context, payload json.RawMessage) error {
+	var takenDown ContentTakenDownEvent
+	event, err := outbox.Decode(payload, &takenDown)
+	if err != nil {
+		return err
+	}
+
+	return s.hub.SendToCreator(ctx, event.UserID, socket.EventMessage{
+		Event: "content_taken_down",
+		Data:  takenDown,
+	})
+}
@@ -1,39 +1,92 @@
 package support
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
 
 type DonationRequest struct {
-	Amount    int  `json:"amount"`
-	CreatorID uint `json:"creator_id"`
-}
-
-type PaymentCallbackRequest struct {
-	MerchantCode     string `schema:"merchantCode"`
-	Amount           string `schema:"amount"`
-	MerchantOrderID  string `schema:"merchantOrderId"`
-	ProductDetail    string `schema:"productDetail"`
-	AdditionalParam  string `schema:"additionalParam"`
-	ResultCode       string `schema:"resultCode"`
-	PaymentCode      string `schema:"paymentCode"`
-	MerchantUserID   string `schema:"merchantUserId"`
-	Reference        string `schema:"reference"`
-	Signature        string `schema:"signature"`
-	PublisherOrderID string `schema:"publisherOrderId"`
-	SettlementDate   string `schema:"settlementDate"`
-	VaNumber         string `schema:"vaNumber"`
-	SourceAccount    string `schema:"sourceAccount"`
+	Amount      int    `json:"amount"`
+	CreatorID   uint   `json:"creator_id"`
+	PostID      *uint  `json:"post_id"`
+	CampaignID  *uint  `json:"campaign_id"`
+	Message     string `json:"message"`
+	DisplayName string `json:"display_name"`
+	IsAnonymous bool   `json:"is_anonymous"`
+}
+
+type SupportSettingsRequest struct {
+	MinAmount     *int64  `json:"min_amount"`
+	MaxAmount     *int64  `json:"max_amount"`
+	PresetAmounts []int64 `json:"preset_amounts"`
 }
 
+// SupportSettingsResponse has the limits a support to the creator must meet,
+// the creator's own ones within the platform ones.
+type SupportSettingsResponse struct {
+	CreatorID         uint    `json:"creator_id"`
+	MinAmount         int64   `json:"min_amount"`
+	MaxAmount         int64   `json:"max_amount"`
+	PresetAmounts     []int64 `json:"preset_amounts"`
+	PlatformMinAmount int64   `json:"platform_min_amount"`
+	PlatformMaxAmount int64   `json:"platform_max_amount"`
+}
+
+type DonationResponse struct {
+	PaymentURL string `json:"payment_url"`
+	SupportID  uint   `json:"support_id"`
+}
+
+type SupportStatusResponse struct {
+	ID              uint            `json:"id" db:"id"`
+	OrderID         string          `json:"order_id" db:"order_id"`
+	CreatorID       uint            `json:"creator_id" db:"creator_id"`
+	CreatorName     string          `json:"creator_name" db:"creator_name"`
+	Amount          decimal.Decimal `json:"amount" db:"amount"`
+	Status          string          `json:"status" db:"status"`
+	FailureReason   *string         `json:"failure_reason" db:"failure_reason"`
+	SentAt          time.Time       `json:"sent_at" db:"sent_at"`
+	StatusChangedAt time.Time       `json:"status_changed_at" db:"status_changed_at"`
+	LastCheckedAt   *time.Time      `json:"-" db:"last_checked_at"`
+}
+
+// BestSupporters is seen by the creator, FanName respects the display name
+// and anonymity the fan chose.
 type BestSupporters struct {
-	ID      uint      `json:"id" db:"id"`
-	FanName string    `json:"fan_name" db:"fan_name"`
-	Amount  int64     `json:"amount" db:"amount"`
-	SentAt  time.Time `json:"sent_at" db:"sent_at"`
+	ID            uint      `json:"id" db:"id"`
+	FanName       string    `json:"fan_name" db:"fan_name"`
+	IsAnonymous   bool      `json:"is_anonymous" db:"is_anonymous"`
+	Amount        int64     `json:"amount" db:"amount"`
+	Message       *string   `json:"message" db:"message"`
+	MessageHidden bool      `json:"message_hidden" db:"message_hidden"`
+	SentAt        time.Time `json:"sent_at" db:"sent_at"`
 }
 
 type FanSupportHistory struct {
-	ID          uint      `json:"id" db:"id"`
-	CreatorName string    `json:"creator_name" db:"creator_name"`
-	Amount      int64     `json:"amount" db:"amount"`
-	SentAt      time.Time `json:"sent_at" db:"sent_at"`
+	ID              uint      `json:"id" db:"id"`
+	CreatorName     string    `json:"creator_name" db:"creator_name"`
+	Amount          int64     `json:"amount" db:"amount"`
+	SentAt          time.Time `json:"sent_at" db:"sent_at"`
+	Status          string    `json:"status" db:"status"`
+	FailureReason   *string   `json:"failure_reason" db:"failure_reason"`
+	StatusChangedAt time.Time `json:"status_changed_at" db:"status_changed_at"`
+	Message         *string   `json:"message" db:"message"`
+	DisplayName     *string   `json:"display_name" db:"display_name"`
+	IsAnonymous     bool      `json:"is_anonymous" db:"is_anonymous"`
+	MessageHidden   bool      `json:"message_hidden" db:"message_hidden"`
+}
+
+type PaymentAnomalyResponse struct {
+	ID                uint                `json:"id" db:"id"`
+	Reason            string              `json:"reason" db:"reason"`
+	OrderID           string              `json:"order_id" db:"order_id"`
+	Reference         string              `json:"reference" db:"reference"`
+	MerchantCode      string              `json:"merchant_code" db:"merchant_code"`
+	Amount            decimal.Decimal     `json:"amount" db:"amount"`
+	ExpectedReference *string             `json:"expected_reference" db:"expected_reference"`
+	ExpectedAmount    decimal.NullDecimal `json:"expected_amount" db:"expected_amount"`
+	Payload           json.RawMessage     `json:"payload" db:"payload"`
+	CreatedAt         time.Time           `json:"created_at" db:"created_at"`
 }
@@ -3,6 +3,7 @@ package support
 import (
 	"time"
 
+	"github.com/lib/pq"
 	"github.com/shopspring/decimal"
 )
 
@@ -10,10 +11,113 @@ type Support struct {
 	ID               uint            `db:"id"`
 	FanID            uint            `db:"fan_id"`
 	CreatorID        uint            `db:"creator_id"`
+	PostID           *uint           `db:"post_id"`
+	CampaignID       *uint           `db:"campaign_id"`
 	Amount           decimal.Decimal `db:"amount"`
 	Status           string          `db:"status"`
 	SupportID        string          `db:"support_id"`
 	SentAt           time.Time       `db:"sent_at"`
 	ReferenceCode    string          `db:"reference_code"`
 	PaymentTimestamp int64           `db:"payment_timestamp"`
+	StatusChangedAt  time.Time       `db:"status_changed_at"`
+	FailureReason    *string         `db:"failure_reason"`
+	Message          *string         `db:"message"`
+	DisplayName      *string         `db:"display_name"`
+	IsAnonymous      bool            `db:"is_anonymous"`
+	MessageHidden    bool            `db:"message_hidden"`
+	// LastCheckedAt is when the gateway was last asked about the payment.
+	LastCheckedAt *time.Time `db:"last_checked_at"`
+}
+
+const (
+	maxMessageLength     = 280
+	maxDisplayNameLength = 50
+)
+
+// CreatorSettings are the support limits and preset amounts a creator chose,
+// a nil limit falls back to the platform one.
+type CreatorSettings struct {
+	CreatorID        uint          `db:"creator_id"`
+	MinSupportAmount *int64        `db:"min_support_amount"`
+	MaxSupportAmount *int64        `db:"max_support_amount"`
+	PresetAmounts    pq.Int64Array `db:"preset_amounts"`
+	UpdatedAt        time.Time     `db:"updated_at"`
+}
+
+// MaxPresetAmounts is how many preset amounts a creator page can show.
+const MaxPresetAmounts = 6
+
+// AnonymousName is shown instead of the fan of an anonymous support.
+const AnonymousName = "Anonymous"
+
+// Support statuses. A support starts pending and settles exactly once, only
+// a paid support can move on, to refunded. An expired support can still be
+// paid, when the gateway callback comes in after we gave up on it.
+const (
+	StatusPending  = "pending"
+	StatusPaid     = "paid"
+	StatusFailed   = "failed"
+	StatusExpired  = "expired"
+	StatusRefunded = "refunded"
+)
+
+var statusTransitions = map[string][]string{
+	StatusPending: {StatusPaid, StatusFailed, StatusExpired},
+	StatusPaid:    {StatusRefunded},
+	StatusExpired: {StatusPaid},
+}
+
+// CanTransition reports whether a support may move from one status to another.
+func CanTransition(from, to string) bool {
+	for _, next := range statusTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// Reasons a verified callback is recorded as a payment anomaly.
+const (
+	AnomalyMerchantMismatch  = "merchant_mismatch"
+	AnomalyUnknownOrder      = "unknown_order"
+	AnomalyReferenceMismatch = "reference_mismatch"
+	AnomalyAmountMismatch    = "amount_mismatch"
+)
+
+type PaymentAnomaly struct {
+	ID                uint                `db:"id"`
+	Reason            string              `db:"reason"`
+	OrderID           string              `db:"order_id"`
+	Reference         string              `db:"reference"`
+	MerchantCode      string              `db:"merchant_code"`
+	Amount            decimal.Decimal     `db:"amount"`
+	ExpectedReference *string             `db:"expected_reference"`
+	ExpectedAmount    decimal.NullDecimal `db:"expected_amount"`
+	Payload           []byte              `db:"payload"`
+	CreatedAt         time.Time           `db:"created_at"`
+}
+
+// EventSupportPaid is written to the outbox when a support is paid.
+const EventSupportPaid = "support.paid"
+
+// JobSupportReceived tells the creator about a paid support over the socket.
+const JobSupportReceived = "support.received"
+
+// SupportPaidEvent is what the creator may know about a paid support, it is
+// sent to their socket and webhooks as is. FanName is the name the fan chose
+// to show. OrderID and FanID are left out of anonymous supports, the order id
+// embeds the fan id.
+type SupportPaidEvent struct {
+	SupportID   uint    `json:"support_id"`
+	OrderID     string  `json:"order_id,omitempty"`
+	Amount      int64   `json:"amount"`
+	Reference   string  `json:"reference"`
+	CampaignID  *uint   `json:"campaign_id,omitempty"`
+	FanName     string  `json:"fan_name"`
+	FanID       *uint   `json:"fan_id,omitempty"`
+	IsAnonymous bool    `json:"is_anonymous"`
+	Message     *string `json:"message"`
+	CreatorName string  `json:"creator_name"`
+	CreatorID   uint    `json:"creator_id"`
 }
@@ -3,10 +3,15 @@ package support
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"fmt"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/jmoiron/sqlx"
+	"github.com/rxmy43/support-platform/internal/outbox"
+	"github.com/rxmy43/support-platform/internal/pagination"
 	"github.com/rxmy43/support-platform/internal/repo"
 	"github.com/shopspring/decimal"
 )
@@ -24,47 +29,333 @@ func NewSupportRepo(DB *sqlx.DB) *SupportRepo {
 	}
 }
 
-type supportTimestampCreatorFan struct {
-	PaymentTimestamp int64  `db:"payment_timestamp"`
-	FanID            uint   `db:"fan_id"`
-	CreatorID        uint   `db:"creator_id"`
-	FanName          string `db:"fan_name"`
-	CreatorName      string `db:"creator_name"`
+// Insert stores a new support and sets its id.
+func (r *SupportRepo) Insert(ctx context.Context, s *Support) error {
+	query := `
+		INSERT INTO supports (fan_id, creator_id, post_id, amount, status, support_id, sent_at, reference_code, payment_timestamp, status_changed_at,
+			message, display_name, is_anonymous, campaign_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+		RETURNING id
+	`
+	return r.DB.QueryRowxContext(ctx, query,
+		s.FanID, s.CreatorID, s.PostID, s.Amount, s.Status, s.SupportID, s.SentAt, s.ReferenceCode, s.PaymentTimestamp, s.StatusChangedAt,
+		s.Message, s.DisplayName, s.IsAnonymous, s.CampaignID,
+	).Scan(&s.ID)
 }
 
-func (r *SupportRepo) GetPaymentTimestamp(ctx context.Context, reference, supportID string) (*supportTimestampCreatorFan, error) {
-	var result supportTimestampCreatorFan
+// FindFanSupport returns a support made by the fan.
+func (r *SupportRepo) FindFanSupport(ctx context.Context, id, fanID uint) (*SupportStatusResponse, error) {
+	var support SupportStatusResponse
+	query := `
+		SELECT s.id, s.support_id AS order_id, s.creator_id, c.name AS creator_name, s.amount, s.status,
+			s.failure_reason, s.sent_at, s.status_changed_at, s.last_checked_at
+		FROM supports s
+		JOIN users c ON c.id = s.creator_id
+		WHERE s.id = $1 AND s.fan_id = $2
+	`
+	if err := r.DB.GetContext(ctx, &support, query, id, fanID); err != nil {
+		return nil, err
+	}
+	return &support, nil
+}
 
+// FindCreatorSettings returns the support settings of a creator,
+// sql.ErrNoRows when they never changed them.
+func (r *SupportRepo) FindCreatorSettings(ctx context.Context, creatorID uint) (*CreatorSettings, error) {
+	var settings CreatorSettings
 	query := `
-		SELECT 
-			s.payment_timestamp, 
-			s.creator_id, 
-			s.fan_id, 
-			f.name AS fan_name,
-			c.name AS creator_name
+		SELECT creator_id, min_support_amount, max_support_amount, preset_amounts, updated_at
+		FROM creator_settings
+		WHERE creator_id = $1
+	`
+	if err := r.DB.GetContext(ctx, &settings, query, creatorID); err != nil {
+		return nil, err
+	}
+	return &settings, nil
+}
+
+// UpsertCreatorSettings creates or replaces the support settings of a creator.
+func (r *SupportRepo) UpsertCreatorSettings(ctx context.Context, settings *CreatorSettings) error {
+	query := `
+		INSERT INTO creator_settings (creator_id, min_support_amount, max_support_amount, preset_amounts)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (creator_id) DO UPDATE SET
+			min_support_amount = EXCLUDED.min_support_amount,
+			max_support_amount = EXCLUDED.max_support_amount,
+			preset_amounts = EXCLUDED.preset_amounts,
+			updated_at = NOW()
+		RETURNING updated_at
+	`
+	return r.DB.QueryRowxContext(ctx, query,
+		settings.CreatorID, settings.MinSupportAmount, settings.MaxSupportAmount, settings.PresetAmounts,
+	).Scan(&settings.UpdatedAt)
+}
+
+// shownFanName is the fan name of a support as the creator sees it, s and f
+// being the supports and fan users tables.
+const shownFanName = "CASE WHEN s.is_anonymous THEN '" + AnonymousName + "' ELSE COALESCE(s.display_name, f.name) END"
+
+// SetMessageHidden hides or shows the message of a support to the creator,
+// it reports false when the creator has no such support.
+func (r *SupportRepo) SetMessageHidden(ctx context.Context, id, creatorID uint, hidden bool) (bool, error) {
+	res, err := r.DB.ExecContext(ctx, "UPDATE supports SET message_hidden = $3 WHERE id = $1 AND creator_id = $2", id, creatorID, hidden)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected > 0, nil
+}
+
+// PostBelongsToCreator checks a support can be attributed to the given post.
+func (r *SupportRepo) PostBelongsToCreator(ctx context.Context, postID, creatorID uint) (bool, error) {
+	var exists bool
+	err := r.DB.GetContext(ctx, &exists, "SELECT EXISTS (SELECT 1 FROM posts WHERE id = $1 AND creator_id = $2)", postID, creatorID)
+	return exists, err
+}
+
+// CampaignOpen checks a support can go to the given campaign of the creator,
+// it returns sql.ErrNoRows when the creator has no such campaign.
+func (r *SupportRepo) CampaignOpen(ctx context.Context, campaignID, creatorID uint) (bool, error) {
+	var open bool
+	query := `
+		SELECT status = 'active' AND (deadline IS NULL OR deadline > NOW())
+		FROM campaigns
+		WHERE id = $1 AND creator_id = $2
+	`
+	err := r.DB.GetContext(ctx, &open, query, campaignID, creatorID)
+	return open, err
+}
+
+type settledSupport struct {
+	ID            uint            `db:"id"`
+	Status        string          `db:"status"`
+	Amount        decimal.Decimal `db:"amount"`
+	ReferenceCode string          `db:"reference_code"`
+	FanID         uint            `db:"fan_id"`
+	CreatorID     uint            `db:"creator_id"`
+	FanName       string          `db:"fan_name"`
+	CreatorName   string          `db:"creator_name"`
+	IsAnonymous   bool            `db:"is_anonymous"`
+	Message       *string         `db:"message"`
+	CampaignID    *uint           `db:"campaign_id"`
+}
+
+var (
+	ErrReferenceMismatch = errors.New("callback reference does not match the support")
+	ErrAmountMismatch    = errors.New("callback amount does not match the support")
+	ErrInvalidTransition = errors.New("support status cannot change")
+)
+
+// Settle moves a support to the status a gateway reported, crediting the
+// creator with the stored amount and writing the EventSupportPaid outbox
+// event when it is paid. The support row is locked
+// for the whole transaction so duplicate callbacks arriving together are
+// applied one after the other, and only the first one changes it. It reports
+// false when the support already has that status. A callback that does not
+// match what we stored gets ErrReferenceMismatch or ErrAmountMismatch, and a
+// support that settled differently gets ErrInvalidTransition, both along with
+// the support.
+func (r *SupportRepo) Settle(ctx context.Context, supportID, reference string, amount decimal.Decimal, status string, reason *string) (*settledSupport, bool, error) {
+	tx, err := r.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, false, err
+	}
+	defer tx.Rollback()
+
+	var support settledSupport
+	query := `
+		SELECT s.id, s.status, s.amount, COALESCE(s.reference_code, '') AS reference_code,
+			s.fan_id, s.creator_id, ` + shownFanName + ` AS fan_name, c.name AS creator_name,
+			s.is_anonymous, s.message, s.campaign_id
 		FROM supports s
-		JOIN users f ON s.fan_id = f.id
-		JOIN users c ON s.creator_id = c.id
-		WHERE s.reference_code = $1
-		AND s.support_id = $2
+		JOIN users f ON f.id = s.fan_id
+		JOIN users c ON c.id = s.creator_id
+		WHERE s.support_id = $1
+		FOR UPDATE OF s
 	`
+	if err := tx.GetContext(ctx, &support, query, supportID); err != nil {
+		return nil, false, err
+	}
+
+	if support.ReferenceCode != reference {
+		return &support, false, ErrReferenceMismatch
+	}
+
+	if !support.Amount.Equal(amount) {
+		return &support, false, ErrAmountMismatch
+	}
+
+	if support.Status == status {
+		return &support, false, nil
+	}
+
+	if !CanTransition(support.Status, status) {
+		return &support, false, ErrInvalidTransition
+	}
 
-	err := r.DB.GetContext(ctx, &result, query, reference, supportID)
+	if status == StatusPaid {
+		if _, err := tx.ExecContext(ctx, "UPDATE balances SET amount = amount + $1 WHERE user_id = $2", support.Amount, support.CreatorID); err != nil {
+			return nil, false, err
+		}
+
+		event := SupportPaidEvent{
+			SupportID:   support.ID,
+			Amount:      support.Amount.IntPart(),
+			Reference:   support.ReferenceCode,
+			FanName:     support.FanName,
+			IsAnonymous: support.IsAnonymous,
+			Message:     support.Message,
+			CampaignID:  support.CampaignID,
+			CreatorName: support.CreatorName,
+			CreatorID:   support.CreatorID,
+		}
+		if !support.IsAnonymous {
+			event.OrderID = supportID
+			event.FanID = &support.FanID
+		}
+
+		if err := outbox.Write(ctx, tx, EventSupportPaid, support.CreatorID, event); err != nil {
+			return nil, false, err
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE supports SET status = $1, failure_reason = $2, status_changed_at = NOW()
+		WHERE id = $3
+	`, status, reason, support.ID); err != nil {
+		return nil, false, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, false, err
+	}
+
+	support.Status = status
+	return &support, true, nil
+}
+
+// Expire expires a support that is still pending, it reports false when the
+// support settled in the meantime.
+func (r *SupportRepo) Expire(ctx context.Context, supportID, reason string) (bool, error) {
+	res, err := r.DB.ExecContext(ctx, `
+		UPDATE supports SET status = 'expired', failure_reason = $2, status_changed_at = NOW()
+		WHERE support_id = $1 AND status = 'pending'
+	`, supportID, reason)
 	if err != nil {
+		return false, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected > 0, nil
+}
+
+// MarkChecked records that the gateway was just asked about a support.
+func (r *SupportRepo) MarkChecked(ctx context.Context, supportID string) error {
+	_, err := r.DB.ExecContext(ctx, "UPDATE supports SET last_checked_at = NOW() WHERE support_id = $1", supportID)
+	return err
+}
+
+// FindPendingBefore lists supports pending since before the given time, the
+// ones least recently checked with the gateway first, so supports the
+// gateway keeps failing on do not hold back the others.
+func (r *SupportRepo) FindPendingBefore(ctx context.Context, before time.Time, limit int) ([]Support, error) {
+	supports := []Support{}
+	query := `
+		SELECT id, fan_id, creator_id, post_id, amount, status, support_id, sent_at,
+			COALESCE(reference_code, '') AS reference_code, COALESCE(payment_timestamp, 0) AS payment_timestamp,
+			status_changed_at, failure_reason, message, display_name, is_anonymous, message_hidden, campaign_id,
+			last_checked_at
+		FROM supports
+		WHERE status = 'pending' AND sent_at < $1
+		ORDER BY last_checked_at ASC NULLS FIRST, sent_at ASC
+		LIMIT $2
+	`
+	if err := r.DB.SelectContext(ctx, &supports, query, before, limit); err != nil {
 		return nil, err
 	}
+	return supports, nil
+}
+
+// CreateAnomaly records a callback that did not match its support.
+func (r *SupportRepo) CreateAnomaly(ctx context.Context, a *PaymentAnomaly) error {
+	query := `
+		INSERT INTO payment_anomalies (reason, order_id, reference, merchant_code, amount, expected_reference, expected_amount, payload)
+		VALUES (:reason, :order_id, :reference, :merchant_code, :amount, :expected_reference, :expected_amount, :payload)
+	`
+	_, err := r.DB.NamedExecContext(ctx, query, a)
+	return err
+}
+
+type anomalyCursor struct {
+	ID uint `json:"id"`
+}
+
+// GetAnomalies lists recorded payment anomalies, newest first.
+func (r *SupportRepo) GetAnomalies(ctx context.Context, params pagination.Params) (pagination.Page[PaymentAnomalyResponse], error) {
+	anomalies := []PaymentAnomalyResponse{}
+
+	cursor, err := pagination.Decode[anomalyCursor](params.Cursor)
+	if err != nil {
+		return pagination.Page[PaymentAnomalyResponse]{}, err
+	}
+
+	where := ""
+	args := []any{}
+	if cursor != nil {
+		args = append(args, cursor.ID)
+		where = fmt.Sprintf("WHERE id < $%d", len(args))
+	}
+
+	args = append(args, params.FetchLimit())
+	query := fmt.Sprintf(`
+		SELECT id, reason, order_id, reference, merchant_code, amount, expected_reference, expected_amount, payload, created_at
+		FROM payment_anomalies
+		%s
+		ORDER BY id DESC
+		LIMIT $%d
+	`, where, len(args))
+
+	if err := r.DB.SelectContext(ctx, &anomalies, query, args...); err != nil {
+		return pagination.Page[PaymentAnomalyResponse]{}, err
+	}
 
-	return &result, nil
+	return pagination.NewPage(anomalies, params.Limit, func(a PaymentAnomalyResponse) anomalyCursor {
+		return anomalyCursor{ID: a.ID}
+	}), nil
 }
 
-func (r *SupportRepo) GetCreatorSupporters(ctx context.Context, cursor *uint, creatorID uint) ([]BestSupporters, *uint, error) {
+type supporterCursor struct {
+	Amount string `json:"amount"`
+	ID     uint   `json:"id"`
+}
+
+// GetCreatorSupporters lists the paid supports of a creator, biggest first.
+func (r *SupportRepo) GetCreatorSupporters(ctx context.Context, params pagination.Params, creatorID uint) (pagination.Page[BestSupporters], error) {
 	supporters := []BestSupporters{}
+	amounts := map[uint]string{}
+
+	cursor, err := pagination.Decode[supporterCursor](params.Cursor)
+	if err != nil {
+		return pagination.Page[BestSupporters]{}, err
+	}
 
 	queryBase := `
 		SELECT 
 			s.id,
-			f.name AS fan_name,
+			` + shownFanName + ` AS fan_name,
+			s.is_anonymous,
 			s.amount,
+			s.message,
+			s.message_hidden,
 			s.sent_at
 		FROM supports s
 		JOIN users f ON f.id = s.fan_id
@@ -74,27 +365,29 @@ func (r *SupportRepo) GetCreatorSupporters(ctx context.Context, cursor *uint, cr
 
 	args := []any{creatorID}
 	if cursor != nil {
-		queryBase += " AND s.id < $2"
-		args = append(args, *cursor)
+		queryBase += " AND (s.amount, s.id) < ($2::numeric, $3)"
+		args = append(args, cursor.Amount, cursor.ID)
 	}
 
-	query := queryBase + `
-		ORDER BY s.id DESC
-		LIMIT 10
-	`
+	args = append(args, params.FetchLimit())
+	query := queryBase + fmt.Sprintf(`
+		ORDER BY s.amount DESC, s.id DESC
+		LIMIT $%d
+	`, len(args))
 
 	rows, err := r.DB.QueryContext(ctx, query, args...)
 	if err != nil {
-		return nil, nil, err
+		return pagination.Page[BestSupporters]{}, err
 	}
 	defer rows.Close()
 
 	for rows.Next() {
 		var s BestSupporters
 		var amountNumeric string
-		if err := rows.Scan(new(uint), &s.FanName, &amountNumeric, &s.SentAt); err != nil {
-			return nil, nil, err
+		if err := rows.Scan(&s.ID, &s.FanName, &s.IsAnonymous, &amountNumeric, &s.Message, &s.MessageHidden, &s.SentAt); err != nil {
+			return pagination.Page[BestSupporters]{}, err
 		}
+		amounts[s.ID] = amountNumeric
 
 		if strings.Contains(amountNumeric, ".") {
 			parts := strings.Split(amountNumeric, ".")
@@ -102,23 +395,19 @@ func (r *SupportRepo) GetCreatorSupporters(ctx context.Context, cursor *uint, cr
 		}
 		amount, err := strconv.ParseInt(amountNumeric, 10, 64)
 		if err != nil {
-			return nil, nil, err
+			return pagination.Page[BestSupporters]{}, err
 		}
 		s.Amount = amount
 		supporters = append(supporters, s)
 	}
 
 	if err := rows.Err(); err != nil {
-		return nil, nil, err
-	}
-
-	var nextCursor *uint
-	if len(supporters) > 0 {
-		lastID := supporters[len(supporters)-1].ID
-		nextCursor = &lastID
+		return pagination.Page[BestSupporters]{}, err
 	}
 
-	return supporters, nextCursor, nil
+	return pagination.NewPage(supporters, params.Limit, func(s BestSupporters) supporterCursor {
+		return supporterCursor{Amount: amounts[s.ID], ID: s.ID}
+	}), nil
 }
 
 func (r *SupportRepo) GetFanSpendingAmount(ctx context.Context, fanID uint) (int64, error) {
@@ -142,45 +431,62 @@ func (r *SupportRepo) GetFanSpendingAmount(ctx context.Context, fanID uint) (int
 	return amount.IntPart(), nil
 }
 
-func (r *SupportRepo) GetFanSupportHistory(ctx context.Context, cursor, fanID *uint) ([]FanSupportHistory, *uint, error) {
+type historyCursor struct {
+	SentAt time.Time `json:"sent_at"`
+	ID     uint      `json:"id"`
+}
+
+func (r *SupportRepo) GetFanSupportHistory(ctx context.Context, params pagination.Params, fanID *uint) (pagination.Page[FanSupportHistory], error) {
 	histories := []FanSupportHistory{}
-	var err error
+
+	cursor, err := pagination.Decode[historyCursor](params.Cursor)
+	if err != nil {
+		return pagination.Page[FanSupportHistory]{}, err
+	}
 
 	queryBase := `
 		SELECT
 			s.id,
 			s.amount,
 			s.sent_at,
-			c.name AS creator_name
+			c.name AS creator_name,
+			s.status,
+			s.failure_reason,
+			s.status_changed_at,
+			s.message,
+			s.display_name,
+			s.is_anonymous,
+			s.message_hidden
 		FROM supports s
 		JOIN users c ON c.id = s.creator_id
 		WHERE s.fan_id = $1
-		AND s.status = 'paid'
+		AND s.status IN ('paid', 'failed', 'expired', 'refunded')
 	`
 
 	args := []any{*fanID}
 	if cursor != nil {
-		queryBase += " AND s.id < $2"
-		args = append(args, *cursor)
+		queryBase += " AND (s.sent_at, s.id) < ($2, $3)"
+		args = append(args, cursor.SentAt, cursor.ID)
 	}
 
-	query := `
-		` + queryBase + `
-		ORDER BY s.id DESC
-		LIMIT 10
-	`
+	args = append(args, params.FetchLimit())
+	query := queryBase + fmt.Sprintf(`
+		ORDER BY s.sent_at DESC, s.id DESC
+		LIMIT $%d
+	`, len(args))
 
 	rows, err := r.DB.QueryContext(ctx, query, args...)
 	if err != nil {
-		return nil, nil, err
+		return pagination.Page[FanSupportHistory]{}, err
 	}
 	defer rows.Close()
 
 	for rows.Next() {
 		var h FanSupportHistory
 		var amountNumeric string
-		if err := rows.Scan(&h.ID, &amountNumeric, &h.SentAt, &h.CreatorName); err != nil {
-			return nil, nil, err
+		if err := rows.Scan(&h.ID, &amountNumeric, &h.SentAt, &h.CreatorName, &h.Status, &h.FailureReason, &h.StatusChangedAt,
+			&h.Message, &h.DisplayName, &h.IsAnonymous, &h.MessageHidden); err != nil {
+			return pagination.Page[FanSupportHistory]{}, err
 		}
 
 		// konversi dari numeric(15,2) → int64
@@ -190,21 +496,17 @@ func (r *SupportRepo) GetFanSupportHistory(ctx context.Context, cursor, fanID *u
 		}
 		amount, err := strconv.ParseInt(amountNumeric, 10, 64)
 		if err != nil {
-			return nil, nil, err
+			return pagination.Page[FanSupportHistory]{}, err
 		}
 		h.Amount = amount
 		histories = append(histories, h)
 	}
 
 	if err := rows.Err(); err != nil {
-		return nil, nil, err
-	}
-
-	var nextCursor *uint
-	if len(histories) > 0 {
-		lastID := histories[len(histories)-1].ID
-		nextCursor = &lastID
+		return pagination.Page[FanSupportHistory]{}, err
 	}
 
-	return histories, nextCursor, nil
+	return pagination.NewPage(histories, params.Limit, func(h FanSupportHistory) historyCursor {
+		return historyCursor{SentAt: h.SentAt, ID: h.ID}
+	}), nil
 }
@@ -1,26 +1,29 @@
 package support
 
 import (
-	"bytes"
 	"context"
-	"crypto/md5"
 	"crypto/rand"
-	"crypto/sha256"
 	"database/sql"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
-	"strconv"
+	"net/url"
 	"strings"
 	"time"
 
+	"github.com/lib/pq"
 	"github.com/rxmy43/support-platform/internal/apperror"
 	"github.com/rxmy43/support-platform/internal/config"
 	"github.com/rxmy43/support-platform/internal/http/middleware"
+	"github.com/rxmy43/support-platform/internal/moderation"
 	"github.com/rxmy43/support-platform/internal/modules/balance"
 	"github.com/rxmy43/support-platform/internal/modules/user"
+	"github.com/rxmy43/support-platform/internal/outbox"
+	"github.com/rxmy43/support-platform/internal/pagination"
+	"github.com/rxmy43/support-platform/internal/payment"
 	"github.com/rxmy43/support-platform/internal/socket"
 	"github.com/shopspring/decimal"
 )
@@ -30,33 +33,191 @@ type SupportService struct {
 	userRepo    *user.UserRepo
 	balanceRepo *balance.BalanceRepo
 	hub         *socket.Hub
+	gateway     payment.PaymentGateway
+	blocklist   *moderation.Blocklist
+	limits      config.SupportLimitsConfig
+	appURL      string
 }
 
-func NewSupportService(supportRepo *SupportRepo, userRepo *user.UserRepo, balanceRepo *balance.BalanceRepo, hub *socket.Hub) *SupportService {
+func NewSupportService(supportRepo *SupportRepo, userRepo *user.UserRepo, balanceRepo *balance.BalanceRepo, hub *socket.Hub, gateway payment.PaymentGateway, blocklist *moderation.Blocklist, limits config.SupportLimitsConfig, appURL string) *SupportService {
 	return &SupportService{
 		supportRepo: supportRepo,
 		userRepo:    userRepo,
 		balanceRepo: balanceRepo,
 		hub:         hub,
+		gateway:     gateway,
+		blocklist:   blocklist,
+		limits:      limits,
+		appURL:      appURL,
 	}
 }
 
-func (s *SupportService) generateSignature(merchantCode string, timestamp int64, merchantKey string) string {
-	raw := fmt.Sprintf("%s%d%s", merchantCode, timestamp, merchantKey)
-	hash := sha256.Sum256([]byte(raw))
-	return hex.EncodeToString(hash[:])
+// supportSettings returns the support settings of a creator with the platform
+// limits applied. Presets the limits no longer allow, after a platform limit
+// changed, are left out.
+func (s *SupportService) supportSettings(ctx context.Context, creatorID uint) (*SupportSettingsResponse, *apperror.AppError) {
+	res := &SupportSettingsResponse{
+		CreatorID:         creatorID,
+		MinAmount:         s.limits.MinAmount,
+		MaxAmount:         s.limits.MaxAmount,
+		PresetAmounts:     []int64{},
+		PlatformMinAmount: s.limits.MinAmount,
+		PlatformMaxAmount: s.limits.MaxAmount,
+	}
+
+	settings, err := s.supportRepo.FindCreatorSettings(ctx, creatorID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return res, nil
+		}
+		return nil, apperror.InternalServer("failed find creator support settings").WithCause(err)
+	}
+
+	if settings.MinSupportAmount != nil && *settings.MinSupportAmount > res.MinAmount {
+		res.MinAmount = *settings.MinSupportAmount
+	}
+	if settings.MaxSupportAmount != nil && *settings.MaxSupportAmount < res.MaxAmount {
+		res.MaxAmount = *settings.MaxSupportAmount
+	}
+
+	for _, amount := range settings.PresetAmounts {
+		if amount >= res.MinAmount && amount <= res.MaxAmount {
+			res.PresetAmounts = append(res.PresetAmounts, amount)
+		}
+	}
+
+	return res, nil
 }
 
-func (s *SupportService) generateTimestamp() (int64, error) {
-	loc, err := time.LoadLocation("Asia/Jakarta")
+// GetSupportSettings returns the support settings shown on a creator page.
+func (s *SupportService) GetSupportSettings(ctx context.Context, creatorID uint) (*SupportSettingsResponse, *apperror.AppError) {
+	creator, err := s.userRepo.FindByID(ctx, creatorID)
 	if err != nil {
-		return 0, err
+		if err == sql.ErrNoRows {
+			return nil, apperror.NotFound("creator not found", apperror.CodeResourceNotFound)
+		}
+		return nil, apperror.InternalServer("failed checking creator id").WithCause(err)
+	}
+
+	if creator.Role != "creator" {
+		return nil, apperror.NotFound("creator not found", apperror.CodeResourceNotFound)
+	}
+
+	return s.supportSettings(ctx, creator.ID)
+}
+
+// validateLimit checks a creator limit is within the platform ones.
+func (s *SupportService) validateLimit(field string, amount *int64) []apperror.FieldError {
+	if amount == nil {
+		return nil
 	}
 
-	now := time.Now().In(loc)
-	timestampMillis := now.UnixNano() / int64(time.Millisecond)
+	if *amount < s.limits.MinAmount {
+		return []apperror.FieldError{apperror.NewFieldError(field, apperror.CodeNumberTooSmall).WithExpect(fmt.Sprintf("at least %d", s.limits.MinAmount))}
+	}
+	if *amount > s.limits.MaxAmount {
+		return []apperror.FieldError{apperror.NewFieldError(field, apperror.CodeNumberTooLarge).WithExpect(fmt.Sprintf("at most %d", s.limits.MaxAmount))}
+	}
+
+	return nil
+}
+
+// UpdateSupportSettings replaces the support settings of the creator, a
+// limit left out falls back to the platform one.
+func (s *SupportService) UpdateSupportSettings(ctx context.Context, creatorID uint, req SupportSettingsRequest) (*SupportSettingsResponse, *apperror.AppError) {
+	var fieldErrs []apperror.FieldError
+	fieldErrs = append(fieldErrs, s.validateLimit("min_amount", req.MinAmount)...)
+	fieldErrs = append(fieldErrs, s.validateLimit("max_amount", req.MaxAmount)...)
+
+	if len(fieldErrs) == 0 && req.MinAmount != nil && req.MaxAmount != nil && *req.MaxAmount < *req.MinAmount {
+		fieldErrs = append(fieldErrs, apperror.NewFieldError("max_amount", apperror.CodeNumberTooSmall).WithExpect("at least min_amount"))
+	}
+
+	if len(req.PresetAmounts) > MaxPresetAmounts {
+		fieldErrs = append(fieldErrs, apperror.NewFieldError("preset_amounts", apperror.CodeFieldOutOfRange).WithExpect(fmt.Sprintf("at most %d amounts", MaxPresetAmounts)))
+	}
+
+	if len(fieldErrs) == 0 {
+		minAmount, maxAmount := s.limits.MinAmount, s.limits.MaxAmount
+		if req.MinAmount != nil {
+			minAmount = *req.MinAmount
+		}
+		if req.MaxAmount != nil {
+			maxAmount = *req.MaxAmount
+		}
+
+		seen := map[int64]bool{}
+		for i, amount := range req.PresetAmounts {
+			field := fmt.Sprintf("preset_amounts[%d]", i)
+			switch {
+			case amount < minAmount:
+				fieldErrs = append(fieldErrs, apperror.NewFieldError(field, apperror.CodeNumberTooSmall).WithExpect(fmt.Sprintf("at least %d", minAmount)))
+			case amount > maxAmount:
+				fieldErrs = append(fieldErrs, apperror.NewFieldError(field, apperror.CodeNumberTooLarge).WithExpect(fmt.Sprintf("at most %d", maxAmount)))
+			case seen[amount]:
+				fieldErrs = append(fieldErrs, apperror.NewFieldError(field, apperror.CodeFieldDuplicate))
+			}
+			seen[amount] = true
+		}
+	}
+
+	if len(fieldErrs) > 0 {
+		return nil, apperror.ValidationError("support settings validation error", fieldErrs)
+	}
+
+	settings := &CreatorSettings{
+		CreatorID:        creatorID,
+		MinSupportAmount: req.MinAmount,
+		MaxSupportAmount: req.MaxAmount,
+		PresetAmounts:    pq.Int64Array(req.PresetAmounts),
+	}
+	if settings.PresetAmounts == nil {
+		settings.PresetAmounts = pq.Int64Array{}
+	}
+
+	if err := s.supportRepo.UpsertCreatorSettings(ctx, settings); err != nil {
+		return nil, apperror.InternalServer("failed saving support settings").WithCause(err)
+	}
 
-	return timestampMillis, nil
+	return s.supportSettings(ctx, creatorID)
+}
+
+// validateMessage checks the message and display name of a donation. Spam is
+// rejected and profanity is masked, as the creator sees the message right
+// away without any review.
+func (s *SupportService) validateMessage(ctx context.Context, req *DonationRequest) *apperror.AppError {
+	req.Message = strings.TrimSpace(req.Message)
+	req.DisplayName = strings.TrimSpace(req.DisplayName)
+
+	var fieldErrs []apperror.FieldError
+	if len([]rune(req.Message)) > maxMessageLength {
+		fieldErrs = append(fieldErrs, apperror.NewFieldError("message", apperror.CodeFieldTooLong))
+	}
+	if len([]rune(req.DisplayName)) > maxDisplayNameLength {
+		fieldErrs = append(fieldErrs, apperror.NewFieldError("display_name", apperror.CodeFieldTooLong))
+	}
+
+	if len(fieldErrs) == 0 {
+		res, err := s.blocklist.Moderate(ctx, []moderation.Content{
+			{Field: "message", Text: req.Message},
+			{Field: "display_name", Text: req.DisplayName},
+		})
+		if err != nil {
+			return apperror.InternalServer("failed moderating support message").WithCause(err)
+		}
+		if res.Decision == moderation.Reject {
+			fieldErrs = res.FieldErrors
+		}
+	}
+
+	if len(fieldErrs) > 0 {
+		return apperror.ValidationError("donation validation error", fieldErrs)
+	}
+
+	req.Message = s.blocklist.Mask(req.Message)
+	req.DisplayName = s.blocklist.Mask(req.DisplayName)
+
+	return nil
 }
 
 func (s *SupportService) generateSupportID(timestamp int64, creatorID, fanID uint) string {
@@ -66,243 +227,459 @@ func (s *SupportService) generateSupportID(timestamp int64, creatorID, fanID uin
 	return fmt.Sprintf("SUPPORT/%d/%s/%d%d", timestamp, randomHex, creatorID, fanID)
 }
 
-func (s *SupportService) Donate(ctx context.Context, req DonationRequest) (string, *apperror.AppError) {
+func (s *SupportService) Donate(ctx context.Context, req DonationRequest) (*DonationResponse, *apperror.AppError) {
 	// Get and Check fanID
 	fanID := middleware.GetUserID(ctx)
 	if fanID == nil {
-		return "", apperror.Unauthorized("invalid user id", apperror.CodeUnauthorizedOperation)
+		return nil, apperror.Unauthorized("invalid user id", apperror.CodeUnauthorizedOperation)
 	}
 
 	fan, err := s.userRepo.FindByID(ctx, *fanID)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return "", apperror.Unauthorized("invalid credentials", apperror.CodeInvalidCredentials)
+			return nil, apperror.Unauthorized("invalid credentials", apperror.CodeInvalidCredentials)
 		}
 
-		return "", apperror.InternalServer("failed checking fan credentials").WithCause(err)
+		return nil, apperror.InternalServer("failed checking fan credentials").WithCause(err)
 	}
 
 	// Checking fan role
 	if fan.Role != "fan" {
-		return "", apperror.Forbidden("only fan can donate", apperror.CodeUnknown)
+		return nil, apperror.Forbidden("only fan can donate", apperror.CodeUnknown)
 	}
 
 	// Checking amount not zero or negative numbers
 	if req.Amount <= 0 {
-		return "", apperror.BadRequest("amount cannot be lower than or equal to 0", apperror.CodeNegativeNotAllowed)
+		return nil, apperror.BadRequest("amount cannot be lower than or equal to 0", apperror.CodeNegativeNotAllowed)
 	}
 
 	// Check Creator ID
 	creator, err := s.userRepo.FindByID(ctx, req.CreatorID)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return "", apperror.NotFound("creator not found", apperror.CodeResourceNotFound)
+			return nil, apperror.NotFound("creator not found", apperror.CodeResourceNotFound)
 		}
 
-		return "", apperror.InternalServer("failed checking creator id").WithCause(err)
+		return nil, apperror.InternalServer("failed checking creator id").WithCause(err)
 	}
 
 	// Checking creator role
 	if creator.Role != "creator" {
-		return "", apperror.BadRequest("you only allowed to donate to creator", apperror.CodeUnknown)
+		return nil, apperror.BadRequest("you only allowed to donate to creator", apperror.CodeUnknown)
 	}
 
-	// Get Duitku API Config
-	cfg := config.Load()
-	merchantCode := cfg.Duitku.MerchantCode
-	merchantKey := cfg.Duitku.MerchantKey
+	// Checking the support is attributed to one of the creator's posts
+	if req.PostID != nil {
+		ok, err := s.supportRepo.PostBelongsToCreator(ctx, *req.PostID, creator.ID)
+		if err != nil {
+			return nil, apperror.InternalServer("failed checking post id").WithCause(err)
+		}
 
-	// Get Timestamp
-	tmstp, e := s.generateTimestamp()
-	if e != nil {
-		return "", apperror.InternalServer("failed generating timestamp").WithCause(err)
+		if !ok {
+			return nil, apperror.ValidationError("donation validation error", []apperror.FieldError{
+				apperror.NewFieldError("post_id", apperror.CodeResourceNotFound),
+			})
+		}
 	}
 
-	// Generate Signature
-	signature := s.generateSignature(merchantCode, tmstp, merchantKey)
+	// Checking the campaign is the creator's and still open
+	if req.CampaignID != nil {
+		open, err := s.supportRepo.CampaignOpen(ctx, *req.CampaignID, creator.ID)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return nil, apperror.InternalServer("failed checking campaign id").WithCause(err)
+		}
 
-	// Generate Support ID
-	supportID := s.generateSupportID(tmstp, creator.ID, fan.ID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, apperror.ValidationError("donation validation error", []apperror.FieldError{
+				apperror.NewFieldError("campaign_id", apperror.CodeResourceNotFound),
+			})
+		}
 
-	appUrl := cfg.AppURL
+		if !open {
+			return nil, apperror.ValidationError("donation validation error", []apperror.FieldError{
+				apperror.NewFieldError("campaign_id", apperror.CodeSelectionInvalid).WithMessage("campaign is no longer accepting supports"),
+			})
+		}
+	}
 
-	// Construct Request Payload
-	payload := map[string]interface{}{
-		"paymentAmount":   req.Amount,
-		"merchantOrderId": supportID,
-		"productDetails":  fmt.Sprintf("%s provided support of IDR %d to %s", fan.Name, req.Amount, creator.Name),
-		"email":           fmt.Sprintf("%s@gmail.com", strings.ReplaceAll(fan.Phone, "+", "")),
-		"callbackUrl":     fmt.Sprintf("%s/api/payment/callback", appUrl),
-		"returnUrl":       "",
+	settings, appErr := s.supportSettings(ctx, creator.ID)
+	if appErr != nil {
+		return nil, appErr
 	}
 
-	url := "https://api-sandbox.duitku.com/api/merchant/createInvoice"
+	amount := int64(req.Amount)
+	if amount < settings.MinAmount {
+		return nil, apperror.ValidationError("donation validation error", []apperror.FieldError{
+			apperror.NewFieldError("amount", apperror.CodeNumberTooSmall).WithExpect(fmt.Sprintf("at least %d", settings.MinAmount)),
+		})
+	}
+	if amount > settings.MaxAmount {
+		return nil, apperror.ValidationError("donation validation error", []apperror.FieldError{
+			apperror.NewFieldError("amount", apperror.CodeNumberTooLarge).WithExpect(fmt.Sprintf("at most %d", settings.MaxAmount)),
+		})
+	}
 
-	body, _ := json.Marshal(payload)
-	httpReq, _ := http.NewRequest("POST", url, bytes.NewBuffer(body))
-	httpReq.Header.Set("Content-Type", "application/json")
-	httpReq.Header.Set("x-duitku-signature", signature)
+	if appErr := s.validateMessage(ctx, &req); appErr != nil {
+		return nil, appErr
+	}
 
-	tmstpStr := strconv.FormatInt(tmstp, 10)
-	httpReq.Header.Set("x-duitku-timestamp", tmstpStr)
-	httpReq.Header.Set("x-duitku-merchantcode", merchantCode)
+	timestamp := time.Now().UnixMilli()
+	supportID := s.generateSupportID(timestamp, creator.ID, fan.ID)
 
-	resp, err := http.DefaultClient.Do(httpReq)
+	invoice, err := s.gateway.CreateInvoice(ctx, payment.Invoice{
+		OrderID:        supportID,
+		Amount:         int64(req.Amount),
+		ProductDetails: fmt.Sprintf("%s provided support of IDR %d to %s", fan.Name, req.Amount, creator.Name),
+		CustomerName:   fan.Name,
+		Email:          fmt.Sprintf("%s@gmail.com", strings.ReplaceAll(fan.Phone, "+", "")),
+		CallbackURL:    fmt.Sprintf("%s/api/payment/callback", s.appURL),
+	})
 	if err != nil {
-		return "", apperror.InternalServer("failed hit Duitku API").WithCause(err)
+		return nil, apperror.Wrap(apperror.CodeExternalAPIRequestFailed, http.StatusBadGateway, "failed creating payment invoice", err)
 	}
-	defer resp.Body.Close()
-
-	var result struct {
-		StatusCode    string `json:"statusCode"`
-		StatusMessage string `json:"statusMessage"`
-		Reference     string `json:"reference"`
-		MerchantCode  string `json:"merchantCode"`
-		PaymentURL    string `json:"paymentUrl"`
-	}
-	json.NewDecoder(resp.Body).Decode(&result)
 
 	// Save Support
 	newSupport := &Support{
 		FanID:            fan.ID,
 		CreatorID:        creator.ID,
+		PostID:           req.PostID,
+		CampaignID:       req.CampaignID,
 		Amount:           decimal.NewFromInt(int64(req.Amount)),
 		SupportID:        supportID,
 		SentAt:           time.Now(),
-		ReferenceCode:    result.Reference,
-		Status:           "pending",
-		PaymentTimestamp: tmstp,
+		ReferenceCode:    invoice.Reference,
+		Status:           StatusPending,
+		PaymentTimestamp: timestamp,
+		StatusChangedAt:  time.Now(),
+		IsAnonymous:      req.IsAnonymous,
+	}
+	if req.Message != "" {
+		newSupport.Message = &req.Message
+	}
+	if req.DisplayName != "" {
+		newSupport.DisplayName = &req.DisplayName
 	}
 
-	if err := s.supportRepo.Create(ctx, newSupport); err != nil {
-		return "", apperror.InternalServer("failed creating new support record").WithCause(err)
+	if err := s.supportRepo.Insert(ctx, newSupport); err != nil {
+		return nil, apperror.InternalServer("failed creating new support record").WithCause(err)
 	}
 
-	return result.PaymentURL, nil
+	return &DonationResponse{PaymentURL: invoice.PaymentURL, SupportID: newSupport.ID}, nil
 }
 
-func (s *SupportService) verifySignature(merchantCode, amount, merchantOrderID, merchantKey, signature string) bool {
-	raw := merchantCode + amount + merchantOrderID + merchantKey
-	hash := md5.Sum([]byte(raw))
-	genSig := hex.EncodeToString(hash[:])
-	return genSig == signature
-}
+func (s *SupportService) PaymentCallback(ctx context.Context, form url.Values) (string, *apperror.AppError) {
+	const SUCCESS string = "SUCCESS"
 
-func (s *SupportService) PaymentCallback(ctx context.Context, req PaymentCallbackRequest) (string, *apperror.AppError) {
-	const (
-		IGNORED string = "IGNORED"
-		SUCCESS string = "SUCCESS"
-	)
+	cb, err := s.gateway.VerifyCallback(form)
+	if err != nil {
+		if errors.Is(err, payment.ErrInvalidSignature) {
+			return "", apperror.Forbidden("invalid signature", apperror.CodeUnknown)
+		}
+		return "", apperror.BadRequest("invalid callback payload", apperror.CodeUnknown)
+	}
 
-	log.Println("=== PaymentCallback START ===")
-	log.Println("Request received:", req)
+	if cb.MerchantCode != s.gateway.MerchantCode() {
+		s.recordAnomaly(ctx, AnomalyMerchantMismatch, cb, form, nil)
+		return "", apperror.BadRequest("callback merchant does not match", apperror.CodePaymentMismatch)
+	}
+
+	var reason string
+	if cb.Status != payment.StatusPaid {
+		reason = fmt.Sprintf("payment failed with result code %s", cb.ResultCode)
+	}
 
-	support, err := s.supportRepo.GetPaymentTimestamp(ctx, req.Reference, req.MerchantOrderID)
+	if appErr := s.settle(ctx, cb, form, reason); appErr != nil {
+		return "", appErr
+	}
+
+	return SUCCESS, nil
+}
+
+// settle applies a payment outcome reported by the gateway, either through a
+// callback or a status check, to its support. Repeating an outcome that was
+// already applied is a no-op.
+func (s *SupportService) settle(ctx context.Context, cb *payment.Callback, form url.Values, reason string) *apperror.AppError {
+	status := StatusPaid
+	var failureReason *string
+	if cb.Status != payment.StatusPaid {
+		status = StatusFailed
+		failureReason = &reason
+	}
+
+	support, applied, err := s.supportRepo.Settle(ctx, cb.OrderID, cb.Reference, cb.Amount, status, failureReason)
 	if err != nil {
-		log.Println("Failed to get payment timestamp:", err)
-		return "", apperror.InternalServer("failed get payment timestamp").WithCause(err)
+		switch {
+		case err == sql.ErrNoRows:
+			s.recordAnomaly(ctx, AnomalyUnknownOrder, cb, form, nil)
+			return apperror.NotFound("support not found", apperror.CodeResourceNotFound)
+		case errors.Is(err, ErrReferenceMismatch):
+			s.recordAnomaly(ctx, AnomalyReferenceMismatch, cb, form, support)
+			return apperror.BadRequest("callback reference does not match the support", apperror.CodePaymentMismatch)
+		case errors.Is(err, ErrAmountMismatch):
+			s.recordAnomaly(ctx, AnomalyAmountMismatch, cb, form, support)
+			return apperror.BadRequest("callback amount does not match the support", apperror.CodePaymentMismatch)
+		case errors.Is(err, ErrInvalidTransition):
+			log.Printf("payment %s for support %s which is already %s", status, cb.OrderID, support.Status)
+			return apperror.Conflict(fmt.Sprintf("support is already %s", support.Status), apperror.CodePaymentInvalidStatus)
+		}
+		return apperror.InternalServer("failed applying payment").WithCause(err)
+	}
+
+	// a retried callback for a support that already has the reported status
+	// is acknowledged without crediting the creator again
+	if !applied {
+		log.Println("payment already applied:", cb.OrderID)
+		return nil
 	}
 
-	cfg := config.Load()
-	merchantKey := cfg.Duitku.MerchantKey
+	if status != StatusPaid {
+		log.Println("payment failed:", cb.OrderID, reason)
+	}
+
+	return nil
+}
 
-	if !s.verifySignature(req.MerchantCode, req.Amount, req.MerchantOrderID, merchantKey, req.Signature) {
-		return "", apperror.Forbidden("invalid signature", apperror.CodeUnknown)
+// HandleSupportReceived runs the JobSupportReceived jobs of EventSupportPaid.
+func (s *SupportService) HandleSupportReceived(ctx context.Context, payload json.RawMessage) error {
+	var job SupportPaidEvent
+	if _, err := outbox.Decode(payload, &job); err != nil {
+		return err
 	}
-	log.Println("Signature verification PASSED")
 
-	if req.ResultCode != "00" {
-		log.Println("ResultCode not 00, ignoring transaction")
-		return IGNORED, nil
+	data := map[string]interface{}{
+		"amount":       job.Amount,
+		"reference":    job.Reference,
+		"fan_name":     job.FanName,
+		"is_anonymous": job.IsAnonymous,
+		"message":      job.Message,
+		"creator_name": job.CreatorName,
+		"creator_id":   job.CreatorID,
+	}
+	if job.FanID != nil {
+		data["fan_id"] = *job.FanID
 	}
-	log.Println("ResultCode is 00, processing transaction")
 
-	tx, err := s.supportRepo.DB.BeginTx(ctx, nil)
+	msg := socket.EventMessage{
+		Event: "support_received",
+		Data:  data,
+	}
+
+	return s.hub.SendToCreator(ctx, job.CreatorID, msg)
+}
+
+// ExpireStale checks up to limit supports still pending after ttl with the
+// gateway. The ones it paid or failed are settled, the ones it still has
+// pending are expired, the fan never finished paying them. A support the
+// gateway cannot answer about is retried on the next runs, and expired anyway
+// once it is pending for twice the ttl, a paid callback arriving later still
+// settles it. It returns how many were expired.
+func (s *SupportService) ExpireStale(ctx context.Context, ttl time.Duration, limit int) (int64, error) {
+	now := time.Now()
+	pending, err := s.supportRepo.FindPendingBefore(ctx, now.Add(-ttl), limit)
 	if err != nil {
-		log.Println("Failed to begin transaction:", err)
-		return "", apperror.InternalServer("failed begin transaction").WithCause(err)
+		return 0, err
 	}
-	defer tx.Rollback()
-	log.Println("Transaction started")
 
-	amountInt, err := strconv.ParseInt(req.Amount, 10, 64)
+	var expired int64
+	for _, sp := range pending {
+		if ctx.Err() != nil {
+			return expired, ctx.Err()
+		}
+
+		reason := "payment was not completed in time"
+		settled, appErr := s.reconcile(ctx, sp.SupportID)
+		if appErr != nil {
+			log.Printf("failed checking payment %s before expiring it: %v", sp.SupportID, appErr)
+			if now.Sub(sp.SentAt) < 2*ttl {
+				continue
+			}
+			reason = "payment was not completed in time, its status could not be checked"
+		}
+		if settled {
+			continue
+		}
+
+		ok, err := s.supportRepo.Expire(ctx, sp.SupportID, reason)
+		if err != nil {
+			return expired, err
+		}
+		if ok {
+			expired++
+		}
+	}
+
+	return expired, nil
+}
+
+// Reconcile asks the gateway about supports pending for longer than
+// olderThan, in case their callback never reached us, and settles the ones
+// the gateway already paid or failed. It returns how many were settled.
+func (s *SupportService) Reconcile(ctx context.Context, olderThan time.Duration, limit int) (int, error) {
+	pending, err := s.supportRepo.FindPendingBefore(ctx, time.Now().Add(-olderThan), limit)
 	if err != nil {
-		log.Println("Invalid amount:", req.Amount)
-		return "", apperror.BadRequest("invalid amount", apperror.CodeUnknown)
+		return 0, err
 	}
 
-	query := `
-    UPDATE balances
-    SET amount = amount + $1
-    WHERE user_id = $2
-`
-	res, err := tx.ExecContext(ctx, query, amountInt, support.CreatorID)
+	settled := 0
+	for _, sp := range pending {
+		if ctx.Err() != nil {
+			return settled, ctx.Err()
+		}
+
+		ok, appErr := s.reconcile(ctx, sp.SupportID)
+		if appErr != nil {
+			log.Printf("failed reconciling payment %s: %v", sp.SupportID, appErr)
+			continue
+		}
+		if ok {
+			settled++
+		}
+	}
+
+	return settled, nil
+}
+
+// reconcile settles a support with the status the gateway reports for it,
+// it reports false while the gateway still has the payment pending.
+func (s *SupportService) reconcile(ctx context.Context, orderID string) (bool, *apperror.AppError) {
+	st, err := s.gateway.CheckStatus(ctx, orderID)
+	if markErr := s.supportRepo.MarkChecked(ctx, orderID); markErr != nil {
+		log.Printf("failed marking payment %s checked: %v", orderID, markErr)
+	}
 	if err != nil {
-		log.Println("Failed updating balance:", err)
-		return "", apperror.InternalServer("failed updating balance").WithCause(err)
+		return false, apperror.Wrap(apperror.CodeExternalAPIRequestFailed, http.StatusBadGateway, "failed checking payment status", err)
+	}
+
+	if st.Status != payment.StatusPaid && st.Status != payment.StatusFailed {
+		return false, nil
+	}
+
+	cb := &payment.Callback{
+		MerchantCode: s.gateway.MerchantCode(),
+		OrderID:      orderID,
+		Reference:    st.Reference,
+		Amount:       st.Amount,
+		Status:       st.Status,
 	}
 
-	affected, _ := res.RowsAffected()
-	log.Println("Balance update affected rows:", affected)
+	if appErr := s.settle(ctx, cb, nil, "payment failed: "+st.Message); appErr != nil {
+		return false, appErr
+	}
+
+	return true, nil
+}
 
-	query = `
-		UPDATE supports
-		SET status = 'paid'
-		WHERE reference_code = $1
-		AND support_id = $2
-	`
-	res, err = tx.ExecContext(ctx, query, req.Reference, req.MerchantOrderID)
+// GetStatus returns a support of the fan. With refresh a pending support is
+// checked with the gateway first, in case its callback never reached us.
+func (s *SupportService) GetStatus(ctx context.Context, id uint, fanID *uint, refresh bool) (*SupportStatusResponse, *apperror.AppError) {
+	if fanID == nil {
+		return nil, apperror.Unauthorized("invalid user", apperror.CodeUnauthorizedOperation)
+	}
+
+	support, err := s.supportRepo.FindFanSupport(ctx, id, *fanID)
 	if err != nil {
-		log.Println("Failed updating support status:", err)
-		return "", apperror.InternalServer("failed updating payment status").WithCause(err)
+		if err == sql.ErrNoRows {
+			return nil, apperror.NotFound("support not found", apperror.CodeResourceNotFound)
+		}
+		return nil, apperror.InternalServer("failed get support").WithCause(err)
 	}
-	affected, _ = res.RowsAffected()
-	log.Println("Support status update affected rows:", affected)
 
-	if err := tx.Commit(); err != nil {
-		log.Println("Failed committing transaction:", err)
-		return "", apperror.InternalServer("failed committing transaction").WithCause(err)
+	if !refresh || support.Status != StatusPending || !refreshDue(support, time.Now()) {
+		return support, nil
 	}
-	log.Println("Transaction committed")
 
-	msg := socket.EventMessage{
-		Event: "support_received",
-		Data: map[string]interface{}{
-			"amount":       req.Amount,
-			"reference":    req.Reference,
-			"fan_name":     support.FanName,
-			"fan_id":       support.FanID,
-			"creator_name": support.CreatorName,
-			"creator_id":   support.CreatorID,
-		},
-	}
-
-	log.Println("Broadcasting event to creator:", support.CreatorID)
-	s.hub.BroadcastToCreator(support.CreatorID, msg)
-	log.Println("=== PaymentCallback END ===")
-	return SUCCESS, nil
+	// a failed refresh still answers with what we know
+	settled, appErr := s.reconcile(ctx, support.OrderID)
+	if appErr != nil {
+		log.Printf("failed refreshing payment %s: %v", support.OrderID, appErr)
+		return support, nil
+	}
+
+	if !settled {
+		return support, nil
+	}
+
+	support, err = s.supportRepo.FindFanSupport(ctx, id, *fanID)
+	if err != nil {
+		return nil, apperror.InternalServer("failed get support").WithCause(err)
+	}
+
+	return support, nil
+}
+
+// refreshInterval is how long a refresh waits after the support last changed
+// or was last checked, polling clients would otherwise hit the gateway on
+// every request.
+const refreshInterval = 30 * time.Second
+
+func refreshDue(support *SupportStatusResponse, now time.Time) bool {
+	last := support.StatusChangedAt
+	if support.LastCheckedAt != nil && support.LastCheckedAt.After(last) {
+		last = *support.LastCheckedAt
+	}
+	return now.Sub(last) >= refreshInterval
 }
 
-func (s *SupportService) GetSupporters(ctx context.Context, cursor *uint, creatorID uint) ([]BestSupporters, *uint, *apperror.AppError) {
+// recordAnomaly keeps a rejected callback for admins. Failing to record it
+// must not change the answer given to the gateway, so errors are only logged.
+func (s *SupportService) recordAnomaly(ctx context.Context, reason string, cb *payment.Callback, form url.Values, support *settledSupport) {
+	payload := []byte("{}")
+	if form != nil {
+		if b, err := json.Marshal(form); err == nil {
+			payload = b
+		}
+	}
+
+	anomaly := &PaymentAnomaly{
+		Reason:       reason,
+		OrderID:      cb.OrderID,
+		Reference:    cb.Reference,
+		MerchantCode: cb.MerchantCode,
+		Amount:       cb.Amount,
+		Payload:      payload,
+	}
+	if support != nil {
+		anomaly.ExpectedReference = &support.ReferenceCode
+		anomaly.ExpectedAmount = decimal.NewNullDecimal(support.Amount)
+	}
+
+	log.Printf("payment callback anomaly %s for order %s", reason, cb.OrderID)
+	if err := s.supportRepo.CreateAnomaly(ctx, anomaly); err != nil {
+		log.Println("failed recording payment anomaly:", err)
+	}
+}
+
+func (s *SupportService) FindAnomalies(ctx context.Context, params pagination.Params) (pagination.Page[PaymentAnomalyResponse], *apperror.AppError) {
+	page, err := s.supportRepo.GetAnomalies(ctx, params)
+	if err != nil {
+		if errors.Is(err, pagination.ErrInvalidCursor) {
+			return page, apperror.BadRequest("invalid cursor", apperror.CodeUnknown)
+		}
+		return page, apperror.InternalServer("failed get payment anomalies").WithCause(err)
+	}
+
+	return page, nil
+}
+
+func (s *SupportService) GetSupporters(ctx context.Context, params pagination.Params, creatorID uint) (pagination.Page[BestSupporters], *apperror.AppError) {
 	user, err := s.userRepo.FindByID(ctx, creatorID)
 	if err != nil {
-		return nil, nil, apperror.Unauthorized("Unauthorized", apperror.CodeUnauthorizedOperation)
+		return pagination.Page[BestSupporters]{}, apperror.Unauthorized("Unauthorized", apperror.CodeUnauthorizedOperation)
 	}
 
 	if user.Role != "creator" {
-		return nil, nil, apperror.Unauthorized("Unauthorized", apperror.CodeUnauthorizedOperation)
+		return pagination.Page[BestSupporters]{}, apperror.Unauthorized("Unauthorized", apperror.CodeUnauthorizedOperation)
 	}
 
-	bestSupports, nextCursor, err := s.supportRepo.GetCreatorSupporters(ctx, cursor, user.ID)
+	page, err := s.supportRepo.GetCreatorSupporters(ctx, params, user.ID)
 	if err != nil {
-		return nil, nil, apperror.InternalServer("failed to get creator's best supporters").WithCause(err)
-	}
-
-	if len(bestSupports) == 0 {
-		return []BestSupporters{}, nil, nil
+		if errors.Is(err, pagination.ErrInvalidCursor) {
+			return page, apperror.BadRequest("invalid cursor", apperror.CodeUnknown)
+		}
+		return page, apperror.InternalServer("failed to get creator's best supporters").WithCause(err)
 	}
 
-	return bestSupports, nextCursor, nil
+	return page, nil
 }
 
 func (s *SupportService) GetFanSpending(ctx context.Context, fanID uint) (int64, *apperror.AppError) {
@@ -323,20 +700,42 @@ func (s *SupportService) GetFanSpending(ctx context.Context, fanID uint) (int64,
 	return amount, nil
 }
 
-func (s *SupportService) GetFanSupportHistory(ctx context.Context, cursor, fanID *uint) ([]FanSupportHistory, *uint, *apperror.AppError) {
+func (s *SupportService) GetFanSupportHistory(ctx context.Context, params pagination.Params, fanID *uint) (pagination.Page[FanSupportHistory], *apperror.AppError) {
+	if fanID == nil {
+		return pagination.Page[FanSupportHistory]{}, apperror.Unauthorized("invalid user", apperror.CodeUnauthorizedOperation)
+	}
+
 	user, err := s.userRepo.FindByID(ctx, *fanID)
 	if err != nil {
-		return nil, nil, apperror.Unauthorized("invalid user", apperror.CodeUnauthorizedOperation)
+		return pagination.Page[FanSupportHistory]{}, apperror.Unauthorized("invalid user", apperror.CodeUnauthorizedOperation)
 	}
 
 	if user.Role != "fan" {
-		return nil, nil, apperror.Unauthorized("invalid role", apperror.CodeUnauthorizedOperation)
+		return pagination.Page[FanSupportHistory]{}, apperror.Unauthorized("invalid role", apperror.CodeUnauthorizedOperation)
+	}
+
+	page, err := s.supportRepo.GetFanSupportHistory(ctx, params, fanID)
+	if err != nil {
+		if errors.Is(err, pagination.ErrInvalidCursor) {
+			return page, apperror.BadRequest("invalid cursor", apperror.CodeUnknown)
+		}
+		return page, apperror.InternalServer("failed get fan spending history").WithCause(err)
 	}
 
-	histories, nextCursor, err := s.supportRepo.GetFanSupportHistory(ctx, cursor, fanID)
+	return page, nil
+}
+
+// SetMessageHidden hides or shows the message of one of the creator's
+// supports.
+func (s *SupportService) SetMessageHidden(ctx context.Context, supportID, creatorID uint, hidden bool) *apperror.AppError {
+	updated, err := s.supportRepo.SetMessageHidden(ctx, supportID, creatorID, hidden)
 	if err != nil {
-		return nil, nil, apperror.InternalServer("failed get fan spending history").WithCause(err)
+		return apperror.InternalServer("failed updating support message visibility").WithCause(err)
+	}
+
+	if !updated {
+		return apperror.NotFound("support not found", apperror.CodeResourceNotFound)
 	}
 
-	return histories, nextCursor, nil
+	return nil
 }
@@ -0,0 +1,144 @@
+package support
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	_ "github.com/lib/pq"
+	"github.com/rxmy43/support-platform/internal/config"
+	"github.com/rxmy43/support-platform/internal/moderation"
+	"github.com/rxmy43/support-platform/internal/modules/balance"
+	"github.com/rxmy43/support-platform/internal/modules/user"
+	"github.com/rxmy43/support-platform/internal/payment"
+	"github.com/rxmy43/support-platform/internal/socket"
+	"github.com/shopspring/decimal"
+)
+
+// TestPaymentCallbackConcurrentDuplicates fires the same paid callback many
+// times at once and checks the creator is credited exactly once. It needs a
+// migrated database in TEST_DATABASE_URL.
+func TestPaymentCallbackConcurrentDuplicates(t *testing.T) {
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+
+	db, err := sqlx.Connect("postgres", dsn)
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+	suffix := time.Now().UnixNano()
+
+	var creatorID, fanID uint
+	insertUser := "INSERT INTO users (name, handle, phone, role) VALUES ($1, $2, $3, $4) RETURNING id"
+	if err := db.GetContext(ctx, &creatorID, insertUser, "Creator", fmt.Sprintf("creator-%d", suffix), fmt.Sprintf("+1%d", suffix%1e12), "creator"); err != nil {
+		t.Fatalf("insert creator: %v", err)
+	}
+	if err := db.GetContext(ctx, &fanID, insertUser, "Fan", fmt.Sprintf("fan-%d", suffix), fmt.Sprintf("+2%d", suffix%1e12), "fan"); err != nil {
+		t.Fatalf("insert fan: %v", err)
+	}
+	t.Cleanup(func() {
+		db.Exec("DELETE FROM users WHERE id IN ($1, $2)", creatorID, fanID)
+	})
+
+	if _, err := db.ExecContext(ctx, "INSERT INTO balances (user_id, amount) VALUES ($1, 0)", creatorID); err != nil {
+		t.Fatalf("insert balance: %v", err)
+	}
+
+	const amount = 50000
+	orderID := fmt.Sprintf("SUPPORT/TEST/%d", suffix)
+	reference := fmt.Sprintf("REF%d", suffix)
+
+	_, err = db.ExecContext(ctx, `
+		INSERT INTO supports (fan_id, creator_id, amount, status, support_id, reference_code, payment_timestamp)
+		VALUES ($1, $2, $3, 'pending', $4, $5, $6)
+	`, fanID, creatorID, amount, orderID, reference, suffix)
+	if err != nil {
+		t.Fatalf("insert support: %v", err)
+	}
+
+	gateway := payment.NewFake("", "FAKE")
+	service := NewSupportService(NewSupportRepo(db), user.NewUserRepo(db), balance.NewBalanceRepo(db), socket.NewHub(), gateway, moderation.NewBlocklist(nil), config.SupportLimitsConfig{MinAmount: 1, MaxAmount: 100000000}, "")
+	form := gateway.CallbackForm(orderID, reference, amount, true)
+
+	const callbacks = 20
+	var wg sync.WaitGroup
+	results := make([]string, callbacks)
+	errs := make([]error, callbacks)
+
+	for i := 0; i < callbacks; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			status, appErr := service.PaymentCallback(ctx, form)
+			results[i] = status
+			if appErr != nil {
+				errs[i] = appErr
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	for i := range results {
+		if errs[i] != nil {
+			t.Fatalf("callback %d failed: %v", i, errs[i])
+		}
+		if results[i] != "SUCCESS" {
+			t.Fatalf("callback %d answered %q, want SUCCESS", i, results[i])
+		}
+	}
+
+	var credited decimal.Decimal
+	if err := db.GetContext(ctx, &credited, "SELECT amount FROM balances WHERE user_id = $1", creatorID); err != nil {
+		t.Fatalf("get balance: %v", err)
+	}
+	if !credited.Equal(decimal.NewFromInt(amount)) {
+		t.Fatalf("creator balance is %s, want %d", credited, amount)
+	}
+
+	var status string
+	if err := db.GetContext(ctx, &status, "SELECT status FROM supports WHERE support_id = $1", orderID); err != nil {
+		t.Fatalf("get support: %v", err)
+	}
+	if status != "paid" {
+		t.Fatalf("support status is %q, want paid", status)
+	}
+}
+
+func TestRefreshDue(t *testing.T) {
+	now := time.Now()
+	ago := func(d time.Duration) *time.Time {
+		at := now.Add(-d)
+		return &at
+	}
+
+	tests := []struct {
+		name        string
+		changedAt   time.Time
+		lastChecked *time.Time
+		want        bool
+	}{
+		{"just created", now.Add(-5 * time.Second), nil, false},
+		{"never checked", now.Add(-time.Minute), nil, true},
+		{"checked recently", now.Add(-time.Hour), ago(10 * time.Second), false},
+		{"checked long ago", now.Add(-time.Hour), ago(time.Minute), true},
+		{"changed after the last check", now.Add(-5 * time.Second), ago(time.Hour), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			support := &SupportStatusResponse{StatusChangedAt: tt.changedAt, LastCheckedAt: tt.lastChecked}
+			if got := refreshDue(support, now); got != tt.want {
+				t.Fatalf("refresh due is %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
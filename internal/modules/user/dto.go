@@ -0,0 +1,13 @@
+package user
+
+type UserResponse struct {
+	ID     uint   `json:"id"`
+	Name   string `json:"name"`
+	Handle string `json:"handle"`
+	Phone  string `json:"phone"`
+	Role   string `json:"role"`
+}
+
+type HandleUpdateRequest struct {
+	Handle string `json:"handle"`
+}
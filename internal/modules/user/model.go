@@ -1,8 +1,12 @@
 package user
 
+import "time"
+
 type User struct {
-	ID    uint   `db:"id"`
-	Name  string `db:"name"`
-	Phone string `db:"phone"`
-	Role  string `db:"role"`
+	ID          uint       `db:"id"`
+	Name        string     `db:"name"`
+	Handle      string     `db:"handle"`
+	Phone       string     `db:"phone"`
+	Role        string     `db:"role"`
+	TakenDownAt *time.Time `db:"taken_down_at"`
 }
@@ -22,9 +22,30 @@ func NewUserRepo(DB *sqlx.DB) *UserRepo {
 
 func (r *UserRepo) FindOneByPhone(ctx context.Context, phone string) (*User, error) {
 	var u User
-	err := r.DB.GetContext(ctx, &u, "SELECT id, name, phone, role FROM users WHERE phone = $1 LIMIT 1", phone)
+	err := r.DB.GetContext(ctx, &u, "SELECT id, name, handle, phone, role FROM users WHERE phone = $1 LIMIT 1", phone)
 	if err != nil {
 		return nil, err
 	}
 	return &u, nil
 }
+
+// UpdateHandle sets the handle of the user and returns the updated user.
+func (r *UserRepo) UpdateHandle(ctx context.Context, id uint, handle string) (*User, error) {
+	var u User
+	query := "UPDATE users SET handle = $2 WHERE id = $1 RETURNING *"
+	if err := r.DB.GetContext(ctx, &u, query, id, handle); err != nil {
+		return nil, err
+	}
+	return &u, nil
+}
+
+// FindCreatorByHandle returns the creator with the handle unless they were
+// taken down.
+func (r *UserRepo) FindCreatorByHandle(ctx context.Context, handle string) (*User, error) {
+	var u User
+	query := "SELECT * FROM users WHERE handle = $1 AND role = 'creator' AND taken_down_at IS NULL LIMIT 1"
+	if err := r.DB.GetContext(ctx, &u, query, handle); err != nil {
+		return nil, err
+	}
+	return &u, nil
+}
@@ -0,0 +1,108 @@
+package user
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"regexp"
+	"strings"
+
+	"github.com/lib/pq"
+	"github.com/rxmy43/support-platform/internal/apperror"
+)
+
+// maxHandleLength and handlePattern mirror the users.handle column and its
+// users_handle_format_check constraint.
+const maxHandleLength = 50
+
+var handlePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]*$`)
+
+type UserService struct {
+	userRepo *UserRepo
+}
+
+func NewUserService(userRepo *UserRepo) *UserService {
+	return &UserService{
+		userRepo: userRepo,
+	}
+}
+
+func toResponse(u *User) *UserResponse {
+	return &UserResponse{
+		ID:     u.ID,
+		Name:   u.Name,
+		Handle: u.Handle,
+		Phone:  u.Phone,
+		Role:   u.Role,
+	}
+}
+
+func (s *UserService) GetMe(ctx context.Context, userID uint) (*UserResponse, *apperror.AppError) {
+	u, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, apperror.NotFound("user not found", apperror.CodeResourceNotFound)
+		}
+		return nil, apperror.InternalServer("failed find user").WithCause(err)
+	}
+
+	return toResponse(u), nil
+}
+
+// CheckAdmin reads the role of the user from the database, the role header
+// of a request is set by the client and cannot be trusted for admin access.
+func (s *UserService) CheckAdmin(ctx context.Context, userID uint) *apperror.AppError {
+	u, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return apperror.Unauthorized("invalid user", apperror.CodeUnauthorizedOperation)
+		}
+		return apperror.InternalServer("failed checking admin").WithCause(err)
+	}
+
+	if u.Role != "admin" {
+		return apperror.Forbidden("only admin allowed to access this resource", apperror.CodeAdminAccessRequired)
+	}
+
+	return nil
+}
+
+func validateHandle(handle string) []apperror.FieldError {
+	switch {
+	case handle == "":
+		return []apperror.FieldError{apperror.NewFieldError("handle", apperror.CodeFieldRequired)}
+	case len(handle) > maxHandleLength:
+		return []apperror.FieldError{apperror.NewFieldError("handle", apperror.CodeFieldTooLong)}
+	case !handlePattern.MatchString(handle):
+		return []apperror.FieldError{
+			apperror.NewFieldError("handle", apperror.CodeFieldInvalidFormat).
+				WithExpect("lowercase letters, digits and dashes, not starting with a dash"),
+		}
+	}
+	return nil
+}
+
+// UpdateHandle changes the handle feeds and profile links are built from.
+// Handles are matched lowercase, so the new one is lowercased first.
+func (s *UserService) UpdateHandle(ctx context.Context, userID uint, req HandleUpdateRequest) (*UserResponse, *apperror.AppError) {
+	handle := strings.ToLower(strings.TrimSpace(req.Handle))
+	if fieldErrs := validateHandle(handle); len(fieldErrs) > 0 {
+		return nil, apperror.ValidationError("handle validation error", fieldErrs)
+	}
+
+	u, err := s.userRepo.UpdateHandle(ctx, userID, handle)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code.Name() == "unique_violation" {
+			return nil, apperror.ValidationError("handle validation error", []apperror.FieldError{
+				apperror.NewFieldError("handle", apperror.CodeFieldDuplicate),
+			})
+		}
+		if err == sql.ErrNoRows {
+			return nil, apperror.NotFound("user not found", apperror.CodeResourceNotFound)
+		}
+		return nil, apperror.InternalServer("failed update handle").WithCause(err)
+	}
+
+	return toResponse(u), nil
+}
@@ -0,0 +1,41 @@
+package user
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/rxmy43/support-platform/internal/apperror"
+)
+
+func TestValidateHandle(t *testing.T) {
+	tests := []struct {
+		name   string
+		handle string
+		code   apperror.ErrorCode
+	}{
+		{"valid", "jane-doe-2", ""},
+		{"digits only", "42", ""},
+		{"longest allowed", strings.Repeat("a", maxHandleLength), ""},
+		{"empty", "", apperror.CodeFieldRequired},
+		{"too long", strings.Repeat("a", maxHandleLength+1), apperror.CodeFieldTooLong},
+		{"leading dash", "-jane", apperror.CodeFieldInvalidFormat},
+		{"uppercase", "Jane", apperror.CodeFieldInvalidFormat},
+		{"space", "jane doe", apperror.CodeFieldInvalidFormat},
+		{"underscore", "jane_doe", apperror.CodeFieldInvalidFormat},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := validateHandle(tt.handle)
+			if tt.code == "" {
+				if len(errs) > 0 {
+					t.Fatalf("got errors %v, want none", errs)
+				}
+				return
+			}
+			if len(errs) != 1 || errs[0].Code != tt.code {
+				t.Fatalf("got errors %v, want %s", errs, tt.code)
+			}
+		})
+	}
+}
@@ -0,0 +1,30 @@
+package webhook
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type WebhookCreateRequest struct {
+	URL string `json:"url"`
+}
+
+type WebhookResponse struct {
+	ID        uint      `json:"id" db:"id"`
+	URL       string    `json:"url" db:"url"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// CreatedWebhookResponse is the only time the signing secret is shown.
+type CreatedWebhookResponse struct {
+	WebhookResponse
+	Secret string `json:"secret"`
+}
+
+// Delivery is the JSON body posted to webhooks.
+type Delivery struct {
+	ID        uint            `json:"id"`
+	Type      string          `json:"type"`
+	CreatedAt time.Time       `json:"created_at"`
+	Data      json.RawMessage `json:"data"`
+}
@@ -0,0 +1,30 @@
+package webhook
+
+import (
+	"time"
+
+	"github.com/rxmy43/support-platform/internal/outbox"
+)
+
+type Webhook struct {
+	ID        uint      `db:"id"`
+	CreatorID uint      `db:"creator_id"`
+	URL       string    `db:"url"`
+	Secret    string    `db:"secret"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+// MaxWebhooksPerCreator caps how many endpoints a creator can register.
+const MaxWebhooksPerCreator = 5
+
+const (
+	// JobDispatch queues a JobDeliver for every webhook of the event owner.
+	JobDispatch = "webhook.dispatch"
+	// JobDeliver posts one event to one webhook.
+	JobDeliver = "webhook.deliver"
+)
+
+type deliverJob struct {
+	WebhookID uint         `json:"webhook_id"`
+	Event     outbox.Event `json:"event"`
+}
@@ -0,0 +1,103 @@
+package webhook
+
+import (
+	"context"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/rxmy43/support-platform/internal/outbox"
+	"github.com/rxmy43/support-platform/internal/queue"
+	"github.com/rxmy43/support-platform/internal/repo"
+)
+
+type WebhookRepo struct {
+	*repo.BaseRepo[Webhook]
+}
+
+func NewWebhookRepo(DB *sqlx.DB) *WebhookRepo {
+	return &WebhookRepo{
+		BaseRepo: &repo.BaseRepo[Webhook]{
+			DB:        DB,
+			TableName: "webhooks",
+		},
+	}
+}
+
+// Insert stores the webhook unless the creator already has the maximum, it
+// reports false then.
+func (r *WebhookRepo) Insert(ctx context.Context, w *Webhook) (bool, error) {
+	tx, err := r.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	// serializes concurrent inserts of the same creator
+	if _, err := tx.ExecContext(ctx, "SELECT id FROM users WHERE id = $1 FOR UPDATE", w.CreatorID); err != nil {
+		return false, err
+	}
+
+	var count int
+	if err := tx.GetContext(ctx, &count, "SELECT COUNT(*) FROM webhooks WHERE creator_id = $1", w.CreatorID); err != nil {
+		return false, err
+	}
+
+	if count >= MaxWebhooksPerCreator {
+		return false, nil
+	}
+
+	query := `
+		INSERT INTO webhooks (creator_id, url, secret)
+		VALUES ($1, $2, $3)
+		RETURNING id, created_at
+	`
+	if err := tx.QueryRowxContext(ctx, query, w.CreatorID, w.URL, w.Secret).Scan(&w.ID, &w.CreatedAt); err != nil {
+		return false, err
+	}
+
+	return true, tx.Commit()
+}
+
+func (r *WebhookRepo) FindByCreator(ctx context.Context, creatorID uint) ([]WebhookResponse, error) {
+	webhooks := []WebhookResponse{}
+	err := r.DB.SelectContext(ctx, &webhooks, "SELECT id, url, created_at FROM webhooks WHERE creator_id = $1 ORDER BY id", creatorID)
+	return webhooks, err
+}
+
+// DeleteOwn deletes a webhook of the creator, it reports false when the
+// creator has no such webhook.
+func (r *WebhookRepo) DeleteOwn(ctx context.Context, id, creatorID uint) (bool, error) {
+	res, err := r.DB.ExecContext(ctx, "DELETE FROM webhooks WHERE id = $1 AND creator_id = $2", id, creatorID)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected > 0, nil
+}
+
+// QueueDeliveries queues a JobDeliver of the event for every webhook of its
+// owner, in one transaction so a failed dispatch queues none of them.
+func (r *WebhookRepo) QueueDeliveries(ctx context.Context, event outbox.Event) error {
+	tx, err := r.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var ids []uint
+	if err := tx.SelectContext(ctx, &ids, "SELECT id FROM webhooks WHERE creator_id = $1", event.UserID); err != nil {
+		return err
+	}
+
+	for _, id := range ids {
+		if err := queue.Enqueue(ctx, tx, JobDeliver, deliverJob{WebhookID: id, Event: event}); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
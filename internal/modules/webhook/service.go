@@ -0,0 +1,224 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/rxmy43/support-platform/internal/apperror"
+	"github.com/rxmy43/support-platform/internal/outbox"
+)
+
+type WebhookService struct {
+	webhookRepo *WebhookRepo
+	httpClient  *http.Client
+	// development accepts plain http endpoints and private addresses, for
+	// local development
+	development bool
+}
+
+func NewWebhookService(webhookRepo *WebhookRepo, development bool) *WebhookService {
+	dialer := &net.Dialer{Timeout: 5 * time.Second}
+	if !development {
+		// checked again on the address actually dialed, so a host resolving
+		// to a public address at registration cannot be rebound later
+		dialer.Control = func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || !publicIP(ip) {
+				return fmt.Errorf("webhook address %s is not public", host)
+			}
+			return nil
+		}
+	}
+
+	return &WebhookService{
+		webhookRepo: webhookRepo,
+		httpClient: &http.Client{
+			Timeout:   10 * time.Second,
+			Transport: &http.Transport{DialContext: dialer.DialContext, TLSHandshakeTimeout: 5 * time.Second},
+		},
+		development: development,
+	}
+}
+
+// publicIP reports whether ip is routable on the internet, webhooks must not
+// reach the loopback, private or link-local networks of the server.
+func publicIP(ip net.IP) bool {
+	return !ip.IsLoopback() && !ip.IsPrivate() && !ip.IsUnspecified() &&
+		!ip.IsLinkLocalUnicast() && !ip.IsLinkLocalMulticast() &&
+		!ip.IsInterfaceLocalMulticast() && !ip.IsMulticast()
+}
+
+func (s *WebhookService) validateURL(ctx context.Context, raw string) bool {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil || u.Hostname() == "" {
+		return false
+	}
+
+	if s.development {
+		return u.Scheme == "https" || u.Scheme == "http"
+	}
+
+	if u.Scheme != "https" {
+		return false
+	}
+
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, u.Hostname())
+	if err != nil || len(addrs) == 0 {
+		return false
+	}
+
+	for _, addr := range addrs {
+		if !publicIP(addr.IP) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func generateSecret() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "whsec_" + hex.EncodeToString(b), nil
+}
+
+func (s *WebhookService) Create(ctx context.Context, creatorID uint, req WebhookCreateRequest) (*CreatedWebhookResponse, *apperror.AppError) {
+	if !s.validateURL(ctx, req.URL) {
+		return nil, apperror.ValidationError("create webhook validation error", []apperror.FieldError{
+			apperror.NewFieldError("url", apperror.CodeURLInvalid).WithExpect("absolute https url of a public host"),
+		})
+	}
+
+	secret, err := generateSecret()
+	if err != nil {
+		return nil, apperror.InternalServer("failed generating webhook secret").WithCause(err)
+	}
+
+	w := &Webhook{
+		CreatorID: creatorID,
+		URL:       strings.TrimSpace(req.URL),
+		Secret:    secret,
+	}
+
+	created, err := s.webhookRepo.Insert(ctx, w)
+	if err != nil {
+		return nil, apperror.InternalServer("failed creating webhook").WithCause(err)
+	}
+
+	if !created {
+		return nil, apperror.Conflict(fmt.Sprintf("a creator can have at most %d webhooks", MaxWebhooksPerCreator), apperror.CodeFieldOutOfRange)
+	}
+
+	return &CreatedWebhookResponse{
+		WebhookResponse: WebhookResponse{ID: w.ID, URL: w.URL, CreatedAt: w.CreatedAt},
+		Secret:          w.Secret,
+	}, nil
+}
+
+func (s *WebhookService) FindAll(ctx context.Context, creatorID uint) ([]WebhookResponse, *apperror.AppError) {
+	webhooks, err := s.webhookRepo.FindByCreator(ctx, creatorID)
+	if err != nil {
+		return nil, apperror.InternalServer("failed get webhooks").WithCause(err)
+	}
+
+	return webhooks, nil
+}
+
+func (s *WebhookService) Delete(ctx context.Context, id, creatorID uint) *apperror.AppError {
+	deleted, err := s.webhookRepo.DeleteOwn(ctx, id, creatorID)
+	if err != nil {
+		return apperror.InternalServer("failed deleting webhook").WithCause(err)
+	}
+
+	if !deleted {
+		return apperror.NotFound("webhook not found", apperror.CodeResourceNotFound)
+	}
+
+	return nil
+}
+
+// HandleDispatch runs the JobDispatch jobs of outbox events.
+func (s *WebhookService) HandleDispatch(ctx context.Context, payload json.RawMessage) error {
+	var event outbox.Event
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return err
+	}
+
+	return s.webhookRepo.QueueDeliveries(ctx, event)
+}
+
+// HandleDeliver runs the JobDeliver jobs. The body is signed with the
+// webhook secret: the X-Webhook-Signature header is
+// sha256=hex(hmac_sha256(secret, timestamp + "." + body)), with the
+// timestamp from X-Webhook-Timestamp.
+func (s *WebhookService) HandleDeliver(ctx context.Context, payload json.RawMessage) error {
+	var job deliverJob
+	if err := json.Unmarshal(payload, &job); err != nil {
+		return err
+	}
+
+	w, err := s.webhookRepo.FindByID(ctx, job.WebhookID)
+	if err != nil {
+		// the creator removed the webhook since
+		if err == sql.ErrNoRows {
+			return nil
+		}
+		return err
+	}
+
+	body, err := json.Marshal(Delivery{
+		ID:        job.Event.ID,
+		Type:      job.Event.Type,
+		CreatedAt: job.Event.CreatedAt,
+		Data:      job.Event.Payload,
+	})
+	if err != nil {
+		return err
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	mac := hmac.New(sha256.New, []byte(w.Secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Webhook-Event", job.Event.Type)
+	req.Header.Set("X-Webhook-Delivery", strconv.FormatUint(uint64(job.Event.ID), 10))
+	req.Header.Set("X-Webhook-Timestamp", timestamp)
+	req.Header.Set("X-Webhook-Signature", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook %d answered http %d", w.ID, resp.StatusCode)
+	}
+
+	return nil
+}
@@ -0,0 +1,166 @@
+// Package outbox stores domain events in the same transaction as the change
+// they describe, and dispatches them to their subscribers through the job
+// queue. An event is turned into its subscriber jobs exactly once, and each
+// job is then retried until it succeeds, so subscribers see every event at
+// least once.
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/rxmy43/support-platform/internal/queue"
+)
+
+// Event is also the payload of the subscriber jobs.
+type Event struct {
+	ID           uint            `json:"id" db:"id"`
+	Type         string          `json:"type" db:"type"`
+	UserID       uint            `json:"user_id" db:"user_id"`
+	Payload      json.RawMessage `json:"payload" db:"payload"`
+	CreatedAt    time.Time       `json:"created_at" db:"created_at"`
+	DispatchedAt *time.Time      `json:"-" db:"dispatched_at"`
+}
+
+// Decode reads a subscriber job payload back into the event and its payload.
+func Decode(job json.RawMessage, payload any) (*Event, error) {
+	var e Event
+	if err := json.Unmarshal(job, &e); err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(e.Payload, payload); err != nil {
+		return nil, err
+	}
+
+	return &e, nil
+}
+
+// Write records an event belonging to userID within tx.
+func Write(ctx context.Context, tx sqlx.ExtContext, eventType string, userID uint, payload any) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, "INSERT INTO outbox_events (type, user_id, payload) VALUES ($1, $2, $3)", eventType, userID, data)
+	return err
+}
+
+// PruneDispatched deletes the events dispatched before the given time and
+// returns how many were deleted.
+func PruneDispatched(ctx context.Context, db *sqlx.DB, before time.Time) (int64, error) {
+	res, err := db.ExecContext(ctx, "DELETE FROM outbox_events WHERE dispatched_at < $1", before)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
+
+// dispatchBatch caps how many events one dispatch transaction handles.
+const dispatchBatch = 100
+
+// Dispatcher turns events into a queue job for every job type subscribed to
+// them.
+type Dispatcher struct {
+	db           *sqlx.DB
+	pollInterval time.Duration
+
+	mu   sync.RWMutex
+	subs map[string][]string
+}
+
+func NewDispatcher(db *sqlx.DB, pollInterval time.Duration) *Dispatcher {
+	return &Dispatcher{
+		db:           db,
+		pollInterval: pollInterval,
+		subs:         map[string][]string{},
+	}
+}
+
+// Subscribe queues a jobType job, with the event as payload, for every
+// eventType event.
+func (d *Dispatcher) Subscribe(eventType, jobType string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.subs[eventType] = append(d.subs[eventType], jobType)
+}
+
+func (d *Dispatcher) subscribers(eventType string) []string {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.subs[eventType]
+}
+
+// Run dispatches events until ctx is cancelled.
+func (d *Dispatcher) Run(ctx context.Context) {
+	for ctx.Err() == nil {
+		n, err := d.dispatch(ctx)
+		if err != nil && ctx.Err() == nil {
+			log.Println("failed dispatching outbox events:", err)
+		}
+
+		// keep going while there is a backlog
+		if n == dispatchBatch {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+		case <-time.After(d.pollInterval):
+		}
+	}
+}
+
+// dispatch queues the subscriber jobs of a batch of events and marks them
+// dispatched, all in one transaction. Events are locked with SKIP LOCKED so
+// several dispatchers can run side by side.
+func (d *Dispatcher) dispatch(ctx context.Context) (int, error) {
+	tx, err := d.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	events := []Event{}
+	query := `
+		SELECT * FROM outbox_events
+		WHERE dispatched_at IS NULL
+		ORDER BY id
+		FOR UPDATE SKIP LOCKED
+		LIMIT $1
+	`
+	if err := tx.SelectContext(ctx, &events, query, dispatchBatch); err != nil {
+		return 0, err
+	}
+
+	if len(events) == 0 {
+		return 0, nil
+	}
+
+	ids := make([]int64, 0, len(events))
+	for _, e := range events {
+		for _, jobType := range d.subscribers(e.Type) {
+			if err := queue.Enqueue(ctx, tx, jobType, e); err != nil {
+				return 0, err
+			}
+		}
+		ids = append(ids, int64(e.ID))
+	}
+
+	if _, err := tx.ExecContext(ctx, "UPDATE outbox_events SET dispatched_at = NOW() WHERE id = ANY($1)", pq.Array(ids)); err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+
+	return len(events), nil
+}
@@ -0,0 +1,179 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	_ "github.com/lib/pq"
+	"github.com/rxmy43/support-platform/internal/queue"
+)
+
+type testPayload struct {
+	SupportID uint  `json:"support_id"`
+	Amount    int64 `json:"amount"`
+}
+
+func TestDecode(t *testing.T) {
+	job, _ := json.Marshal(Event{
+		ID:      3,
+		Type:    "support.paid",
+		UserID:  7,
+		Payload: json.RawMessage(`{"support_id":11,"amount":50000}`),
+	})
+
+	tests := []struct {
+		name    string
+		job     json.RawMessage
+		want    testPayload
+		wantErr bool
+	}{
+		{"event job", job, testPayload{SupportID: 11, Amount: 50000}, false},
+		{"not json", json.RawMessage(`support.paid`), testPayload{}, true},
+		{"payload of another shape", json.RawMessage(`{"id":3,"payload":{"amount":"lots"}}`), testPayload{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var payload testPayload
+			e, err := Decode(tt.job, &payload)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error is %v, want error %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if e.ID != 3 || e.Type != "support.paid" || e.UserID != 7 {
+				t.Fatalf("unexpected event %+v", e)
+			}
+			if payload != tt.want {
+				t.Fatalf("payload is %+v, want %+v", payload, tt.want)
+			}
+		})
+	}
+}
+
+func TestSubscribe(t *testing.T) {
+	d := NewDispatcher(nil, time.Second)
+	d.Subscribe("support.paid", "notification.support_paid")
+	d.Subscribe("support.paid", "webhook.support_paid")
+	d.Subscribe("campaign.reached", "notification.campaign_reached")
+
+	tests := []struct {
+		eventType string
+		want      []string
+	}{
+		{"support.paid", []string{"notification.support_paid", "webhook.support_paid"}},
+		{"campaign.reached", []string{"notification.campaign_reached"}},
+		{"post.published", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.eventType, func(t *testing.T) {
+			if got := d.subscribers(tt.eventType); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("subscribers are %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestDispatch writes events and checks every subscriber gets one job per
+// event, and that events are dispatched only once. It needs a migrated
+// database in TEST_DATABASE_URL.
+func TestDispatch(t *testing.T) {
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+
+	db, err := sqlx.Connect("postgres", dsn)
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+	suffix := time.Now().UnixNano()
+	subscribed := fmt.Sprintf("test.subscribed.%d", suffix)
+	ignored := fmt.Sprintf("test.ignored.%d", suffix)
+	jobTypes := []string{fmt.Sprintf("test.first.%d", suffix), fmt.Sprintf("test.second.%d", suffix)}
+
+	var userID uint
+	insertUser := "INSERT INTO users (name, handle, phone, role) VALUES ($1, $2, $3, $4) RETURNING id"
+	if err := db.Get(&userID, insertUser, "Creator", fmt.Sprintf("creator-%d", suffix), fmt.Sprintf("+4%d", suffix%1e12), "creator"); err != nil {
+		t.Fatalf("insert user: %v", err)
+	}
+
+	t.Cleanup(func() {
+		db.Exec("DELETE FROM jobs WHERE type IN ($1, $2)", jobTypes[0], jobTypes[1])
+		db.Exec("DELETE FROM users WHERE id = $1", userID)
+	})
+
+	d := NewDispatcher(db, time.Second)
+	for _, jobType := range jobTypes {
+		d.Subscribe(subscribed, jobType)
+	}
+
+	payloads := []testPayload{{SupportID: 1, Amount: 10000}, {SupportID: 2, Amount: 20000}}
+	for _, p := range payloads {
+		if err := Write(ctx, db, subscribed, userID, p); err != nil {
+			t.Fatalf("write event: %v", err)
+		}
+	}
+	if err := Write(ctx, db, ignored, userID, testPayload{SupportID: 3}); err != nil {
+		t.Fatalf("write event: %v", err)
+	}
+
+	// drain whatever else is waiting in the table too
+	for {
+		n, err := d.dispatch(ctx)
+		if err != nil {
+			t.Fatalf("dispatch: %v", err)
+		}
+		if n < dispatchBatch {
+			break
+		}
+	}
+
+	var pending int
+	if err := db.Get(&pending, "SELECT COUNT(*) FROM outbox_events WHERE type IN ($1, $2) AND dispatched_at IS NULL", subscribed, ignored); err != nil {
+		t.Fatalf("count events: %v", err)
+	}
+	if pending != 0 {
+		t.Fatalf("%d events left undispatched", pending)
+	}
+
+	for _, jobType := range jobTypes {
+		jobs := []queue.Job{}
+		if err := db.Select(&jobs, "SELECT * FROM jobs WHERE type = $1 ORDER BY id", jobType); err != nil {
+			t.Fatalf("select jobs: %v", err)
+		}
+		if len(jobs) != len(payloads) {
+			t.Fatalf("%s has %d jobs, want %d", jobType, len(jobs), len(payloads))
+		}
+
+		for i, job := range jobs {
+			var payload testPayload
+			e, err := Decode(job.Payload, &payload)
+			if err != nil {
+				t.Fatalf("decode job %d: %v", job.ID, err)
+			}
+			if e.Type != subscribed || e.UserID != userID || payload != payloads[i] {
+				t.Fatalf("job %d carries %+v %+v, want %+v", job.ID, e, payload, payloads[i])
+			}
+		}
+	}
+
+	n, err := d.dispatch(ctx)
+	if err != nil {
+		t.Fatalf("dispatch again: %v", err)
+	}
+	if n != 0 {
+		t.Fatalf("dispatched %d events again", n)
+	}
+}
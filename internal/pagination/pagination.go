@@ -0,0 +1,128 @@
+package pagination
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/rxmy43/support-platform/internal/apperror"
+)
+
+const (
+	DefaultLimit = 10
+	MaxLimit     = 50
+)
+
+// ErrInvalidCursor is returned when a cursor token cannot be decoded into the
+// sort key of the list it was sent to.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Params holds the paging query params of a list endpoint. Cursor is the raw
+// opaque token, each repo decodes it into its own sort key.
+type Params struct {
+	Limit  int
+	Cursor string
+}
+
+// Page is one page of a list endpoint. Items never holds more than the
+// requested limit, NextCursor is only set when HasMore is true.
+type Page[T any] struct {
+	Items      []T
+	NextCursor *string
+	HasMore    bool
+}
+
+// FromRequest reads the `limit` and `cursor` query params.
+func FromRequest(r *http.Request) (Params, *apperror.AppError) {
+	query := r.URL.Query()
+	params := Params{
+		Limit:  DefaultLimit,
+		Cursor: query.Get("cursor"),
+	}
+
+	if limitStr := query.Get("limit"); limitStr != "" {
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil {
+			return params, apperror.ValidationError("pagination validation error", []apperror.FieldError{
+				apperror.NewFieldError("limit", apperror.CodeNumberInvalid),
+			})
+		}
+
+		if limit < 1 {
+			return params, apperror.ValidationError("pagination validation error", []apperror.FieldError{
+				apperror.NewFieldError("limit", apperror.CodeNumberTooSmall).WithExpect("1"),
+			})
+		}
+
+		if limit > MaxLimit {
+			return params, apperror.ValidationError("pagination validation error", []apperror.FieldError{
+				apperror.NewFieldError("limit", apperror.CodeNumberTooLarge).WithExpect(strconv.Itoa(MaxLimit)),
+			})
+		}
+
+		params.Limit = limit
+	}
+
+	return params, nil
+}
+
+// FetchLimit is the number of rows a repo should select, one more than the
+// page size so it can tell whether another page exists.
+func (p Params) FetchLimit() int {
+	return p.Limit + 1
+}
+
+// Encode turns a sort key into an opaque cursor token.
+func Encode[C any](key C) string {
+	raw, _ := json.Marshal(key)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// Decode parses a cursor token into its sort key. An empty token yields nil.
+func Decode[C any](token string) (*C, error) {
+	if token == "" {
+		return nil, nil
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var key C
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&key); err != nil {
+		return nil, ErrInvalidCursor
+	}
+	// a token is exactly one key, anything appended to it was tampered with
+	if _, err := decoder.Token(); err != io.EOF {
+		return nil, ErrInvalidCursor
+	}
+
+	return &key, nil
+}
+
+// NewPage trims the extra row fetched by FetchLimit and builds the cursor of
+// the last returned row with key.
+func NewPage[T any, C any](rows []T, limit int, key func(T) C) Page[T] {
+	page := Page[T]{Items: rows}
+
+	if len(rows) > limit {
+		page.Items = rows[:limit]
+		page.HasMore = true
+
+		token := Encode(key(page.Items[len(page.Items)-1]))
+		page.NextCursor = &token
+	}
+
+	if page.Items == nil {
+		page.Items = []T{}
+	}
+
+	return page
+}
@@ -0,0 +1,151 @@
+package pagination
+
+import (
+	"encoding/base64"
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/rxmy43/support-platform/internal/apperror"
+)
+
+type testKey struct {
+	CreatedAt time.Time `json:"created_at"`
+	ID        uint      `json:"id"`
+}
+
+func TestEncodeDecodeRoundTrip(t *testing.T) {
+	keys := []testKey{
+		{},
+		{CreatedAt: time.Date(2026, 3, 1, 10, 30, 0, 123456789, time.UTC), ID: 42},
+		{CreatedAt: time.Date(2026, 3, 1, 10, 30, 0, 0, time.FixedZone("WIB", 7*3600)), ID: 1<<32 + 1},
+	}
+
+	for _, key := range keys {
+		got, err := Decode[testKey](Encode(key))
+		if err != nil {
+			t.Fatalf("decode %+v: %v", key, err)
+		}
+		if got.ID != key.ID || !got.CreatedAt.Equal(key.CreatedAt) {
+			t.Fatalf("decoded %+v, want %+v", *got, key)
+		}
+	}
+}
+
+func TestDecodeEmptyToken(t *testing.T) {
+	got, err := Decode[testKey]("")
+	if err != nil || got != nil {
+		t.Fatalf("got %v, %v, want nil, nil", got, err)
+	}
+}
+
+func TestDecodeTamperedToken(t *testing.T) {
+	raw := func(s string) string { return base64.RawURLEncoding.EncodeToString([]byte(s)) }
+	valid := Encode(testKey{ID: 7})
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{"not base64", "not a cursor!"},
+		{"padded base64", base64.URLEncoding.EncodeToString([]byte(`{"id":7}`)) + "="},
+		{"standard alphabet", base64.StdEncoding.EncodeToString([]byte(`{"id":7,"created_at":"2026-03-01T10:30:00Z"}??`))},
+		{"truncated", valid[:len(valid)-2]},
+		{"not json", raw("id=7")},
+		{"unknown field", raw(`{"id":7,"admin":true}`)},
+		{"wrong type", raw(`{"id":"7"}`)},
+		{"negative id", raw(`{"id":-1}`)},
+		{"bad time", raw(`{"created_at":"yesterday"}`)},
+		{"trailing value", raw(`{"id":7}{"id":8}`)},
+		{"array", raw(`[7]`)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Decode[testKey](tt.token)
+			if !errors.Is(err, ErrInvalidCursor) {
+				t.Fatalf("got %+v, %v, want ErrInvalidCursor", got, err)
+			}
+		})
+	}
+}
+
+func TestNewPage(t *testing.T) {
+	key := func(n int) testKey { return testKey{ID: uint(n)} }
+
+	tests := []struct {
+		name    string
+		rows    []int
+		limit   int
+		items   int
+		hasMore bool
+		last    uint
+	}{
+		{"no rows", nil, 3, 0, false, 0},
+		{"fewer rows than the limit", []int{1, 2}, 3, 2, false, 0},
+		{"exactly the limit", []int{1, 2, 3}, 3, 3, false, 0},
+		{"one extra row", []int{1, 2, 3, 4}, 3, 3, true, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page := NewPage(tt.rows, tt.limit, key)
+			if page.Items == nil {
+				t.Fatal("items is nil, want an empty slice")
+			}
+			if len(page.Items) != tt.items || page.HasMore != tt.hasMore {
+				t.Fatalf("got %d items, has more %v, want %d, %v", len(page.Items), page.HasMore, tt.items, tt.hasMore)
+			}
+			if !tt.hasMore {
+				if page.NextCursor != nil {
+					t.Fatal("next cursor is set without a next page")
+				}
+				return
+			}
+
+			next, err := Decode[testKey](*page.NextCursor)
+			if err != nil {
+				t.Fatalf("decode next cursor: %v", err)
+			}
+			if next.ID != tt.last {
+				t.Fatalf("next cursor points after %d, want %d", next.ID, tt.last)
+			}
+		})
+	}
+}
+
+func TestFromRequest(t *testing.T) {
+	tests := []struct {
+		name   string
+		query  string
+		limit  int
+		cursor string
+		code   apperror.ErrorCode
+	}{
+		{"defaults", "", DefaultLimit, "", ""},
+		{"limit and cursor", "?limit=5&cursor=abc", 5, "abc", ""},
+		{"max limit", "?limit=50", MaxLimit, "", ""},
+		{"not a number", "?limit=ten", 0, "", apperror.CodeNumberInvalid},
+		{"zero", "?limit=0", 0, "", apperror.CodeNumberTooSmall},
+		{"above max", "?limit=51", 0, "", apperror.CodeNumberTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params, appErr := FromRequest(httptest.NewRequest("GET", "/posts"+tt.query, nil))
+			if tt.code != "" {
+				if appErr == nil || len(appErr.FieldErrors) != 1 || appErr.FieldErrors[0].Code != tt.code {
+					t.Fatalf("got %v, want %s", appErr, tt.code)
+				}
+				return
+			}
+			if appErr != nil {
+				t.Fatalf("unexpected error: %v", appErr)
+			}
+			if params.Limit != tt.limit || params.Cursor != tt.cursor {
+				t.Fatalf("got %+v, want limit %d cursor %q", params, tt.limit, tt.cursor)
+			}
+		})
+	}
+}
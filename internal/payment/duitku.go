@@ -0,0 +1,218 @@
+package payment
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rxmy43/support-platform/internal/config"
+	"github.com/shopspring/decimal"
+)
+
+// Duitku is the Duitku POP gateway.
+type Duitku struct {
+	merchantCode string
+	merchantKey  string
+	apiURL       string
+	webAPIURL    string
+	httpClient   *http.Client
+}
+
+func NewDuitku(cfg config.DuitkuAPIConfig, timeout time.Duration) *Duitku {
+	return &Duitku{
+		merchantCode: cfg.MerchantCode,
+		merchantKey:  cfg.MerchantKey,
+		apiURL:       strings.TrimRight(cfg.APIURL, "/"),
+		webAPIURL:    strings.TrimRight(cfg.WebAPIURL, "/"),
+		httpClient:   &http.Client{Timeout: timeout},
+	}
+}
+
+func (d *Duitku) MerchantCode() string {
+	return d.merchantCode
+}
+
+func md5Hex(parts ...string) string {
+	hash := md5.Sum([]byte(strings.Join(parts, "")))
+	return hex.EncodeToString(hash[:])
+}
+
+func jakartaMillis() (int64, error) {
+	loc, err := time.LoadLocation("Asia/Jakarta")
+	if err != nil {
+		return 0, err
+	}
+	return time.Now().In(loc).UnixMilli(), nil
+}
+
+func (d *Duitku) post(ctx context.Context, url string, headers map[string]string, payload any, result any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := d.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	resBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("duitku api error (http %d): %s", resp.StatusCode, resBody)
+	}
+
+	return json.Unmarshal(resBody, result)
+}
+
+func (d *Duitku) CreateInvoice(ctx context.Context, invoice Invoice) (*InvoiceResult, error) {
+	timestamp, err := jakartaMillis()
+	if err != nil {
+		return nil, err
+	}
+
+	hash := sha256.Sum256([]byte(fmt.Sprintf("%s%d%s", d.merchantCode, timestamp, d.merchantKey)))
+
+	headers := map[string]string{
+		"x-duitku-signature":    hex.EncodeToString(hash[:]),
+		"x-duitku-timestamp":    strconv.FormatInt(timestamp, 10),
+		"x-duitku-merchantcode": d.merchantCode,
+	}
+
+	payload := map[string]any{
+		"paymentAmount":   invoice.Amount,
+		"merchantOrderId": invoice.OrderID,
+		"productDetails":  invoice.ProductDetails,
+		"customerVaName":  invoice.CustomerName,
+		"email":           invoice.Email,
+		"callbackUrl":     invoice.CallbackURL,
+		"returnUrl":       invoice.ReturnURL,
+	}
+
+	var result struct {
+		StatusCode    string `json:"statusCode"`
+		StatusMessage string `json:"statusMessage"`
+		Reference     string `json:"reference"`
+		PaymentURL    string `json:"paymentUrl"`
+	}
+
+	if err := d.post(ctx, d.apiURL+"/api/merchant/createInvoice", headers, payload, &result); err != nil {
+		return nil, err
+	}
+
+	if result.StatusCode != "00" {
+		return nil, fmt.Errorf("duitku create invoice failed: %s %s", result.StatusCode, result.StatusMessage)
+	}
+
+	return &InvoiceResult{Reference: result.Reference, PaymentURL: result.PaymentURL}, nil
+}
+
+// VerifyCallback checks the MD5 signature Duitku puts on callbacks:
+// md5(merchantCode + amount + merchantOrderId + merchantKey).
+func (d *Duitku) VerifyCallback(form url.Values) (*Callback, error) {
+	return verifyMD5Callback(form, d.merchantKey)
+}
+
+func verifyMD5Callback(form url.Values, merchantKey string) (*Callback, error) {
+	merchantCode := form.Get("merchantCode")
+	amount := form.Get("amount")
+	orderID := form.Get("merchantOrderId")
+	signature := form.Get("signature")
+
+	if merchantCode == "" || amount == "" || orderID == "" || signature == "" {
+		return nil, ErrMalformed
+	}
+
+	expected := md5Hex(merchantCode, amount, orderID, merchantKey)
+	if subtle.ConstantTimeCompare([]byte(expected), []byte(signature)) != 1 {
+		return nil, ErrInvalidSignature
+	}
+
+	parsed, err := decimal.NewFromString(amount)
+	if err != nil {
+		return nil, ErrMalformed
+	}
+
+	cb := &Callback{
+		MerchantCode: merchantCode,
+		OrderID:      orderID,
+		Reference:    form.Get("reference"),
+		Amount:       parsed,
+		ResultCode:   form.Get("resultCode"),
+		Status:       StatusFailed,
+	}
+	if cb.ResultCode == "00" {
+		cb.Status = StatusPaid
+	}
+
+	return cb, nil
+}
+
+// duitkuStatuses maps the transactionStatus codes to our statuses.
+var duitkuStatuses = map[string]string{
+	"00": StatusPaid,
+	"01": StatusPending,
+	"02": StatusFailed,
+}
+
+func (d *Duitku) CheckStatus(ctx context.Context, orderID string) (*TransactionStatus, error) {
+	payload := map[string]string{
+		"merchantCode":    d.merchantCode,
+		"merchantOrderId": orderID,
+		"signature":       md5Hex(d.merchantCode, orderID, d.merchantKey),
+	}
+
+	var result struct {
+		MerchantOrderID string `json:"merchantOrderId"`
+		Reference       string `json:"reference"`
+		Amount          string `json:"amount"`
+		StatusCode      string `json:"statusCode"`
+		StatusMessage   string `json:"statusMessage"`
+	}
+
+	if err := d.post(ctx, d.webAPIURL+"/api/merchant/transactionStatus", nil, payload, &result); err != nil {
+		return nil, err
+	}
+
+	status, ok := duitkuStatuses[result.StatusCode]
+	if !ok {
+		return nil, fmt.Errorf("unknown duitku status code %q", result.StatusCode)
+	}
+
+	amount, err := decimal.NewFromString(result.Amount)
+	if err != nil {
+		amount = decimal.Zero
+	}
+
+	return &TransactionStatus{
+		OrderID:   result.MerchantOrderID,
+		Reference: result.Reference,
+		Amount:    amount,
+		Status:    status,
+		Message:   result.StatusMessage,
+	}, nil
+}
@@ -0,0 +1,227 @@
+package payment
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"html/template"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// fakeMerchantKey signs the fake gateway callbacks. It is not a secret, the
+// fake gateway must never be enabled in production.
+const fakeMerchantKey = "fake-merchant-key"
+
+// FakePathPrefix is where the fake gateway pages are mounted.
+const FakePathPrefix = "/fake-gateway"
+
+type fakeInvoice struct {
+	Invoice
+	Reference string
+	Status    string
+}
+
+// Fake is an in-memory gateway for local development. Its payment page lets
+// you pay or fail an invoice, which fires a signed callback like Duitku does.
+// Invoices only live in the process that created them.
+type Fake struct {
+	appURL       string
+	merchantCode string
+	httpClient   *http.Client
+
+	mu       sync.Mutex
+	invoices map[string]*fakeInvoice // by reference
+	byOrder  map[string]string       // order id to reference
+}
+
+func NewFake(appURL, merchantCode string) *Fake {
+	if merchantCode == "" {
+		merchantCode = "FAKE"
+	}
+
+	return &Fake{
+		appURL:       strings.TrimRight(appURL, "/"),
+		merchantCode: merchantCode,
+		httpClient:   &http.Client{Timeout: 10 * time.Second},
+		invoices:     map[string]*fakeInvoice{},
+		byOrder:      map[string]string{},
+	}
+}
+
+func (f *Fake) MerchantCode() string {
+	return f.merchantCode
+}
+
+func (f *Fake) CreateInvoice(ctx context.Context, invoice Invoice) (*InvoiceResult, error) {
+	b := make([]byte, 6)
+	if _, err := rand.Read(b); err != nil {
+		return nil, err
+	}
+	reference := "FAKE" + strings.ToUpper(hex.EncodeToString(b))
+
+	f.mu.Lock()
+	f.invoices[reference] = &fakeInvoice{Invoice: invoice, Reference: reference, Status: StatusPending}
+	f.byOrder[invoice.OrderID] = reference
+	f.mu.Unlock()
+
+	return &InvoiceResult{
+		Reference:  reference,
+		PaymentURL: fmt.Sprintf("%s%s/pay/%s", f.appURL, FakePathPrefix, reference),
+	}, nil
+}
+
+func (f *Fake) VerifyCallback(form url.Values) (*Callback, error) {
+	return verifyMD5Callback(form, fakeMerchantKey)
+}
+
+func (f *Fake) CheckStatus(ctx context.Context, orderID string) (*TransactionStatus, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	// invoices of another process, or from before a restart, are reported
+	// pending, like an invoice nobody paid
+	inv, ok := f.invoices[f.byOrder[orderID]]
+	if !ok {
+		return &TransactionStatus{OrderID: orderID, Status: StatusPending}, nil
+	}
+
+	return &TransactionStatus{
+		OrderID:   inv.OrderID,
+		Reference: inv.Reference,
+		Amount:    decimal.NewFromInt(inv.Amount),
+		Status:    inv.Status,
+	}, nil
+}
+
+var fakePage = template.Must(template.New("pay").Parse(`<!doctype html>
+<html>
+<head><meta charset="utf-8"><title>Fake payment</title></head>
+<body style="font-family: sans-serif; max-width: 420px; margin: 48px auto">
+	<h2>Fake payment gateway</h2>
+	<p>{{.ProductDetails}}</p>
+	<p><strong>IDR {{.Amount}}</strong> &middot; {{.Reference}} &middot; {{.Status}}</p>
+	{{if eq .Status "pending"}}
+	<form method="post">
+		<button name="action" value="pay">Pay</button>
+		<button name="action" value="fail">Fail</button>
+	</form>
+	{{end}}
+</body>
+</html>`))
+
+// ServeHTTP serves GET and POST {FakePathPrefix}/pay/{reference}.
+func (f *Fake) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	reference, ok := strings.CutPrefix(r.URL.Path, FakePathPrefix+"/pay/")
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	f.mu.Lock()
+	inv, found := f.invoices[reference]
+	var snapshot fakeInvoice
+	if found {
+		snapshot = *inv
+	}
+	f.mu.Unlock()
+
+	if !found {
+		http.NotFound(w, r)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		fakePage.Execute(w, snapshot)
+	case http.MethodPost:
+		f.complete(w, r, reference)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (f *Fake) complete(w http.ResponseWriter, r *http.Request, reference string) {
+	paid := r.FormValue("action") == "pay"
+	status := StatusFailed
+	if paid {
+		status = StatusPaid
+	}
+
+	f.mu.Lock()
+	inv := f.invoices[reference]
+	if inv.Status != StatusPending {
+		f.mu.Unlock()
+		http.Redirect(w, r, r.URL.Path, http.StatusSeeOther)
+		return
+	}
+	inv.Status = status
+	snapshot := *inv
+	f.mu.Unlock()
+
+	if err := f.sendCallback(r.Context(), snapshot, paid); err != nil {
+		log.Println("fake gateway callback failed:", err)
+		http.Error(w, "callback failed: "+err.Error(), http.StatusBadGateway)
+		return
+	}
+
+	target := r.URL.Path
+	if snapshot.ReturnURL != "" {
+		target = snapshot.ReturnURL
+	}
+	http.Redirect(w, r, target, http.StatusSeeOther)
+}
+
+// CallbackForm builds the callback the fake gateway sends for an order,
+// signed like Duitku's.
+func (f *Fake) CallbackForm(orderID, reference string, amount int64, paid bool) url.Values {
+	resultCode := "01"
+	if paid {
+		resultCode = "00"
+	}
+
+	amountStr := strconv.FormatInt(amount, 10)
+
+	form := url.Values{}
+	form.Set("merchantCode", f.merchantCode)
+	form.Set("amount", amountStr)
+	form.Set("merchantOrderId", orderID)
+	form.Set("resultCode", resultCode)
+	form.Set("reference", reference)
+	form.Set("signature", md5Hex(f.merchantCode, amountStr, orderID, fakeMerchantKey))
+
+	return form
+}
+
+// sendCallback posts the signed callback to the invoice callback URL.
+func (f *Fake) sendCallback(ctx context.Context, inv fakeInvoice, paid bool) error {
+	form := f.CallbackForm(inv.OrderID, inv.Reference, inv.Amount, paid)
+	form.Set("productDetail", inv.ProductDetails)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, inv.CallbackURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := f.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("callback answered http %d", resp.StatusCode)
+	}
+
+	return nil
+}
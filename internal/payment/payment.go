@@ -0,0 +1,97 @@
+package payment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/rxmy43/support-platform/internal/config"
+	"github.com/shopspring/decimal"
+)
+
+// Invoice is what we ask a gateway to collect.
+type Invoice struct {
+	OrderID        string
+	Amount         int64
+	ProductDetails string
+	CustomerName   string
+	Email          string
+	CallbackURL    string
+	ReturnURL      string
+}
+
+type InvoiceResult struct {
+	Reference  string
+	PaymentURL string
+}
+
+// Payment statuses as reported by a gateway.
+const (
+	StatusPaid    = "paid"
+	StatusPending = "pending"
+	StatusFailed  = "failed"
+	StatusExpired = "expired"
+)
+
+// Callback is a verified payment notification.
+type Callback struct {
+	MerchantCode string
+	OrderID      string
+	Reference    string
+	Amount       decimal.Decimal
+	ResultCode   string
+	// Status is StatusPaid or StatusFailed.
+	Status string
+}
+
+type TransactionStatus struct {
+	OrderID   string
+	Reference string
+	Amount    decimal.Decimal
+	Status    string
+	Message   string
+}
+
+var (
+	ErrInvalidSignature = errors.New("invalid callback signature")
+	ErrMalformed        = errors.New("malformed callback")
+)
+
+// PaymentGateway collects support payments.
+type PaymentGateway interface {
+	CreateInvoice(ctx context.Context, invoice Invoice) (*InvoiceResult, error)
+	// VerifyCallback parses the callback form and checks its signature, it
+	// returns ErrInvalidSignature or ErrMalformed for callbacks to reject.
+	VerifyCallback(form url.Values) (*Callback, error)
+	CheckStatus(ctx context.Context, orderID string) (*TransactionStatus, error)
+	// MerchantCode is the merchant callbacks must be addressed to.
+	MerchantCode() string
+}
+
+const (
+	GatewayDuitku = "duitku"
+	GatewayFake   = "fake"
+)
+
+// FakeAllowed reports whether the fake gateway may run in env. Anyone can
+// mark its payments paid, so it is refused outside development and test.
+func FakeAllowed(env string) bool {
+	return env == "development" || env == "test"
+}
+
+// NewFromConfig returns the gateway selected by PAYMENT_GATEWAY.
+func NewFromConfig(cfg *config.Config) (PaymentGateway, error) {
+	switch cfg.Payment.Gateway {
+	case GatewayDuitku, "":
+		return NewDuitku(cfg.Duitku, 15*time.Second), nil
+	case GatewayFake:
+		if !FakeAllowed(cfg.Env) {
+			return nil, fmt.Errorf("the fake payment gateway is not allowed in env %q", cfg.Env)
+		}
+		return NewFake(cfg.AppURL, cfg.Duitku.MerchantCode), nil
+	}
+
+	return nil, fmt.Errorf("unknown payment gateway %q", cfg.Payment.Gateway)
+}
@@ -0,0 +1,224 @@
+package payment
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"testing"
+
+	"github.com/rxmy43/support-platform/internal/config"
+)
+
+// signedForm is a Duitku callback for order SUPPORT/1 signed with key.
+func signedForm(merchantCode, amount, resultCode, key string) url.Values {
+	form := url.Values{}
+	form.Set("merchantCode", merchantCode)
+	form.Set("amount", amount)
+	form.Set("merchantOrderId", "SUPPORT/1")
+	form.Set("resultCode", resultCode)
+	form.Set("reference", "REF1")
+	form.Set("signature", md5Hex(merchantCode, amount, "SUPPORT/1", key))
+	return form
+}
+
+func TestDuitkuVerifyCallback(t *testing.T) {
+	const key = "merchant-key"
+	gateway := NewDuitku(config.DuitkuAPIConfig{MerchantCode: "D1", MerchantKey: key}, 0)
+
+	tests := []struct {
+		name   string
+		form   func() url.Values
+		status string
+		err    error
+	}{
+		{
+			name:   "paid",
+			form:   func() url.Values { return signedForm("D1", "50000", "00", key) },
+			status: StatusPaid,
+		},
+		{
+			name:   "failed",
+			form:   func() url.Values { return signedForm("D1", "50000", "01", key) },
+			status: StatusFailed,
+		},
+		{
+			name: "signed with another key",
+			form: func() url.Values { return signedForm("D1", "50000", "00", "other-key") },
+			err:  ErrInvalidSignature,
+		},
+		{
+			name: "amount changed after signing",
+			form: func() url.Values {
+				form := signedForm("D1", "50000", "00", key)
+				form.Set("amount", "500000")
+				return form
+			},
+			err: ErrInvalidSignature,
+		},
+		{
+			name: "order changed after signing",
+			form: func() url.Values {
+				form := signedForm("D1", "50000", "00", key)
+				form.Set("merchantOrderId", "SUPPORT/2")
+				return form
+			},
+			err: ErrInvalidSignature,
+		},
+		{
+			name: "forged signature",
+			form: func() url.Values {
+				form := signedForm("D1", "50000", "00", key)
+				form.Set("signature", md5Hex("X"))
+				return form
+			},
+			err: ErrInvalidSignature,
+		},
+		{
+			name: "missing signature",
+			form: func() url.Values {
+				form := signedForm("D1", "50000", "00", key)
+				form.Del("signature")
+				return form
+			},
+			err: ErrMalformed,
+		},
+		{
+			name: "missing amount",
+			form: func() url.Values {
+				form := signedForm("D1", "50000", "00", key)
+				form.Del("amount")
+				return form
+			},
+			err: ErrMalformed,
+		},
+		{
+			name: "signed amount that is not a number",
+			form: func() url.Values { return signedForm("D1", "lots", "00", key) },
+			err:  ErrMalformed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cb, err := gateway.VerifyCallback(tt.form())
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("error is %v, want %v", err, tt.err)
+			}
+			if tt.err != nil {
+				return
+			}
+			if cb.Status != tt.status {
+				t.Fatalf("status is %q, want %q", cb.Status, tt.status)
+			}
+			if cb.OrderID != "SUPPORT/1" || cb.Reference != "REF1" || cb.Amount.IntPart() != 50000 {
+				t.Fatalf("unexpected callback %+v", cb)
+			}
+		})
+	}
+}
+
+func TestFakeVerifyCallback(t *testing.T) {
+	gateway := NewFake("http://localhost:8080", "")
+
+	tests := []struct {
+		name   string
+		form   func() url.Values
+		status string
+		err    error
+	}{
+		{
+			name:   "paid",
+			form:   func() url.Values { return gateway.CallbackForm("SUPPORT/1", "REF1", 50000, true) },
+			status: StatusPaid,
+		},
+		{
+			name:   "failed",
+			form:   func() url.Values { return gateway.CallbackForm("SUPPORT/1", "REF1", 50000, false) },
+			status: StatusFailed,
+		},
+		{
+			name: "signed with a real merchant key",
+			form: func() url.Values { return signedForm("FAKE", "50000", "00", "merchant-key") },
+			err:  ErrInvalidSignature,
+		},
+		{
+			name: "amount changed after signing",
+			form: func() url.Values {
+				form := gateway.CallbackForm("SUPPORT/1", "REF1", 50000, true)
+				form.Set("amount", "1")
+				return form
+			},
+			err: ErrInvalidSignature,
+		},
+		{
+			name: "missing order",
+			form: func() url.Values {
+				form := gateway.CallbackForm("SUPPORT/1", "REF1", 50000, true)
+				form.Del("merchantOrderId")
+				return form
+			},
+			err: ErrMalformed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cb, err := gateway.VerifyCallback(tt.form())
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("error is %v, want %v", err, tt.err)
+			}
+			if tt.err == nil && cb.Status != tt.status {
+				t.Fatalf("status is %q, want %q", cb.Status, tt.status)
+			}
+		})
+	}
+}
+
+func TestFakeAllowed(t *testing.T) {
+	tests := []struct {
+		env  string
+		want bool
+	}{
+		{"development", true},
+		{"test", true},
+		{"staging", false},
+		{"production", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		if got := FakeAllowed(tt.env); got != tt.want {
+			t.Errorf("FakeAllowed(%q) is %v, want %v", tt.env, got, tt.want)
+		}
+	}
+}
+
+func TestFakeCheckStatus(t *testing.T) {
+	ctx := context.Background()
+	gateway := NewFake("http://localhost:8080", "")
+
+	if _, err := gateway.CreateInvoice(ctx, Invoice{OrderID: "SUPPORT/1", Amount: 50000}); err != nil {
+		t.Fatalf("create invoice: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		orderID string
+		status  string
+	}{
+		{"invoiced order", "SUPPORT/1", StatusPending},
+		{"order of another process", "SUPPORT/2", StatusPending},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			st, err := gateway.CheckStatus(ctx, tt.orderID)
+			if err != nil {
+				t.Fatalf("check status: %v", err)
+			}
+			if st.OrderID != tt.orderID || st.Status != tt.status {
+				t.Fatalf("got %+v, want order %s %s", st, tt.orderID, tt.status)
+			}
+		})
+	}
+}
@@ -0,0 +1,80 @@
+// Package queue is a durable job queue stored in the jobs table. Jobs are
+// claimed with FOR UPDATE SKIP LOCKED so any number of runners can share it,
+// failed jobs are retried with exponential backoff and end up dead once they
+// run out of attempts.
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Job statuses.
+const (
+	StatusQueued  = "queued"
+	StatusRunning = "running"
+	StatusDone    = "done"
+	StatusDead    = "dead"
+)
+
+// DefaultMaxAttempts is how many times a job runs before it is dead, with
+// the backoff below that is about ten minutes of retries.
+const DefaultMaxAttempts = 8
+
+type Job struct {
+	ID          uint            `db:"id"`
+	Type        string          `db:"type"`
+	Payload     json.RawMessage `db:"payload"`
+	Status      string          `db:"status"`
+	Attempts    int             `db:"attempts"`
+	MaxAttempts int             `db:"max_attempts"`
+	RunAt       time.Time       `db:"run_at"`
+	LockedAt    *time.Time      `db:"locked_at"`
+	LastError   *string         `db:"last_error"`
+	CreatedAt   time.Time       `db:"created_at"`
+	UpdatedAt   time.Time       `db:"updated_at"`
+}
+
+// Enqueue adds a job for its handler to run as soon as possible. Pass the
+// *sqlx.Tx of the surrounding work to have the job committed, or rolled
+// back, together with it.
+func Enqueue(ctx context.Context, db sqlx.ExtContext, jobType string, payload any) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.ExecContext(ctx,
+		"INSERT INTO jobs (type, payload, max_attempts) VALUES ($1, $2, $3)",
+		jobType, data, DefaultMaxAttempts,
+	)
+	return err
+}
+
+// PruneDone deletes the finished jobs last updated before the given time and
+// returns how many were deleted. Dead jobs are kept for inspection.
+func PruneDone(ctx context.Context, db *sqlx.DB, before time.Time) (int64, error) {
+	res, err := db.ExecContext(ctx, "DELETE FROM jobs WHERE status = 'done' AND updated_at < $1", before)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
+
+const (
+	backoffBase = 5 * time.Second
+	backoffMax  = time.Hour
+)
+
+// backoff is the wait before retrying a job that failed its attempt-th run.
+func backoff(attempt int) time.Duration {
+	d := backoffBase
+	for i := 1; i < attempt && d < backoffMax; i++ {
+		d *= 2
+	}
+	return min(d, backoffMax)
+}
@@ -0,0 +1,154 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	_ "github.com/lib/pq"
+)
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{0, 5 * time.Second},
+		{1, 5 * time.Second},
+		{2, 10 * time.Second},
+		{3, 20 * time.Second},
+		{7, 320 * time.Second},
+		{10, 2560 * time.Second},
+		{11, time.Hour},
+		{1000, time.Hour},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("attempt %d", tt.attempt), func(t *testing.T) {
+			if got := backoff(tt.attempt); got != tt.want {
+				t.Fatalf("backoff is %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOutcome(t *testing.T) {
+	failed := errors.New("boom")
+
+	tests := []struct {
+		name     string
+		attempts int
+		err      error
+		want     string
+	}{
+		{"succeeded", 1, nil, StatusDone},
+		{"succeeded on the last attempt", DefaultMaxAttempts, nil, StatusDone},
+		{"failed with attempts left", 1, failed, StatusQueued},
+		{"failed one before the last attempt", DefaultMaxAttempts - 1, failed, StatusQueued},
+		{"failed the last attempt", DefaultMaxAttempts, failed, StatusDead},
+		{"failed past the last attempt", DefaultMaxAttempts + 1, failed, StatusDead},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			job := &Job{Attempts: tt.attempts, MaxAttempts: DefaultMaxAttempts}
+			if got := outcome(job, tt.err); got != tt.want {
+				t.Fatalf("outcome is %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunRecoversPanics(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register("test.ok", func(ctx context.Context, payload json.RawMessage) error { return nil })
+	registry.Register("test.panic", func(ctx context.Context, payload json.RawMessage) error { panic("boom") })
+	runner := NewRunner(nil, registry, 1, time.Second)
+
+	tests := []struct {
+		jobType string
+		wantErr bool
+	}{
+		{"test.ok", false},
+		{"test.panic", true},
+		{"test.unknown", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.jobType, func(t *testing.T) {
+			err := runner.run(context.Background(), &Job{Type: tt.jobType})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error is %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestProcessDeadLetters runs a failing job until it is dead. It needs a
+// migrated database in TEST_DATABASE_URL.
+func TestProcessDeadLetters(t *testing.T) {
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+
+	db, err := sqlx.Connect("postgres", dsn)
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+	jobType := fmt.Sprintf("test.failing.%d", time.Now().UnixNano())
+	t.Cleanup(func() { db.Exec("DELETE FROM jobs WHERE type = $1", jobType) })
+
+	registry := NewRegistry()
+	registry.Register(jobType, func(ctx context.Context, payload json.RawMessage) error {
+		return errors.New("always failing")
+	})
+	runner := NewRunner(db, registry, 1, time.Second)
+
+	var id uint
+	if err := db.Get(&id, "INSERT INTO jobs (type, max_attempts) VALUES ($1, 2) RETURNING id", jobType); err != nil {
+		t.Fatalf("insert job: %v", err)
+	}
+
+	wantStatuses := []string{StatusQueued, StatusDead}
+	for attempt, want := range wantStatuses {
+		job, err := runner.claim(ctx)
+		if err != nil || job == nil {
+			t.Fatalf("attempt %d: claimed %v, %v", attempt+1, job, err)
+		}
+		runner.process(ctx, job)
+
+		var got Job
+		if err := db.Get(&got, "SELECT * FROM jobs WHERE id = $1", id); err != nil {
+			t.Fatalf("get job: %v", err)
+		}
+		if got.Status != want {
+			t.Fatalf("attempt %d: status is %q, want %q", attempt+1, got.Status, want)
+		}
+		if got.LastError == nil || *got.LastError != "always failing" {
+			t.Fatalf("attempt %d: last error is %v", attempt+1, got.LastError)
+		}
+
+		if want == StatusQueued {
+			if !got.RunAt.After(time.Now()) {
+				t.Fatalf("retry is not backed off, runs at %v", got.RunAt)
+			}
+			// skip the backoff so the next claim picks it up
+			if _, err := db.Exec("UPDATE jobs SET run_at = NOW() WHERE id = $1", id); err != nil {
+				t.Fatalf("reset run_at: %v", err)
+			}
+		}
+	}
+
+	if job, err := runner.claim(ctx); err != nil || job != nil {
+		t.Fatalf("claimed %v, %v after the job died", job, err)
+	}
+}
@@ -0,0 +1,206 @@
+package queue
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// Handler runs one job. Returning an error retries it later, so handlers
+// must be safe to run more than once.
+type Handler func(ctx context.Context, payload json.RawMessage) error
+
+// Registry maps job types to their handlers.
+type Registry struct {
+	mu       sync.RWMutex
+	handlers map[string]Handler
+}
+
+func NewRegistry() *Registry {
+	return &Registry{handlers: map[string]Handler{}}
+}
+
+func (r *Registry) Register(jobType string, handler Handler) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.handlers[jobType] = handler
+}
+
+func (r *Registry) handler(jobType string) (Handler, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	h, ok := r.handlers[jobType]
+	return h, ok
+}
+
+func (r *Registry) types() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	types := make([]string, 0, len(r.handlers))
+	for t := range r.handlers {
+		types = append(types, t)
+	}
+	return types
+}
+
+// lease is how long a job may stay running before it is considered
+// abandoned, by a crashed process, and claimed again.
+const lease = 10 * time.Minute
+
+// handlerTimeout bounds a single run, well within the lease.
+const handlerTimeout = 5 * time.Minute
+
+// Runner claims and runs jobs. It only claims the job types registered on
+// its registry, so processes with different handlers can share the table.
+type Runner struct {
+	db           *sqlx.DB
+	registry     *Registry
+	concurrency  int
+	pollInterval time.Duration
+}
+
+func NewRunner(db *sqlx.DB, registry *Registry, concurrency int, pollInterval time.Duration) *Runner {
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+
+	return &Runner{
+		db:           db,
+		registry:     registry,
+		concurrency:  concurrency,
+		pollInterval: pollInterval,
+	}
+}
+
+// Run works through the queue until ctx is cancelled, then lets the running
+// jobs finish.
+func (r *Runner) Run(ctx context.Context) {
+	if len(r.registry.types()) == 0 {
+		return
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < r.concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r.loop(ctx)
+		}()
+	}
+
+	wg.Wait()
+}
+
+func (r *Runner) loop(ctx context.Context) {
+	for ctx.Err() == nil {
+		job, err := r.claim(ctx)
+		if err != nil && ctx.Err() == nil {
+			log.Println("failed claiming a job:", err)
+		}
+
+		if job == nil {
+			select {
+			case <-ctx.Done():
+			case <-time.After(r.pollInterval):
+			}
+			continue
+		}
+
+		r.process(context.WithoutCancel(ctx), job)
+	}
+}
+
+// claim takes the next runnable job, or a running one whose lease expired.
+// It returns nil when there is nothing to do.
+func (r *Runner) claim(ctx context.Context) (*Job, error) {
+	query := `
+		UPDATE jobs
+		SET status = 'running', attempts = attempts + 1, locked_at = NOW(), updated_at = NOW()
+		WHERE id = (
+			SELECT id FROM jobs
+			WHERE type = ANY($1)
+			AND (
+				(status = 'queued' AND run_at <= NOW())
+				OR (status = 'running' AND locked_at < NOW() - make_interval(secs => $2))
+			)
+			ORDER BY run_at, id
+			FOR UPDATE SKIP LOCKED
+			LIMIT 1
+		)
+		RETURNING *
+	`
+
+	var job Job
+	err := r.db.GetContext(ctx, &job, query, pq.Array(r.registry.types()), lease.Seconds())
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &job, nil
+}
+
+func (r *Runner) process(ctx context.Context, job *Job) {
+	runCtx, cancel := context.WithTimeout(ctx, handlerTimeout)
+	runErr := r.run(runCtx, job)
+	cancel()
+
+	var err error
+	switch outcome(job, runErr) {
+	case StatusDone:
+		_, err = r.db.ExecContext(ctx, "UPDATE jobs SET status = 'done', locked_at = NULL, updated_at = NOW() WHERE id = $1", job.ID)
+	case StatusDead:
+		log.Printf("job %d %s is dead after %d attempts: %v", job.ID, job.Type, job.Attempts, runErr)
+		_, err = r.db.ExecContext(ctx,
+			"UPDATE jobs SET status = 'dead', locked_at = NULL, last_error = $2, updated_at = NOW() WHERE id = $1",
+			job.ID, runErr.Error(),
+		)
+	default:
+		log.Printf("job %d %s failed attempt %d: %v", job.ID, job.Type, job.Attempts, runErr)
+		_, err = r.db.ExecContext(ctx,
+			"UPDATE jobs SET status = 'queued', locked_at = NULL, last_error = $2, run_at = $3, updated_at = NOW() WHERE id = $1",
+			job.ID, runErr.Error(), time.Now().Add(backoff(job.Attempts)),
+		)
+	}
+
+	if err != nil {
+		log.Printf("failed updating job %d: %v", job.ID, err)
+	}
+}
+
+// outcome is the status a job moves to after a run that returned runErr: a
+// failed job is queued again until it has used all its attempts.
+func outcome(job *Job, runErr error) string {
+	switch {
+	case runErr == nil:
+		return StatusDone
+	case job.Attempts >= job.MaxAttempts:
+		return StatusDead
+	}
+	return StatusQueued
+}
+
+// run calls the job handler, turning a panic into an error.
+func (r *Runner) run(ctx context.Context, job *Job) (err error) {
+	handler, ok := r.registry.handler(job.Type)
+	if !ok {
+		return fmt.Errorf("no handler for job type %q", job.Type)
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("job handler panicked: %v", p)
+		}
+	}()
+
+	return handler(ctx, job.Payload)
+}
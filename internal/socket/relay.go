@@ -0,0 +1,105 @@
+package socket
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// relayChannel is the Postgres channel socket messages are relayed on.
+const relayChannel = "socket_messages"
+
+// relayMessage is one message for the connections of a creator or of a
+// campaign, on whichever process holds them.
+type relayMessage struct {
+	CreatorID  uint         `json:"creator_id,omitempty"`
+	CampaignID uint         `json:"campaign_id,omitempty"`
+	Message    EventMessage `json:"message"`
+}
+
+// UseRelay makes SendToCreator and SendToCampaign go through Postgres
+// NOTIFY, so the message reaches the connections held by every API process
+// running Listen, not only this one.
+func (h *Hub) UseRelay(db *sqlx.DB) {
+	h.db = db
+}
+
+// SendToCreator delivers a message to the connections of a creator on every
+// process. Without a relay it only reaches the connections of this process.
+func (h *Hub) SendToCreator(ctx context.Context, creatorID uint, message EventMessage) error {
+	if h.db == nil {
+		h.BroadcastToCreator(creatorID, message)
+		return nil
+	}
+	return h.notify(ctx, relayMessage{CreatorID: creatorID, Message: message})
+}
+
+// SendToCampaign delivers a message to the viewers of a campaign on every
+// process. Without a relay it only reaches the connections of this process.
+func (h *Hub) SendToCampaign(ctx context.Context, campaignID uint, message EventMessage) error {
+	if h.db == nil {
+		h.BroadcastToCampaign(campaignID, message)
+		return nil
+	}
+	return h.notify(ctx, relayMessage{CampaignID: campaignID, Message: message})
+}
+
+func (h *Hub) notify(ctx context.Context, msg relayMessage) error {
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	_, err = h.db.ExecContext(ctx, "SELECT pg_notify($1, $2)", relayChannel, string(payload))
+	return err
+}
+
+// Listen delivers the relayed messages to the connections of this process
+// until ctx is cancelled. Messages sent while the listener is reconnecting
+// are missed, like messages sent to a client that is not connected.
+func (h *Hub) Listen(ctx context.Context, dsn string) {
+	listener := pq.NewListener(dsn, time.Second, time.Minute, func(event pq.ListenerEventType, err error) {
+		if err != nil {
+			log.Println("socket relay listener:", err)
+		}
+	})
+	defer listener.Close()
+
+	if err := listener.Listen(relayChannel); err != nil {
+		log.Println("failed listening for socket messages:", err)
+		return
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case n := <-listener.Notify:
+			// nil after a reconnect
+			if n != nil {
+				h.deliver(n.Extra)
+			}
+		case <-time.After(90 * time.Second):
+			go listener.Ping()
+		}
+	}
+}
+
+func (h *Hub) deliver(payload string) {
+	var msg relayMessage
+	if err := json.Unmarshal([]byte(payload), &msg); err != nil {
+		log.Println("failed decoding relayed socket message:", err)
+		return
+	}
+
+	switch {
+	case msg.CreatorID != 0:
+		h.BroadcastToCreator(msg.CreatorID, msg.Message)
+	case msg.CampaignID != 0:
+		h.BroadcastToCampaign(msg.CampaignID, msg.Message)
+	}
+}
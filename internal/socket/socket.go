@@ -9,17 +9,22 @@ import (
 	"time"
 
 	"github.com/gorilla/websocket"
+	"github.com/jmoiron/sqlx"
 	"github.com/rxmy43/support-platform/internal/apperror"
 	"github.com/rxmy43/support-platform/internal/http/response"
 )
 
 type Hub struct {
-	mu           sync.RWMutex
-	clients      map[uint]map[*websocket.Conn]bool
+	mu      sync.RWMutex
+	clients map[uint]map[*websocket.Conn]bool
+	// campaigns holds the connections of viewers following a campaign
+	campaigns    map[uint]map[*websocket.Conn]bool
 	upgrader     websocket.Upgrader
 	pingInterval time.Duration
 	pongWait     time.Duration
 	writeWait    time.Duration
+	// db relays the Send messages to every process, see UseRelay
+	db *sqlx.DB
 }
 
 type EventMessage struct {
@@ -29,7 +34,8 @@ type EventMessage struct {
 
 func NewHub() *Hub {
 	return &Hub{
-		clients: make(map[uint]map[*websocket.Conn]bool),
+		clients:   make(map[uint]map[*websocket.Conn]bool),
+		campaigns: make(map[uint]map[*websocket.Conn]bool),
 		upgrader: websocket.Upgrader{
 			CheckOrigin: func(r *http.Request) bool { return true },
 		},
@@ -62,6 +68,62 @@ func (h *Hub) Unregister(creatorID uint, conn *websocket.Conn) {
 	conn.Close()
 }
 
+// RegisterCampaign adds a connection following the progress of a campaign.
+func (h *Hub) RegisterCampaign(campaignID uint, conn *websocket.Conn) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if _, ok := h.campaigns[campaignID]; !ok {
+		h.campaigns[campaignID] = make(map[*websocket.Conn]bool)
+	}
+	h.campaigns[campaignID][conn] = true
+}
+
+func (h *Hub) UnregisterCampaign(campaignID uint, conn *websocket.Conn) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if conns, ok := h.campaigns[campaignID]; ok {
+		delete(conns, conn)
+		if len(conns) == 0 {
+			delete(h.campaigns, campaignID)
+		}
+	}
+	conn.Close()
+}
+
+// BroadcastToCampaign sends a message to the viewers following a campaign.
+func (h *Hub) BroadcastToCampaign(campaignID uint, message EventMessage) {
+	h.mu.RLock()
+	conns := make([]*websocket.Conn, 0, len(h.campaigns[campaignID]))
+	for conn := range h.campaigns[campaignID] {
+		conns = append(conns, conn)
+	}
+	h.mu.RUnlock()
+
+	if len(conns) == 0 {
+		return
+	}
+
+	messageBytes, err := json.Marshal(message)
+	if err != nil {
+		log.Printf("Error marshaling broadcast message for campaign_id %d: %v", campaignID, err)
+		return
+	}
+
+	var wg sync.WaitGroup
+	for _, conn := range conns {
+		wg.Add(1)
+		go func(c *websocket.Conn) {
+			defer wg.Done()
+
+			c.SetWriteDeadline(time.Now().Add(h.writeWait))
+			if err := c.WriteMessage(websocket.TextMessage, messageBytes); err != nil {
+				h.UnregisterCampaign(campaignID, c)
+			}
+		}(conn)
+	}
+	wg.Wait()
+}
+
 func (h *Hub) BroadcastToCreator(creatorID uint, message EventMessage) {
 	h.mu.RLock()
 	conns := h.clients[creatorID]
@@ -103,6 +165,11 @@ func (h *Hub) BroadcastToCreator(creatorID uint, message EventMessage) {
 }
 
 func (h *Hub) WsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Query().Has("campaign_id") {
+		h.campaignWsHandler(w, r)
+		return
+	}
+
 	creatorIDParam := r.URL.Query().Get("creator_id")
 	if creatorIDParam == "" {
 		response.ToJSON(w, r, apperror.BadRequest("creator id is required", apperror.CodeFieldRequired))
@@ -173,3 +240,56 @@ func (h *Hub) WsHandler(w http.ResponseWriter, r *http.Request) {
 		conn.SetReadDeadline(time.Now().Add(h.pongWait))
 	}
 }
+
+// campaignWsHandler serves /ws?campaign_id=, the connection only receives
+// the progress of the campaign.
+func (h *Hub) campaignWsHandler(w http.ResponseWriter, r *http.Request) {
+	parsed, err := strconv.ParseUint(r.URL.Query().Get("campaign_id"), 10, 64)
+	if err != nil {
+		response.ToJSON(w, r, apperror.BadRequest("campaign id must be a number", apperror.CodeFieldInvalidFormat))
+		return
+	}
+	campaignID := uint(parsed)
+
+	conn, err := h.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		log.Printf("WebSocket upgrade failed for campaign_id %d: %v", campaignID, err)
+		return
+	}
+
+	conn.SetReadLimit(512)
+	conn.SetReadDeadline(time.Now().Add(h.pongWait))
+	conn.SetPongHandler(func(string) error {
+		conn.SetReadDeadline(time.Now().Add(h.pongWait))
+		return nil
+	})
+
+	h.RegisterCampaign(campaignID, conn)
+	defer h.UnregisterCampaign(campaignID, conn)
+
+	done := make(chan struct{})
+	defer close(done)
+
+	go func() {
+		ticker := time.NewTicker(h.pingInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				conn.SetWriteDeadline(time.Now().Add(h.writeWait))
+				if err := conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+					return
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	for {
+		if _, _, err := conn.ReadMessage(); err != nil {
+			break
+		}
+		conn.SetReadDeadline(time.Now().Add(h.pongWait))
+	}
+}
@@ -0,0 +1,123 @@
+package worker
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/rxmy43/support-platform/internal/modules/campaign"
+	"github.com/rxmy43/support-platform/internal/modules/post"
+	"github.com/rxmy43/support-platform/internal/modules/support"
+	"github.com/rxmy43/support-platform/internal/outbox"
+	"github.com/rxmy43/support-platform/internal/queue"
+)
+
+// reconcileBatch caps how many pending supports one reconcile or expire run
+// checks with the gateway.
+const reconcileBatch = 100
+
+// ExpirePendingSupports expires supports still pending after ttl, once the
+// gateway confirms they were not paid.
+func ExpirePendingSupports(supportService *support.SupportService, interval, ttl time.Duration) Job {
+	return Job{
+		Name:     "expire_pending_supports",
+		Interval: interval,
+		Run: func(ctx context.Context) error {
+			expired, err := supportService.ExpireStale(ctx, ttl, reconcileBatch)
+			if expired > 0 {
+				log.Printf("expired %d pending supports", expired)
+			}
+			return err
+		},
+	}
+}
+
+// ReconcilePayments settles supports pending for longer than delay whose
+// callback we never received, using the gateway status API. It should run
+// well within the pending ttl so paid supports are caught before expiring.
+func ReconcilePayments(supportService *support.SupportService, interval, delay time.Duration) Job {
+	return Job{
+		Name:     "reconcile_payments",
+		Interval: interval,
+		Run: func(ctx context.Context) error {
+			settled, err := supportService.Reconcile(ctx, delay, reconcileBatch)
+			if settled > 0 {
+				log.Printf("reconciled %d pending supports", settled)
+			}
+			return err
+		},
+	}
+}
+
+// PublishScheduledPosts publishes the scheduled posts that are due.
+func PublishScheduledPosts(postRepo *post.PostRepo, interval time.Duration) Job {
+	return Job{
+		Name:     "publish_scheduled_posts",
+		Interval: interval,
+		Run: func(ctx context.Context) error {
+			published, err := postRepo.PublishScheduled(ctx)
+			if published > 0 {
+				log.Printf("published %d scheduled posts", published)
+			}
+			return err
+		},
+	}
+}
+
+// ExpireCampaigns expires the active campaigns past their deadline.
+func ExpireCampaigns(campaignService *campaign.CampaignService, interval time.Duration) Job {
+	return Job{
+		Name:     "expire_campaigns",
+		Interval: interval,
+		Run: func(ctx context.Context) error {
+			expired, err := campaignService.ExpireOverdue(ctx)
+			if expired > 0 {
+				log.Printf("expired %d campaigns", expired)
+			}
+			return err
+		},
+	}
+}
+
+// PruneViewWindows deletes the post view windows older than the longest
+// analytics range, the unique viewers of a range are counted from them.
+func PruneViewWindows(postRepo *post.PostRepo, interval time.Duration) Job {
+	return Job{
+		Name:     "prune_view_windows",
+		Interval: interval,
+		Run: func(ctx context.Context) error {
+			pruned, err := postRepo.PruneViewWindows(ctx, time.Now().AddDate(0, 0, -(post.MaxAnalyticsDays+1)))
+			if pruned > 0 {
+				log.Printf("pruned %d post view windows", pruned)
+			}
+			return err
+		},
+	}
+}
+
+// PruneFinishedJobs deletes the queue jobs and outbox events that were
+// finished, or dispatched, more than keep ago.
+func PruneFinishedJobs(db *sqlx.DB, interval, keep time.Duration) Job {
+	return Job{
+		Name:     "prune_finished_jobs",
+		Interval: interval,
+		Run: func(ctx context.Context) error {
+			before := time.Now().Add(-keep)
+
+			pruned, err := queue.PruneDone(ctx, db, before)
+			if err != nil {
+				return err
+			}
+			if pruned > 0 {
+				log.Printf("pruned %d finished jobs", pruned)
+			}
+
+			pruned, err = outbox.PruneDispatched(ctx, db, before)
+			if pruned > 0 {
+				log.Printf("pruned %d dispatched outbox events", pruned)
+			}
+			return err
+		},
+	}
+}
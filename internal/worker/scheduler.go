@@ -0,0 +1,132 @@
+package worker
+
+import (
+	"context"
+	"hash/fnv"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Job is a task the Scheduler runs every Interval.
+type Job struct {
+	Name     string
+	Interval time.Duration
+	Run      func(ctx context.Context) error
+}
+
+// Scheduler runs periodic jobs. Every run takes a Postgres advisory lock
+// named after the job, so with several worker replicas a job only runs on
+// one of them at a time, the others skip that tick.
+type Scheduler struct {
+	db              *sqlx.DB
+	shutdownTimeout time.Duration
+	jobs            []Job
+}
+
+func NewScheduler(db *sqlx.DB, shutdownTimeout time.Duration) *Scheduler {
+	return &Scheduler{
+		db:              db,
+		shutdownTimeout: shutdownTimeout,
+	}
+}
+
+func (s *Scheduler) Add(job Job) {
+	s.jobs = append(s.jobs, job)
+}
+
+// Run starts every job right away and then on its interval, until ctx is
+// cancelled. Running jobs are then given the shutdown timeout to finish
+// before their context is cancelled too.
+func (s *Scheduler) Run(ctx context.Context) {
+	runCtx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var wg sync.WaitGroup
+	for _, job := range s.jobs {
+		wg.Add(1)
+		go func(job Job) {
+			defer wg.Done()
+			s.loop(ctx, runCtx, job)
+		}(job)
+	}
+
+	log.Printf("worker started with %d jobs", len(s.jobs))
+	<-ctx.Done()
+	log.Println("worker stopping, waiting for running jobs...")
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(s.shutdownTimeout):
+		log.Println("worker shutdown timed out, cancelling running jobs")
+		cancel()
+		<-done
+	}
+
+	log.Println("worker stopped")
+}
+
+// loop schedules a job until ctx is done, the job itself runs with runCtx
+// so a shutdown lets the current run finish.
+func (s *Scheduler) loop(ctx, runCtx context.Context, job Job) {
+	ticker := time.NewTicker(job.Interval)
+	defer ticker.Stop()
+
+	for {
+		s.runOnce(runCtx, job)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Scheduler) runOnce(ctx context.Context, job Job) {
+	// advisory locks belong to a session, so lock and unlock on one connection
+	conn, err := s.db.Connx(ctx)
+	if err != nil {
+		log.Printf("job %s: failed getting a connection: %v", job.Name, err)
+		return
+	}
+	defer conn.Close()
+
+	key := lockKey(job.Name)
+
+	var locked bool
+	if err := conn.QueryRowxContext(ctx, "SELECT pg_try_advisory_lock($1)", key).Scan(&locked); err != nil {
+		log.Printf("job %s: failed taking the lock: %v", job.Name, err)
+		return
+	}
+
+	if !locked {
+		return
+	}
+
+	defer func() {
+		if _, err := conn.ExecContext(context.WithoutCancel(ctx), "SELECT pg_advisory_unlock($1)", key); err != nil {
+			log.Printf("job %s: failed releasing the lock: %v", job.Name, err)
+		}
+	}()
+
+	start := time.Now()
+	if err := job.Run(ctx); err != nil {
+		log.Printf("job %s failed after %s: %v", job.Name, time.Since(start), err)
+	}
+}
+
+// lockKey turns a job name into the advisory lock key for it.
+func lockKey(name string) int64 {
+	h := fnv.New64a()
+	h.Write([]byte("worker:" + name))
+	return int64(h.Sum64())
+}